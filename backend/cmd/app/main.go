@@ -1,7 +1,19 @@
 package main
 
-import "finance-backend/internal/app"
+import (
+	"log/slog"
+	"os"
+
+	"finance-backend/internal/app"
+)
 
 func main() {
-	app.Run()
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		app.Migrate(os.Args[2:])
+		return
+	}
+	if err := app.Run(); err != nil {
+		slog.Error("server failed", "error", err)
+		os.Exit(1)
+	}
 }
@@ -1,72 +1,240 @@
 package app
 
 import (
-	"log"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
-	"gorm.io/driver/postgres"
-	"gorm.io/gorm"
 
+	"finance-backend/internal/config"
+	"finance-backend/internal/database"
 	"finance-backend/internal/handler"
+	"finance-backend/internal/job"
+	"finance-backend/internal/logging"
+	"finance-backend/internal/mailer"
+	"finance-backend/internal/metrics"
 	"finance-backend/internal/middleware"
-	"finance-backend/internal/model"
+	"finance-backend/internal/migrations"
 	"finance-backend/internal/repository"
 	"finance-backend/internal/service"
+	"finance-backend/internal/storage"
+	"finance-backend/internal/tracing"
 )
 
-func Run() {
-	dsn := "host=" + os.Getenv("DB_HOST") +
-		" user=" + os.Getenv("DB_USER") +
-		" password=" + os.Getenv("DB_PASSWORD") +
-		" dbname=" + os.Getenv("DB_NAME") +
-		" port=" + os.Getenv("DB_PORT") +
-		" sslmode=disable TimeZone=Europe/Moscow"
+// Run запускает сервер и возвращает ошибку запуска, когда уже закрыты база и
+// трассировка; при остановке по сигналу возвращает nil
+func Run() error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	logger, err := logging.New(os.Stdout, cfg.Log.Level, cfg.Log.Format)
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(logger)
+	slog.Info("config loaded", "config", cfg.Redacted())
+
+	// Остановка по SIGTERM (docker stop, Kubernetes) или Ctrl+C
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	shutdownTimeout := time.Duration(cfg.HTTP.ShutdownTimeoutSeconds) * time.Second
+	shutdownTracing, err := tracing.Setup(ctx, cfg.Tracing)
+	if err != nil {
+		return fmt.Errorf("set up tracing: %w", err)
+	}
+	// Досылаем спаны, накопленные до остановки, в том числе при ошибке запуска
+	defer func() {
+		tracingCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := shutdownTracing(tracingCtx); err != nil {
+			slog.Error("tracing shutdown", "error", err)
+		}
+	}()
+
+	db, err := database.Open(cfg.DB)
+	if err != nil {
+		return fmt.Errorf("open database: %w", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("open database: %w", err)
+	}
+	defer func() {
+		if err := sqlDB.Close(); err != nil {
+			slog.Error("close database", "error", err)
+		}
+	}()
+	if err := metrics.RegisterDB(sqlDB, cfg.DB.Driver); err != nil {
+		return fmt.Errorf("register database metrics: %w", err)
+	}
 
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	// Миграции схемы
+	migrator, err := migrations.New(db)
 	if err != nil {
-		log.Fatal(err)
+		return fmt.Errorf("load migrations: %w", err)
+	}
+	if cfg.DB.MigrateOnStart {
+		if err := migrator.Up(); err != nil {
+			return fmt.Errorf("apply migrations: %w", err)
+		}
 	}
 
-	// Автомиграция
-	err = db.AutoMigrate(&model.User{}, &model.Category{}, &model.Transaction{})
+	// Хранилище вложений: локальный каталог или S3-совместимый сервис
+	var fileStorage storage.Storage
+	switch cfg.Storage.Driver {
+	case "local":
+		fileStorage, err = storage.NewLocalStorage(cfg.Storage.LocalDir)
+	case "s3":
+		s3 := cfg.Storage.S3
+		fileStorage, err = storage.NewS3Storage(s3.Endpoint, s3.Region, s3.Bucket, s3.AccessKey, s3.SecretKey)
+	}
 	if err != nil {
-		log.Fatal(err)
+		return fmt.Errorf("open attachment storage: %w", err)
 	}
+	maxAttachmentSize := int64(cfg.Attachments.MaxSizeMB) << 20
+	attachmentQuota := int64(cfg.Attachments.QuotaMB) << 20
 
-	// JWT секрет
-	jwtSecret := os.Getenv("JWT_SECRET")
+	// Отправка писем: SMTP, если настроен, иначе вывод в лог
+	var mail mailer.Mailer = mailer.NewLogMailer()
+	if smtp := cfg.SMTP; smtp.Host != "" {
+		mail = mailer.NewSMTPMailer(smtp.Host, strconv.Itoa(smtp.Port), smtp.User, smtp.Password, smtp.From)
+	}
 
 	// Инициализация репозиториев
 	userRepo := repository.NewUserRepository(db)
 	transactionRepo := repository.NewTransactionRepository(db)
 	categoryRepo := repository.NewCategoryRepository(db)
+	ledgerRepo := repository.NewLedgerRepository(db)
+	splitRepo := repository.NewSplitRepository(db)
+	tagRepo := repository.NewTagRepository(db)
+	attachmentRepo := repository.NewAttachmentRepository(db)
+	ruleRepo := repository.NewRuleRepository(db)
+	searchRepo := repository.NewSearchRepository(db)
+	payeeRepo := repository.NewPayeeRepository(db)
+	duplicateRepo := repository.NewDuplicateRepository(db)
+	auditRepo := repository.NewAuditRepository(db)
+	goalRepo := repository.NewGoalRepository(db)
+	forecastRepo := repository.NewForecastRepository(db)
+	recurringRepo := repository.NewRecurringRepository(db)
+	alertRepo := repository.NewAlertRepository(db)
 
 	// Инициализация сервисов
-	authService := service.NewAuthService(jwtSecret)
-	userService := service.NewUserService(userRepo, authService)
-	transactionService := service.NewTransactionService(transactionRepo, categoryRepo)
-	categoryService := service.NewCategoryService(categoryRepo)
+	authService := service.NewAuthService(cfg.Auth.JWTSecret, time.Duration(cfg.Auth.TokenTTLHours)*time.Hour)
+	auditService := service.NewAuditService(auditRepo)
+	ledgerService := service.NewLedgerService(ledgerRepo, userRepo, mail, auditService)
+	userService := service.NewUserService(userRepo, authService, mail, cfg.HTTP.AppURL)
+	suggestionService := service.NewSuggestionService(transactionRepo, categoryRepo)
+	payeeService := service.NewPayeeService(payeeRepo, categoryRepo, ledgerService)
+	duplicateService := service.NewDuplicateService(duplicateRepo, transactionRepo, ledgerService, suggestionService,
+		auditService)
+	searchService := service.NewSearchService(searchRepo, transactionRepo)
+	ruleService := service.NewRuleService(ruleRepo, tagRepo, categoryRepo, transactionRepo, ledgerService,
+		suggestionService, auditService)
+	transactionService := service.NewTransactionService(transactionRepo, categoryRepo, tagRepo, ledgerService, ruleService,
+		suggestionService, payeeService, duplicateService, auditService)
+	categoryService := service.NewCategoryService(categoryRepo, ledgerService, auditService)
+	tagService := service.NewTagService(tagRepo)
+	goalService := service.NewGoalService(goalRepo, categoryRepo, ledgerService)
+	forecastService := service.NewForecastService(forecastRepo, categoryRepo, recurringRepo)
+	subscriptionService := service.NewSubscriptionService(recurringRepo, categoryRepo, ledgerService)
+	anomalyService := service.NewAnomalyService(alertRepo, ledgerService)
+	trashService := service.NewTrashService(transactionRepo, categoryRepo, ledgerService, suggestionService,
+		auditService, time.Duration(cfg.Retention.TrashDays)*24*time.Hour)
+	attachmentService := service.NewAttachmentService(attachmentRepo, transactionRepo, ledgerService, fileStorage,
+		maxAttachmentSize, attachmentQuota)
+	splitService := service.NewSplitService(transactionRepo, categoryRepo, splitRepo, ledgerRepo, ledgerService,
+		ruleService, suggestionService, payeeService, auditService)
+	accountService := service.NewAccountService(userRepo, ledgerRepo, categoryRepo, transactionRepo, tagRepo, authService,
+		attachmentService, mail, time.Duration(cfg.Retention.AccountDeletionGraceDays)*24*time.Hour)
 
 	// Инициализация хендлеров
 	authHandler := handler.NewAuthHandler(userService, authService)
 	transactionHandler := handler.NewTransactionHandler(transactionService)
-	categoryHandler := handler.NewCategoryHandler(categoryService)
+	categoryHandler := handler.NewCategoryHandler(categoryService, suggestionService)
+	accountHandler := handler.NewAccountHandler(accountService)
+	ledgerHandler := handler.NewLedgerHandler(ledgerService)
+	splitHandler := handler.NewSplitHandler(splitService)
+	tagHandler := handler.NewTagHandler(tagService)
+	ruleHandler := handler.NewRuleHandler(ruleService)
+	searchHandler := handler.NewSearchHandler(searchService)
+	payeeHandler := handler.NewPayeeHandler(payeeService)
+	duplicateHandler := handler.NewDuplicateHandler(duplicateService)
+	attachmentHandler := handler.NewAttachmentHandler(attachmentService)
+	trashHandler := handler.NewTrashHandler(trashService)
+	auditHandler := handler.NewAuditHandler(auditService, ledgerService)
+	goalHandler := handler.NewGoalHandler(goalService)
+	forecastHandler := handler.NewForecastHandler(forecastService)
+	subscriptionHandler := handler.NewSubscriptionHandler(subscriptionService)
+	alertHandler := handler.NewAlertHandler(anomalyService)
+	healthHandler := handler.NewHealthHandler(sqlDB, migrator)
+
+	// Фоновые задачи; при остановке их контекст отменяется и они дорабатывают до закрытия базы
+	jobCtx, cancelJobs := context.WithCancel(ctx)
+	defer cancelJobs()
+	var jobs job.Group
+	jobs.Every(jobCtx, "purge-deleted-accounts", time.Hour, accountService.PurgeDeleted)
+	jobs.Every(jobCtx, "cleanup-orphan-attachments", time.Hour, attachmentService.CleanupOrphans)
+	jobs.Every(jobCtx, "purge-trash", time.Hour, trashService.PurgeExpired)
+	jobs.Every(jobCtx, "detect-anomalies", time.Hour, anomalyService.DetectAnomalies)
 
 	// Настройка Gin
-	r := gin.Default()
+	// Отладочный вывод gin ломает JSON-логи, поэтому режим release по умолчанию
+	if os.Getenv(gin.EnvGinMode) == "" {
+		gin.SetMode(gin.ReleaseMode)
+	}
+	r := gin.New()
+	r.Use(middleware.RequestIDMiddleware())
+	r.Use(middleware.TracingMiddleware(cfg.Tracing.ServiceName, "/healthz", "/readyz", "/metrics"))
+	r.Use(middleware.LoggerMiddleware("/healthz", "/readyz", "/metrics"))
+	r.Use(middleware.MetricsMiddleware())
+	r.Use(middleware.RecoveryMiddleware())
+	r.Use(middleware.ClientIPMiddleware())
 
 	r.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"http://localhost:3000", "http://localhost:5173"}, // порты, где работает фронт
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
+		AllowOrigins: cfg.HTTP.CORSOrigins,
+		AllowMethods: []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowHeaders: []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Ledger-ID", middleware.RequestIDHeader,
+			"traceparent", "tracestate"},
+		ExposeHeaders:    []string{middleware.RequestIDHeader},
 		AllowCredentials: true,
 	}))
 
+	// Пробы для оркестратора: процесс жив / готов принимать трафик
+	r.GET("/healthz", healthHandler.Live)
+	r.GET("/readyz", healthHandler.Ready)
+
+	// Метрики Prometheus: на отдельном адресе или на основном порту по токену
+	var metricsSrv *http.Server
+	switch {
+	case cfg.Metrics.Addr != "":
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", metrics.Handler(cfg.Metrics.Token))
+		metricsSrv = &http.Server{
+			Addr:              cfg.Metrics.Addr,
+			Handler:           mux,
+			ReadHeaderTimeout: 5 * time.Second,
+		}
+	case cfg.Metrics.Token != "":
+		r.GET("/metrics", gin.WrapH(metrics.Handler(cfg.Metrics.Token)))
+	}
+
 	// Публичные маршруты (без аутентификации)
 	r.POST("/api/auth/register", authHandler.Register)
 	r.POST("/api/auth/login", authHandler.Login)
+	r.GET("/api/auth/verify-email", authHandler.VerifyEmail)
+	r.GET("/api/auth/email/confirm", authHandler.ConfirmEmailChange)
 
 	// Защищенные маршруты (требуют JWT токен)
 	api := r.Group("/api")
@@ -74,20 +242,183 @@ func Run() {
 	{
 		// Профиль пользователя
 		api.GET("/auth/profile", authHandler.GetProfile)
+		api.PUT("/auth/profile", authHandler.UpdateProfile)
+		api.POST("/auth/verify-email/resend", authHandler.ResendVerification)
+		api.POST("/auth/email/change", authHandler.RequestEmailChange)
+
+		// Экспорт данных и удаление аккаунта
+		api.GET("/auth/export", accountHandler.ExportData)
+		api.DELETE("/auth/account", accountHandler.DeleteAccount)
+		api.POST("/auth/account/cancel-deletion", accountHandler.CancelDeletion)
+	}
+
+	// Маршруты с данными пользователя (при необходимости требуют подтвержденный email)
+	data := api.Group("")
+	if cfg.Auth.RequireEmailVerification {
+		data.Use(middleware.EmailVerifiedMiddleware(userService))
+	}
+	{
+		// Бюджеты и участники
+		data.GET("/ledgers", ledgerHandler.GetLedgers)
+		data.POST("/ledgers", ledgerHandler.CreateLedger)
+		data.PUT("/ledgers/:id", ledgerHandler.UpdateLedger)
+		data.DELETE("/ledgers/:id", ledgerHandler.DeleteLedger)
+		data.GET("/ledgers/:id/members", ledgerHandler.GetMembers)
+		data.PUT("/ledgers/:id/members/:userId", ledgerHandler.UpdateMember)
+		data.DELETE("/ledgers/:id/members/:userId", ledgerHandler.RemoveMember)
+		data.POST("/ledgers/:id/invitations", ledgerHandler.Invite)
+		data.GET("/ledgers/:id/invitations", ledgerHandler.GetLedgerInvitations)
+		data.GET("/ledgers/:id/history", auditHandler.GetLedgerHistory)
 
+		// Лента изменений пользователя
+		data.GET("/activity", auditHandler.GetActivity)
+
+		// Приглашения текущего пользователя
+		data.GET("/invitations", ledgerHandler.GetMyInvitations)
+		data.POST("/invitations/:id/accept", ledgerHandler.AcceptInvitation)
+		data.POST("/invitations/:id/decline", ledgerHandler.DeclineInvitation)
+
+		// Теги
+		data.POST("/tags", tagHandler.CreateTag)
+		data.GET("/tags", tagHandler.GetTags)
+		data.PUT("/tags/:id", tagHandler.UpdateTag)
+		data.DELETE("/tags/:id", tagHandler.DeleteTag)
+
+		// Правила категоризации
+		data.POST("/rules", ruleHandler.CreateRule)
+		data.GET("/rules", ruleHandler.GetRules)
+		data.PUT("/rules/:id", ruleHandler.UpdateRule)
+		data.DELETE("/rules/:id", ruleHandler.DeleteRule)
+	}
+
+	// Данные бюджета: бюджет выбирается заголовком X-Ledger-ID, по умолчанию личный
+	ledger := data.Group("")
+	ledger.Use(middleware.LedgerMiddleware(ledgerService))
+	{
 		// Транзакции
-		api.POST("/transactions", transactionHandler.CreateTransaction)
-		api.GET("/transactions", transactionHandler.GetTransactions)
-		api.GET("/transactions/summary", transactionHandler.GetSummary)
-		api.DELETE("/transactions/:id", transactionHandler.DeleteTransaction)
+		ledger.POST("/transactions", transactionHandler.CreateTransaction)
+		ledger.GET("/transactions", transactionHandler.GetTransactions)
+		ledger.GET("/transactions/summary", transactionHandler.GetSummary)
+		ledger.GET("/transactions/search", searchHandler.SearchTransactions)
+		ledger.PUT("/transactions/:id", transactionHandler.UpdateTransaction)
+		ledger.DELETE("/transactions/:id", transactionHandler.DeleteTransaction)
+
+		// Общие расходы и взаиморасчеты
+		ledger.POST("/transactions/split", splitHandler.CreateSplitExpense)
+		ledger.GET("/balances", splitHandler.GetBalances)
+		ledger.POST("/settlements", splitHandler.CreateSettlement)
+		ledger.GET("/settlements", splitHandler.GetSettlements)
 
 		// Категории
-		api.POST("/categories", categoryHandler.CreateCategory)
-		api.GET("/categories", categoryHandler.GetCategories)
-		api.DELETE("/categories/:id", categoryHandler.DeleteCategory)
+		ledger.POST("/categories", categoryHandler.CreateCategory)
+		ledger.GET("/categories", categoryHandler.GetCategories)
+		ledger.GET("/categories/suggest", categoryHandler.SuggestCategories)
+		ledger.DELETE("/categories/:id", categoryHandler.DeleteCategory)
+
+		// Дубликаты
+		ledger.GET("/duplicates", duplicateHandler.GetDuplicates)
+		ledger.POST("/duplicates/merge", duplicateHandler.MergeDuplicates)
+		ledger.POST("/duplicates/dismiss", duplicateHandler.DismissDuplicates)
+
+		// Получатели
+		ledger.POST("/payees", payeeHandler.CreatePayee)
+		ledger.GET("/payees", payeeHandler.GetPayees)
+		ledger.PUT("/payees/:id", payeeHandler.UpdatePayee)
+		ledger.DELETE("/payees/:id", payeeHandler.DeletePayee)
+
+		// Цели накопления
+		ledger.POST("/goals", goalHandler.CreateGoal)
+		ledger.GET("/goals", goalHandler.GetGoals)
+		ledger.GET("/goals/:id", goalHandler.GetGoal)
+		ledger.PUT("/goals/:id", goalHandler.UpdateGoal)
+		ledger.DELETE("/goals/:id", goalHandler.DeleteGoal)
+
+		// Подписки и регулярные платежи
+		ledger.GET("/subscriptions", subscriptionHandler.GetSubscriptions)
+		ledger.POST("/subscriptions/confirm", subscriptionHandler.ConfirmSubscription)
+		ledger.GET("/recurring", subscriptionHandler.GetRecurringItems)
+		ledger.PUT("/recurring/:id", subscriptionHandler.UpdateRecurringItem)
+		ledger.DELETE("/recurring/:id", subscriptionHandler.DeleteRecurringItem)
+
+		// Предупреждения о необычных тратах
+		ledger.GET("/alerts", alertHandler.GetAlerts)
+		ledger.POST("/alerts/:id/acknowledge", alertHandler.AcknowledgeAlert)
+
+		// Вложения
+		ledger.POST("/transactions/:id/attachments", attachmentHandler.UploadAttachment)
+		ledger.GET("/transactions/:id/attachments", attachmentHandler.GetAttachments)
+		ledger.GET("/attachments/:id", attachmentHandler.DownloadAttachment)
+		ledger.GET("/attachments/:id/thumbnail", attachmentHandler.GetThumbnail)
+		ledger.DELETE("/attachments/:id", attachmentHandler.DeleteAttachment)
+
+		// Корзина
+		ledger.GET("/trash", trashHandler.GetTrash)
+		ledger.POST("/trash/transactions/:id/restore", trashHandler.RestoreTransaction)
+		ledger.POST("/trash/categories/:id/restore", trashHandler.RestoreCategory)
+
+		// История изменений
+		ledger.GET("/transactions/:id/history", auditHandler.GetTransactionHistory)
+		ledger.GET("/categories/:id/history", auditHandler.GetCategoryHistory)
+
+		// Прогон правил по истории бюджета
+		ledger.POST("/rules/dry-run", ruleHandler.DryRunRules)
+		ledger.POST("/rules/apply", ruleHandler.ReapplyRules)
+
+		// Отчеты
+		ledger.GET("/reports/tags", tagHandler.GetTagReport)
+		ledger.GET("/reports/payees", payeeHandler.GetTopPayees)
+		ledger.GET("/reports/forecast", forecastHandler.GetForecast)
 	}
 
 	// Запуск сервера
-	log.Println("Server starting on :8080")
-	r.Run(":8080")
+	srv := &http.Server{
+		Addr:         ":" + strconv.Itoa(cfg.HTTP.Port),
+		Handler:      r,
+		ReadTimeout:  time.Duration(cfg.HTTP.ReadTimeoutSeconds) * time.Second,
+		WriteTimeout: time.Duration(cfg.HTTP.WriteTimeoutSeconds) * time.Second,
+		IdleTimeout:  time.Duration(cfg.HTTP.IdleTimeoutSeconds) * time.Second,
+	}
+
+	serveErr := make(chan error, 2)
+	go func() {
+		slog.Info("server starting", "addr", srv.Addr)
+		serveErr <- srv.ListenAndServe()
+	}()
+	if metricsSrv != nil {
+		go func() {
+			slog.Info("metrics server starting", "addr", metricsSrv.Addr)
+			serveErr <- metricsSrv.ListenAndServe()
+		}()
+	}
+
+	var runErr error
+	select {
+	case err := <-serveErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			runErr = fmt.Errorf("start server: %w", err)
+		}
+	case <-ctx.Done():
+	}
+	stop()
+	cancelJobs()
+
+	// Новые соединения больше не принимаются, начатые запросы дорабатывают до таймаута
+	slog.Info("shutting down server")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		slog.Error("server shutdown", "error", err)
+	}
+	if metricsSrv != nil {
+		if err := metricsSrv.Shutdown(shutdownCtx); err != nil {
+			slog.Error("metrics server shutdown", "error", err)
+		}
+	}
+
+	// База закрывается в defer только после того, как фоновые задачи остановились
+	if err := jobs.Wait(shutdownCtx); err != nil {
+		slog.Error("background jobs did not stop", "error", err)
+	}
+	slog.Info("server stopped")
+	return runErr
 }
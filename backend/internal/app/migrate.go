@@ -0,0 +1,78 @@
+package app
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"finance-backend/internal/config"
+	"finance-backend/internal/database"
+	"finance-backend/internal/migrations"
+)
+
+const migrateUsage = "usage: migrate up | down | status | to <version>"
+
+// Migrate выполняет команду migrate: применение, откат и состояние миграций
+func Migrate(args []string) {
+	if len(args) == 0 {
+		log.Fatal(migrateUsage)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	db, err := database.Open(cfg.DB)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	migrator, err := migrations.New(db)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	switch args[0] {
+	case "up":
+		err = migrator.Up()
+	case "down":
+		err = migrator.Down()
+	case "to":
+		if len(args) != 2 {
+			log.Fatal(migrateUsage)
+		}
+		version, convErr := strconv.Atoi(args[1])
+		if convErr != nil {
+			log.Fatalf("invalid version %q: %v", args[1], convErr)
+		}
+		err = migrator.To(version)
+	case "status":
+		err = printMigrationStatus(migrator)
+	default:
+		log.Fatal(migrateUsage)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func printMigrationStatus(migrator *migrations.Migrator) error {
+	statuses, err := migrator.Status()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+	for _, s := range statuses {
+		applied := "pending"
+		if s.AppliedAt != nil {
+			applied = s.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(w, "%04d\t%s\t%s\n", s.Version, s.Name, applied)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,185 @@
+// Package classifier реализует наивный байесовский классификатор для
+// подсказки категорий по описанию и сумме транзакции
+package classifier
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Prediction класс с апостериорной вероятностью
+type Prediction struct {
+	Class       uint
+	Probability float64
+}
+
+type classStats struct {
+	docs     int
+	features map[string]int
+	total    int
+}
+
+// NaiveBayes мультиномиальная модель со сглаживанием Лапласа. Поддерживает
+// дообучение и удаление примеров, безопасна для конкурентного использования
+type NaiveBayes struct {
+	mu      sync.RWMutex
+	classes map[uint]*classStats
+	vocab   map[string]int
+	docs    int
+}
+
+func New() *NaiveBayes {
+	return &NaiveBayes{
+		classes: make(map[uint]*classStats),
+		vocab:   make(map[string]int),
+	}
+}
+
+// Add добавляет пример класса
+func (m *NaiveBayes) Add(class uint, features []string) {
+	if len(features) == 0 {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats, ok := m.classes[class]
+	if !ok {
+		stats = &classStats{features: make(map[string]int)}
+		m.classes[class] = stats
+	}
+	stats.docs++
+	m.docs++
+	for _, f := range features {
+		stats.features[f]++
+		stats.total++
+		m.vocab[f]++
+	}
+}
+
+// Remove убирает ранее добавленный пример класса
+func (m *NaiveBayes) Remove(class uint, features []string) {
+	if len(features) == 0 {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats, ok := m.classes[class]
+	if !ok || stats.docs == 0 {
+		return
+	}
+	stats.docs--
+	m.docs--
+	for _, f := range features {
+		if stats.features[f] == 0 {
+			continue
+		}
+		stats.features[f]--
+		stats.total--
+		if stats.features[f] == 0 {
+			delete(stats.features, f)
+		}
+		m.vocab[f]--
+		if m.vocab[f] <= 0 {
+			delete(m.vocab, f)
+		}
+	}
+	if stats.docs == 0 {
+		delete(m.classes, class)
+	}
+}
+
+// Predict возвращает классы по убыванию вероятности. Признаки, которых модель
+// не видела, не влияют на результат
+func (m *NaiveBayes) Predict(features []string) []Prediction {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.docs == 0 {
+		return nil
+	}
+
+	vocabSize := float64(len(m.vocab))
+	scores := make([]Prediction, 0, len(m.classes))
+	maxScore := math.Inf(-1)
+	for class, stats := range m.classes {
+		score := math.Log(float64(stats.docs) / float64(m.docs))
+		for _, f := range features {
+			if _, known := m.vocab[f]; !known {
+				continue
+			}
+			score += math.Log((float64(stats.features[f]) + 1) / (float64(stats.total) + vocabSize))
+		}
+		scores = append(scores, Prediction{Class: class, Probability: score})
+		maxScore = math.Max(maxScore, score)
+	}
+
+	// Переводим логарифмы в вероятности без переполнения
+	var sum float64
+	for i := range scores {
+		scores[i].Probability = math.Exp(scores[i].Probability - maxScore)
+		sum += scores[i].Probability
+	}
+	for i := range scores {
+		scores[i].Probability /= sum
+	}
+
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].Probability != scores[j].Probability {
+			return scores[i].Probability > scores[j].Probability
+		}
+		return scores[i].Class < scores[j].Class
+	})
+	return scores
+}
+
+// Size возвращает количество обучающих примеров
+func (m *NaiveBayes) Size() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.docs
+}
+
+// Features превращает транзакцию в признаки: слова описания, порядок суммы и тип
+func Features(description string, amount float64, kind string) []string {
+	var features []string
+	seen := make(map[string]bool)
+	for _, token := range Tokenize(description) {
+		if !seen[token] {
+			seen[token] = true
+			features = append(features, "w:"+token)
+		}
+	}
+	if amount > 0 {
+		// Корзины растут вдвое: 1–2, 2–4, 4–8 и т.д.
+		features = append(features, "a:"+strconv.Itoa(int(math.Log2(amount+1))))
+	}
+	if kind != "" {
+		features = append(features, "t:"+kind)
+	}
+	return features
+}
+
+// Tokenize нормализует описание: нижний регистр, только слова из букв длиной от двух символов.
+// Номера карт, чеков и дат отбрасываются как шум
+func Tokenize(description string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(description), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	tokens := fields[:0]
+	for _, f := range fields {
+		if len([]rune(f)) < 2 || strings.IndexFunc(f, unicode.IsLetter) < 0 {
+			continue
+		}
+		tokens = append(tokens, strings.ReplaceAll(f, "ё", "е"))
+	}
+	return tokens
+}
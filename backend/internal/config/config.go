@@ -0,0 +1,353 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/pelletier/go-toml/v2"
+)
+
+// Config настройки приложения. Значения берутся из значений по умолчанию,
+// затем из файла CONFIG_FILE (YAML или TOML), затем из переменных окружения
+type Config struct {
+	HTTP        HTTPConfig        `yaml:"http" toml:"http"`
+	DB          DBConfig          `yaml:"db" toml:"db"`
+	Auth        AuthConfig        `yaml:"auth" toml:"auth"`
+	Storage     StorageConfig     `yaml:"storage" toml:"storage"`
+	Attachments AttachmentsConfig `yaml:"attachments" toml:"attachments"`
+	SMTP        SMTPConfig        `yaml:"smtp" toml:"smtp"`
+	Retention   RetentionConfig   `yaml:"retention" toml:"retention"`
+	Log         LogConfig         `yaml:"log" toml:"log"`
+	Metrics     MetricsConfig     `yaml:"metrics" toml:"metrics"`
+	Tracing     TracingConfig     `yaml:"tracing" toml:"tracing"`
+}
+
+type HTTPConfig struct {
+	Port        int      `yaml:"port" toml:"port"`
+	AppURL      string   `yaml:"app_url" toml:"app_url"`           // публичный адрес API для ссылок из писем
+	CORSOrigins []string `yaml:"cors_origins" toml:"cors_origins"` // адреса, где работает фронт
+
+	// Таймауты сервера в секундах: чтение запроса, запись ответа, простой keep-alive
+	ReadTimeoutSeconds  int `yaml:"read_timeout_seconds" toml:"read_timeout_seconds"`
+	WriteTimeoutSeconds int `yaml:"write_timeout_seconds" toml:"write_timeout_seconds"`
+	IdleTimeoutSeconds  int `yaml:"idle_timeout_seconds" toml:"idle_timeout_seconds"`
+	// Сколько секунд при остановке ждать завершения начатых запросов
+	ShutdownTimeoutSeconds int `yaml:"shutdown_timeout_seconds" toml:"shutdown_timeout_seconds"`
+}
+
+type DBConfig struct {
+	Driver   string `yaml:"driver" toml:"driver"` // postgres или sqlite
+	Path     string `yaml:"path" toml:"path"`     // файл базы SQLite
+	Host     string `yaml:"host" toml:"host"`
+	Port     int    `yaml:"port" toml:"port"`
+	User     string `yaml:"user" toml:"user"`
+	Password string `yaml:"password" toml:"password"`
+	Name     string `yaml:"name" toml:"name"`
+	SSLMode  string `yaml:"sslmode" toml:"sslmode"`
+	TimeZone string `yaml:"timezone" toml:"timezone"`
+	// Применять ли миграции при запуске; иначе их запускают командой migrate up
+	MigrateOnStart bool `yaml:"migrate_on_start" toml:"migrate_on_start"`
+}
+
+type AuthConfig struct {
+	JWTSecret     string `yaml:"jwt_secret" toml:"jwt_secret"`
+	TokenTTLHours int    `yaml:"token_ttl_hours" toml:"token_ttl_hours"`
+	// Блокировать ли изменяющие запросы до подтверждения email
+	RequireEmailVerification bool `yaml:"require_email_verification" toml:"require_email_verification"`
+}
+
+type StorageConfig struct {
+	Driver   string   `yaml:"driver" toml:"driver"` // local или s3
+	LocalDir string   `yaml:"local_dir" toml:"local_dir"`
+	S3       S3Config `yaml:"s3" toml:"s3"`
+}
+
+type S3Config struct {
+	Endpoint  string `yaml:"endpoint" toml:"endpoint"`
+	Region    string `yaml:"region" toml:"region"`
+	Bucket    string `yaml:"bucket" toml:"bucket"`
+	AccessKey string `yaml:"access_key" toml:"access_key"`
+	SecretKey string `yaml:"secret_key" toml:"secret_key"`
+}
+
+type AttachmentsConfig struct {
+	MaxSizeMB int `yaml:"max_size_mb" toml:"max_size_mb"`
+	QuotaMB   int `yaml:"quota_mb" toml:"quota_mb"`
+}
+
+// SMTPConfig без хоста письма выводятся в лог
+type SMTPConfig struct {
+	Host     string `yaml:"host" toml:"host"`
+	Port     int    `yaml:"port" toml:"port"`
+	User     string `yaml:"user" toml:"user"`
+	Password string `yaml:"password" toml:"password"`
+	From     string `yaml:"from" toml:"from"`
+}
+
+type RetentionConfig struct {
+	// Сколько дней хранить данные после запроса на удаление аккаунта
+	AccountDeletionGraceDays int `yaml:"account_deletion_grace_days" toml:"account_deletion_grace_days"`
+	// Сколько дней удаленные транзакции и категории хранятся в корзине
+	TrashDays int `yaml:"trash_days" toml:"trash_days"`
+}
+
+type LogConfig struct {
+	Level  string `yaml:"level" toml:"level"`   // debug, info, warn или error
+	Format string `yaml:"format" toml:"format"` // json или text
+}
+
+// MetricsConfig публикация метрик Prometheus. Без адреса и токена /metrics не отдается
+type MetricsConfig struct {
+	// Отдельный адрес для /metrics, например ":9090", недоступный снаружи
+	Addr string `yaml:"addr" toml:"addr"`
+	// Токен (Authorization: Bearer); без отдельного адреса /metrics отдается на основном порту
+	Token string `yaml:"token" toml:"token"`
+}
+
+// TracingConfig экспорт трассировок OpenTelemetry по OTLP/HTTP. Без адреса трассировка выключена
+type TracingConfig struct {
+	// Адрес коллектора, например http://localhost:4318; схема http отключает TLS
+	Endpoint    string `yaml:"endpoint" toml:"endpoint"`
+	ServiceName string `yaml:"service_name" toml:"service_name"`
+	// Доля записываемых трассировок от 0 до 1; решение вызывающего из traceparent имеет приоритет
+	SampleRatio float64 `yaml:"sample_ratio" toml:"sample_ratio"`
+}
+
+// Default возвращает настройки по умолчанию
+func Default() Config {
+	return Config{
+		HTTP: HTTPConfig{
+			Port:        8080,
+			AppURL:      "http://localhost:8080",
+			CORSOrigins: []string{"http://localhost:3000", "http://localhost:5173"},
+
+			ReadTimeoutSeconds:     15,
+			WriteTimeoutSeconds:    60,
+			IdleTimeoutSeconds:     120,
+			ShutdownTimeoutSeconds: 20,
+		},
+		DB: DBConfig{
+			Driver:         "postgres",
+			Path:           "./data/finance.db",
+			Host:           "localhost",
+			Port:           5432,
+			User:           "postgres",
+			Name:           "postgres",
+			SSLMode:        "disable",
+			TimeZone:       "Europe/Moscow",
+			MigrateOnStart: true,
+		},
+		Auth: AuthConfig{
+			TokenTTLHours: 72,
+		},
+		Storage: StorageConfig{
+			Driver:   "local",
+			LocalDir: "./data/attachments",
+		},
+		Attachments: AttachmentsConfig{
+			MaxSizeMB: 10,
+			QuotaMB:   100,
+		},
+		SMTP: SMTPConfig{
+			Port: 587,
+		},
+		Retention: RetentionConfig{
+			AccountDeletionGraceDays: 30,
+			TrashDays:                30,
+		},
+		Log: LogConfig{
+			Level:  "info",
+			Format: "json",
+		},
+		Tracing: TracingConfig{
+			ServiceName: "finance-backend",
+			SampleRatio: 1,
+		},
+	}
+}
+
+// Load собирает настройки и проверяет их
+func Load() (*Config, error) {
+	cfg := Default()
+
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		if err := cfg.loadFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := cfg.applyEnv(); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// loadFile читает файл настроек, формат определяется по расширению
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read config file: %w", err)
+	}
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.UnmarshalWithOptions(data, c, yaml.Strict())
+	case ".toml":
+		err = toml.NewDecoder(bytes.NewReader(data)).DisallowUnknownFields().Decode(c)
+	default:
+		return fmt.Errorf("unsupported config file format: %q", ext)
+	}
+	if err != nil {
+		return fmt.Errorf("parse config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// Validate проверяет настройки, возвращая все найденные ошибки сразу
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.Auth.JWTSecret != "", "JWT_SECRET is required")
+	check(c.Auth.TokenTTLHours > 0, "token TTL must be positive, got %d hours", c.Auth.TokenTTLHours)
+
+	check(validPort(c.HTTP.Port), "invalid HTTP port: %d", c.HTTP.Port)
+	check(c.HTTP.AppURL != "", "APP_URL is required")
+	check(c.HTTP.ReadTimeoutSeconds > 0, "HTTP read timeout must be positive, got %d seconds", c.HTTP.ReadTimeoutSeconds)
+	check(c.HTTP.WriteTimeoutSeconds > 0, "HTTP write timeout must be positive, got %d seconds", c.HTTP.WriteTimeoutSeconds)
+	check(c.HTTP.IdleTimeoutSeconds > 0, "HTTP idle timeout must be positive, got %d seconds", c.HTTP.IdleTimeoutSeconds)
+	check(c.HTTP.ShutdownTimeoutSeconds > 0, "HTTP shutdown timeout must be positive, got %d seconds", c.HTTP.ShutdownTimeoutSeconds)
+
+	switch c.DB.Driver {
+	case "postgres":
+		check(c.DB.Host != "", "DB_HOST is required")
+		check(validPort(c.DB.Port), "invalid DB port: %d", c.DB.Port)
+		check(c.DB.User != "", "DB_USER is required")
+		check(c.DB.Name != "", "DB_NAME is required")
+		switch c.DB.SSLMode {
+		case "disable", "allow", "prefer", "require", "verify-ca", "verify-full":
+		default:
+			check(false, "invalid DB sslmode: %q", c.DB.SSLMode)
+		}
+	case "sqlite":
+		check(c.DB.Path != "", "DB_PATH is required for sqlite")
+	default:
+		check(false, "unknown DB_DRIVER: %q", c.DB.Driver)
+	}
+
+	switch c.Storage.Driver {
+	case "local":
+		check(c.Storage.LocalDir != "", "STORAGE_LOCAL_DIR is required for local storage")
+	case "s3":
+		check(c.Storage.S3.Bucket != "", "S3_BUCKET is required for s3 storage")
+	default:
+		check(false, "unknown STORAGE_DRIVER: %q", c.Storage.Driver)
+	}
+
+	check(c.Attachments.MaxSizeMB > 0, "attachment max size must be positive, got %d MB", c.Attachments.MaxSizeMB)
+	check(c.Attachments.QuotaMB > 0, "attachment quota must be positive, got %d MB", c.Attachments.QuotaMB)
+
+	if c.SMTP.Host != "" {
+		check(validPort(c.SMTP.Port), "invalid SMTP port: %d", c.SMTP.Port)
+		check(c.SMTP.From != "", "SMTP_FROM is required when SMTP_HOST is set")
+	}
+
+	check(c.Retention.AccountDeletionGraceDays >= 0, "account deletion grace days must not be negative")
+	check(c.Retention.TrashDays >= 0, "trash retention days must not be negative")
+
+	switch strings.ToLower(c.Log.Level) {
+	case "debug", "info", "warn", "error":
+	default:
+		check(false, "invalid LOG_LEVEL: %q", c.Log.Level)
+	}
+	switch strings.ToLower(c.Log.Format) {
+	case "json", "text":
+	default:
+		check(false, "invalid LOG_FORMAT: %q", c.Log.Format)
+	}
+
+	if c.Metrics.Addr != "" {
+		_, port, err := net.SplitHostPort(c.Metrics.Addr)
+		n, _ := strconv.Atoi(port)
+		check(err == nil && validPort(n), "invalid METRICS_ADDR: %q", c.Metrics.Addr)
+		check(n != c.HTTP.Port, "METRICS_ADDR must differ from the HTTP port")
+	}
+
+	if c.Tracing.Endpoint != "" {
+		u, err := url.Parse(c.Tracing.Endpoint)
+		check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "",
+			"invalid TRACING_ENDPOINT: %q", c.Tracing.Endpoint)
+		check(c.Tracing.ServiceName != "", "TRACING_SERVICE_NAME is required")
+	}
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1,
+		"tracing sample ratio must be between 0 and 1, got %g", c.Tracing.SampleRatio)
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid config: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+func validPort(port int) bool {
+	return port > 0 && port <= 65535
+}
+
+// DSN строка подключения к Postgres
+func (c DBConfig) DSN() string {
+	return strings.Join([]string{
+		"host=" + dsnValue(c.Host),
+		"user=" + dsnValue(c.User),
+		"password=" + dsnValue(c.Password),
+		"dbname=" + dsnValue(c.Name),
+		fmt.Sprintf("port=%d", c.Port),
+		"sslmode=" + dsnValue(c.SSLMode),
+		"TimeZone=" + dsnValue(c.TimeZone),
+	}, " ")
+}
+
+// dsnValue экранирует значение для строки подключения в формате key=value
+func dsnValue(v string) string {
+	if v != "" && !strings.ContainsAny(v, ` '\`) {
+		return v
+	}
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(v) + "'"
+}
+
+// Redacted возвращает настройки одной строкой со скрытыми секретами, для вывода в лог
+func (c Config) Redacted() string {
+	c.DB.Password = redact(c.DB.Password)
+	c.Auth.JWTSecret = redact(c.Auth.JWTSecret)
+	c.Storage.S3.AccessKey = redact(c.Storage.S3.AccessKey)
+	c.Storage.S3.SecretKey = redact(c.Storage.S3.SecretKey)
+	c.SMTP.Password = redact(c.SMTP.Password)
+	c.Metrics.Token = redact(c.Metrics.Token)
+
+	out, err := yaml.MarshalWithOptions(c, yaml.Flow(true))
+	if err != nil {
+		return fmt.Sprintf("<unprintable config: %v>", err)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func redact(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return "***"
+}
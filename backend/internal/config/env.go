@@ -0,0 +1,138 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// applyEnv переопределяет настройки непустыми переменными окружения
+func (c *Config) applyEnv() error {
+	e := &envReader{}
+
+	e.int(&c.HTTP.Port, "HTTP_PORT")
+	e.str(&c.HTTP.AppURL, "APP_URL")
+	e.list(&c.HTTP.CORSOrigins, "CORS_ORIGINS")
+	e.int(&c.HTTP.ReadTimeoutSeconds, "HTTP_READ_TIMEOUT_SECONDS")
+	e.int(&c.HTTP.WriteTimeoutSeconds, "HTTP_WRITE_TIMEOUT_SECONDS")
+	e.int(&c.HTTP.IdleTimeoutSeconds, "HTTP_IDLE_TIMEOUT_SECONDS")
+	e.int(&c.HTTP.ShutdownTimeoutSeconds, "HTTP_SHUTDOWN_TIMEOUT_SECONDS")
+
+	e.str(&c.DB.Driver, "DB_DRIVER")
+	e.str(&c.DB.Path, "DB_PATH")
+	e.str(&c.DB.Host, "DB_HOST")
+	e.int(&c.DB.Port, "DB_PORT")
+	e.str(&c.DB.User, "DB_USER")
+	e.str(&c.DB.Password, "DB_PASSWORD")
+	e.str(&c.DB.Name, "DB_NAME")
+	e.str(&c.DB.SSLMode, "DB_SSLMODE")
+	e.str(&c.DB.TimeZone, "DB_TIMEZONE")
+	e.bool(&c.DB.MigrateOnStart, "DB_MIGRATE_ON_START")
+
+	e.str(&c.Auth.JWTSecret, "JWT_SECRET")
+	e.int(&c.Auth.TokenTTLHours, "JWT_TTL_HOURS")
+	e.bool(&c.Auth.RequireEmailVerification, "REQUIRE_EMAIL_VERIFICATION")
+
+	e.str(&c.Storage.Driver, "STORAGE_DRIVER")
+	e.str(&c.Storage.LocalDir, "STORAGE_LOCAL_DIR")
+	e.str(&c.Storage.S3.Endpoint, "S3_ENDPOINT")
+	e.str(&c.Storage.S3.Region, "S3_REGION")
+	e.str(&c.Storage.S3.Bucket, "S3_BUCKET")
+	e.str(&c.Storage.S3.AccessKey, "S3_ACCESS_KEY")
+	e.str(&c.Storage.S3.SecretKey, "S3_SECRET_KEY")
+
+	e.int(&c.Attachments.MaxSizeMB, "ATTACHMENT_MAX_SIZE_MB")
+	e.int(&c.Attachments.QuotaMB, "ATTACHMENT_QUOTA_MB")
+
+	e.str(&c.SMTP.Host, "SMTP_HOST")
+	e.int(&c.SMTP.Port, "SMTP_PORT")
+	e.str(&c.SMTP.User, "SMTP_USER")
+	e.str(&c.SMTP.Password, "SMTP_PASSWORD")
+	e.str(&c.SMTP.From, "SMTP_FROM")
+
+	e.int(&c.Retention.AccountDeletionGraceDays, "ACCOUNT_DELETION_GRACE_DAYS")
+	e.int(&c.Retention.TrashDays, "TRASH_RETENTION_DAYS")
+
+	e.str(&c.Log.Level, "LOG_LEVEL")
+	e.str(&c.Log.Format, "LOG_FORMAT")
+
+	e.str(&c.Metrics.Addr, "METRICS_ADDR")
+	e.str(&c.Metrics.Token, "METRICS_TOKEN")
+
+	e.str(&c.Tracing.Endpoint, "TRACING_ENDPOINT")
+	e.str(&c.Tracing.ServiceName, "TRACING_SERVICE_NAME")
+	e.float(&c.Tracing.SampleRatio, "TRACING_SAMPLE_RATIO")
+
+	return errors.Join(e.errs...)
+}
+
+// envReader копит ошибки разбора, чтобы сообщить обо всех переменных сразу
+type envReader struct {
+	errs []error
+}
+
+func (e *envReader) str(dst *string, name string) {
+	if v := os.Getenv(name); v != "" {
+		*dst = v
+	}
+}
+
+func (e *envReader) int(dst *int, name string) {
+	v := os.Getenv(name)
+	if v == "" {
+		return
+	}
+
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		e.errs = append(e.errs, fmt.Errorf("invalid %s: %w", name, err))
+		return
+	}
+	*dst = n
+}
+
+func (e *envReader) float(dst *float64, name string) {
+	v := os.Getenv(name)
+	if v == "" {
+		return
+	}
+
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		e.errs = append(e.errs, fmt.Errorf("invalid %s: %w", name, err))
+		return
+	}
+	*dst = f
+}
+
+func (e *envReader) bool(dst *bool, name string) {
+	v := os.Getenv(name)
+	if v == "" {
+		return
+	}
+
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		e.errs = append(e.errs, fmt.Errorf("invalid %s: %w", name, err))
+		return
+	}
+	*dst = b
+}
+
+// list разбирает значения через запятую
+func (e *envReader) list(dst *[]string, name string) {
+	v := os.Getenv(name)
+	if v == "" {
+		return
+	}
+
+	var items []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	*dst = items
+}
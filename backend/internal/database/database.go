@@ -0,0 +1,125 @@
+package database
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	gosqlite "github.com/glebarez/go-sqlite"
+	"github.com/glebarez/sqlite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"finance-backend/internal/config"
+	"finance-backend/internal/metrics"
+	"finance-backend/internal/repository"
+	"finance-backend/internal/tracing"
+)
+
+// slowQueryThreshold запросы дольше этого пишутся в лог как медленные
+const slowQueryThreshold = 200 * time.Millisecond
+
+// Open подключается к базе выбранного в настройках драйвера
+func Open(cfg config.DBConfig) (*gorm.DB, error) {
+	var (
+		db  *gorm.DB
+		err error
+	)
+	switch cfg.Driver {
+	case "postgres":
+		db, err = gorm.Open(postgres.Open(cfg.DSN()), gormConfig())
+	case "sqlite":
+		db, err = openSQLite(cfg.Path)
+	default:
+		return nil, fmt.Errorf("unknown DB driver: %q", cfg.Driver)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Use(repository.ErrorsPlugin{}); err != nil {
+		return nil, err
+	}
+	if err := db.Use(metrics.GormPlugin{}); err != nil {
+		return nil, err
+	}
+	if err := db.Use(tracing.GormPlugin{}); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+// gormConfig пишет в slog ошибки и медленные запросы. Значения параметров
+// в лог не попадают: среди них бывают пароли и токены
+func gormConfig() *gorm.Config {
+	return &gorm.Config{
+		Logger: logger.NewSlogLogger(slog.Default(), logger.Config{
+			SlowThreshold:             slowQueryThreshold,
+			LogLevel:                  logger.Warn,
+			IgnoreRecordNotFoundError: true,
+			ParameterizedQueries:      true,
+		}),
+	}
+}
+
+var registerFunctions sync.Once
+
+// openSQLite открывает файл базы SQLite. Внешние ключи в SQLite по умолчанию
+// не проверяются, а транзакции берут блокировку записи только при первой
+// записи, поэтому включаем foreign_keys и _txlock=immediate. Время хранится
+// текстом и сравнивается как строка, поэтому и в записи, и в условиях
+// переводится в UTC (см. utcConnPool)
+func openSQLite(path string) (*gorm.DB, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+
+	var err error
+	registerFunctions.Do(func() {
+		err = gosqlite.RegisterDeterministicScalarFunction("unicode_lower", 1, unicodeLower)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	params.Add("_pragma", "foreign_keys(1)")
+	params.Add("_pragma", "journal_mode(WAL)")
+	params.Add("_pragma", "busy_timeout(5000)")
+	params.Set("_txlock", "immediate")
+
+	sqlDB, err := sql.Open(sqlite.DriverName, path+"?"+params.Encode())
+	if err != nil {
+		return nil, err
+	}
+
+	gormCfg := gormConfig()
+	gormCfg.NowFunc = func() time.Time { return time.Now().UTC() }
+	db, err := gorm.Open(sqlite.Dialector{DriverName: sqlite.DriverName, Conn: utcConnPool{sqlDB}}, gormCfg)
+	if err != nil {
+		sqlDB.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// unicodeLower нижний регистр с поддержкой Unicode: встроенная lower() в
+// SQLite меняет только латиницу, а описания транзакций часто на кириллице
+func unicodeLower(_ *gosqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+	switch v := args[0].(type) {
+	case string:
+		return strings.ToLower(v), nil
+	case []byte:
+		return strings.ToLower(string(v)), nil
+	default:
+		return v, nil
+	}
+}
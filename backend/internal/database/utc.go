@@ -0,0 +1,101 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// utcConnPool переводит время в параметрах запросов в UTC. SQLite хранит время
+// текстом и сравнивает его как строку, поэтому 2026-03-10T01:00:00+03:00 без
+// перевода оказывается «позже» 2026-03-09T23:59:59Z и выпадает из фильтров.
+// Обертка действует и на запись, и на условия, в том числе внутри транзакций
+type utcConnPool struct {
+	db *sql.DB
+}
+
+func (p utcConnPool) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return p.db.PrepareContext(ctx, query)
+}
+
+func (p utcConnPool) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return p.db.ExecContext(ctx, query, utcArgs(args)...)
+}
+
+func (p utcConnPool) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return p.db.QueryContext(ctx, query, utcArgs(args)...)
+}
+
+func (p utcConnPool) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return p.db.QueryRowContext(ctx, query, utcArgs(args)...)
+}
+
+func (p utcConnPool) BeginTx(ctx context.Context, opts *sql.TxOptions) (gorm.ConnPool, error) {
+	tx, err := p.db.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &utcTx{tx}, nil
+}
+
+// GetDBConn нужен gorm.DB.DB(): пробы готовности и закрытие при остановке
+func (p utcConnPool) GetDBConn() (*sql.DB, error) {
+	return p.db, nil
+}
+
+// utcTx то же для транзакции; Commit и Rollback берутся из *sql.Tx
+type utcTx struct {
+	*sql.Tx
+}
+
+func (tx *utcTx) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return tx.Tx.ExecContext(ctx, query, utcArgs(args)...)
+}
+
+func (tx *utcTx) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return tx.Tx.QueryContext(ctx, query, utcArgs(args)...)
+}
+
+func (tx *utcTx) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return tx.Tx.QueryRowContext(ctx, query, utcArgs(args)...)
+}
+
+// utcArgs заменяет время в параметрах на UTC. Типы вроде gorm.DeletedAt
+// отдают время через driver.Valuer, поэтому проверяется и их значение
+func utcArgs(args []any) []any {
+	var out []any
+	for i, arg := range args {
+		t, ok := timeArg(arg)
+		if !ok || t.Location() == time.UTC {
+			continue
+		}
+		if out == nil {
+			out = append([]any(nil), args...)
+		}
+		out[i] = t.UTC()
+	}
+	if out == nil {
+		return args
+	}
+	return out
+}
+
+func timeArg(arg any) (time.Time, bool) {
+	switch v := arg.(type) {
+	case time.Time:
+		return v, true
+	case *time.Time:
+		if v != nil {
+			return *v, true
+		}
+	case driver.Valuer:
+		if value, err := v.Value(); err == nil {
+			t, ok := value.(time.Time)
+			return t, ok
+		}
+	}
+	return time.Time{}, false
+}
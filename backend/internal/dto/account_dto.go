@@ -0,0 +1,51 @@
+package dto
+
+import "time"
+
+type ExportCategory struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	Type      string    `json:"type"`
+	Color     string    `json:"color"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ExportTransaction struct {
+	ID           uint      `json:"id"`
+	CategoryID   *uint     `json:"category_id,omitempty"`
+	CategoryName string    `json:"category_name,omitempty"`
+	PayeeName    string    `json:"payee,omitempty"`
+	Amount       float64   `json:"amount"`
+	Type         string    `json:"type"`
+	Description  string    `json:"description"`
+	Notes        string    `json:"notes,omitempty"`
+	Date         time.Time `json:"date"`
+	CreatedAt    time.Time `json:"created_at"`
+
+	PaidByID *uint           `json:"paid_by_id,omitempty"`
+	Splits   []SplitResponse `json:"splits,omitempty"`
+	Tags     []string        `json:"tags,omitempty"`
+}
+
+type ExportLedger struct {
+	ID           uint                `json:"id"`
+	Name         string              `json:"name"`
+	Personal     bool                `json:"personal"`
+	Role         string              `json:"role"`
+	Categories   []ExportCategory    `json:"categories"`
+	Transactions []ExportTransaction `json:"transactions"`
+}
+
+// AccountExport полный архив данных всех бюджетов пользователя
+type AccountExport struct {
+	ExportedAt time.Time      `json:"exported_at"`
+	User       AuthUser       `json:"user"`
+	Tags       []TagResponse  `json:"tags"`
+	Ledgers    []ExportLedger `json:"ledgers"`
+}
+
+type AccountDeletionResponse struct {
+	Message             string    `json:"message"`
+	DeletionScheduledAt time.Time `json:"deletion_scheduled_at"`
+	ExportURL           string    `json:"export_url"`
+}
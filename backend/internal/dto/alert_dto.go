@@ -0,0 +1,5 @@
+package dto
+
+type AlertQuery struct {
+	All bool `form:"all"` // включая просмотренные
+}
@@ -4,19 +4,49 @@ import "time"
 
 type CreateTransactionRequest struct {
 	CategoryID  *uint   `json:"category_id,omitempty"`
+	PayeeID     *uint   `json:"payee_id,omitempty"` // без него получатель определяется по описанию
 	Amount      float64 `json:"amount" binding:"required,gt=0"`
 	Type        string  `json:"type" binding:"required,oneof=income expense"`
 	Description string  `json:"description" binding:"required"`
+	Notes       string  `json:"notes,omitempty"`
 	Date        string  `json:"date" binding:"required"`
+	TagIDs      []uint  `json:"tag_ids,omitempty"`
+}
+
+type UpdateTransactionRequest struct {
+	CategoryID  *uint   `json:"category_id,omitempty"`
+	PayeeID     *uint   `json:"payee_id,omitempty"`
+	Amount      float64 `json:"amount" binding:"required,gt=0"`
+	Type        string  `json:"type" binding:"required,oneof=income expense"`
+	Description string  `json:"description" binding:"required"`
+	Notes       string  `json:"notes"`
+	Date        string  `json:"date" binding:"required"`
+	TagIDs      []uint  `json:"tag_ids"`
+}
+
+// TransactionFilter условия выборки транзакций
+type TransactionFilter struct {
+	From         *time.Time
+	To           *time.Time
+	TagIDs       []uint
+	MatchAllTags bool // true — транзакция должна иметь все теги, иначе хотя бы один
 }
 
 type TransactionResponse struct {
 	ID           uint      `json:"id"`
+	CategoryID   *uint     `json:"category_id,omitempty"`
 	Amount       float64   `json:"amount"`
 	Type         string    `json:"type"`
 	Description  string    `json:"description"`
+	Notes        string    `json:"notes,omitempty"`
 	Date         time.Time `json:"date"`
 	CategoryName string    `json:"category_name,omitempty"`
+	PayeeID      *uint     `json:"payee_id,omitempty"`
+	PayeeName    string    `json:"payee_name,omitempty"`
+
+	PaidByID *uint           `json:"paid_by_id,omitempty"`
+	Splits   []SplitResponse `json:"splits,omitempty"`
+	Tags     []TagResponse   `json:"tags,omitempty"`
 }
 
 type CreateCategoryRequest struct {
@@ -30,3 +60,18 @@ type FinancialSummary struct {
 	TotalExpense float64 `json:"total_expense"`
 	Balance      float64 `json:"balance"`
 }
+
+type SuggestCategoryQuery struct {
+	Description string  `form:"description" binding:"required"`
+	Amount      float64 `form:"amount" binding:"gte=0"`
+	Type        string  `form:"type" binding:"omitempty,oneof=income expense"`
+	Limit       int     `form:"limit" binding:"gte=0,lte=20"`
+}
+
+// CategorySuggestion категория, предложенная по истории пользователя
+type CategorySuggestion struct {
+	CategoryID uint    `json:"category_id"`
+	Name       string  `json:"name"`
+	Color      string  `json:"color"`
+	Confidence float64 `json:"confidence"`
+}
@@ -0,0 +1,13 @@
+package dto
+
+import "time"
+
+type AttachmentResponse struct {
+	ID            uint      `json:"id"`
+	TransactionID uint      `json:"transaction_id"`
+	FileName      string    `json:"file_name"`
+	ContentType   string    `json:"content_type"`
+	Size          int64     `json:"size"`
+	HasThumbnail  bool      `json:"has_thumbnail"`
+	CreatedAt     time.Time `json:"created_at"`
+}
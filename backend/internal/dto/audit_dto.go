@@ -0,0 +1,36 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type AuditQuery struct {
+	Limit  int `form:"limit" binding:"gte=0,lte=200"`
+	Offset int `form:"offset" binding:"gte=0"`
+}
+
+// AuditChange старое и новое значение поля
+type AuditChange struct {
+	Old any `json:"old"`
+	New any `json:"new"`
+}
+
+type AuditEntryResponse struct {
+	ID         uint                   `json:"id"`
+	UserID     uint                   `json:"user_id"`
+	LedgerID   uint                   `json:"ledger_id"`
+	EntityType string                 `json:"entity_type"`
+	EntityID   uint                   `json:"entity_id"`
+	Action     string                 `json:"action"`
+	Before     json.RawMessage        `json:"before"`
+	After      json.RawMessage        `json:"after"`
+	Changes    map[string]AuditChange `json:"changes,omitempty"`
+	IP         string                 `json:"ip"`
+	CreatedAt  time.Time              `json:"created_at"`
+}
+
+type AuditResponse struct {
+	Total   int64                `json:"total"`
+	Entries []AuditEntryResponse `json:"entries"`
+}
@@ -1,23 +1,40 @@
 package dto
 
 type RegisterRequest struct {
-    Email     string `json:"email" binding:"required,email"`
-    Password  string `json:"password" binding:"required,min=6"`
-    FirstName string `json:"first_name" binding:"required"`
-    LastName  string `json:"last_name" binding:"required"`
+	Email     string `json:"email" binding:"required,email"`
+	Password  string `json:"password" binding:"required,min=6"`
+	FirstName string `json:"first_name" binding:"required"`
+	LastName  string `json:"last_name" binding:"required"`
 }
 
 type LoginRequest struct {
-    Email    string `json:"email" binding:"required,email"`
-    Password string `json:"password" binding:"required"`
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required"`
+}
+
+type AuthUser struct {
+	ID            uint   `json:"id"`
+	Email         string `json:"email"`
+	FirstName     string `json:"first_name"`
+	LastName      string `json:"last_name"`
+	EmailVerified bool   `json:"email_verified"`
 }
 
 type AuthResponse struct {
-    Token string `json:"token"`
-    User  struct {
-        ID        uint   `json:"id"`
-        Email     string `json:"email"`
-        FirstName string `json:"first_name"`
-        LastName  string `json:"last_name"`
-    } `json:"user"`
-}
\ No newline at end of file
+	Token string   `json:"token"`
+	User  AuthUser `json:"user"`
+}
+
+type UpdateProfileRequest struct {
+	FirstName string `json:"first_name" binding:"required"`
+	LastName  string `json:"last_name" binding:"required"`
+}
+
+type ChangeEmailRequest struct {
+	NewEmail string `json:"new_email" binding:"required,email"`
+	Password string `json:"password" binding:"required"`
+}
+
+type DeleteAccountRequest struct {
+	Password string `json:"password" binding:"required"`
+}
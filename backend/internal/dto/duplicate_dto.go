@@ -0,0 +1,19 @@
+package dto
+
+// DuplicateCandidate пара транзакций, похожих на дубликаты
+type DuplicateCandidate struct {
+	Transaction TransactionResponse `json:"transaction"`
+	Duplicate   TransactionResponse `json:"duplicate"`
+	Similarity  float64             `json:"similarity"` // схожесть описаний от 0 до 1
+	DaysApart   int                 `json:"days_apart"`
+}
+
+type DismissDuplicateRequest struct {
+	TransactionID uint `json:"transaction_id" binding:"required"`
+	OtherID       uint `json:"other_id" binding:"required"`
+}
+
+type MergeDuplicateRequest struct {
+	KeepID   uint `json:"keep_id" binding:"required"`
+	RemoveID uint `json:"remove_id" binding:"required"`
+}
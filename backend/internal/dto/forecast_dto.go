@@ -0,0 +1,37 @@
+package dto
+
+import "time"
+
+type ForecastQuery struct {
+	Months int `form:"months" binding:"gte=0,lte=24"`
+}
+
+// ForecastDay прогноз остатка на конец дня
+type ForecastDay struct {
+	Date        time.Time `json:"date"`
+	Expected    float64   `json:"expected"`
+	Optimistic  float64   `json:"optimistic"`
+	Pessimistic float64   `json:"pessimistic"`
+	Scheduled   float64   `json:"scheduled,omitempty"` // сумма известных транзакций этого дня
+}
+
+// ForecastCategory средний месячный оборот категории по истории
+type ForecastCategory struct {
+	CategoryID     *uint   `json:"category_id,omitempty"`
+	Name           string  `json:"name"`
+	Type           string  `json:"type"`
+	MonthlyAverage float64 `json:"monthly_average"`
+	MonthlyStdDev  float64 `json:"monthly_std_dev"`
+}
+
+type ForecastResponse struct {
+	StartBalance  float64            `json:"start_balance"`
+	HistoryMonths int                `json:"history_months"` // за сколько месяцев взяты средние
+	Categories    []ForecastCategory `json:"categories"`
+	Days          []ForecastDay      `json:"days"`
+	// LowestExpected минимальный ожидаемый остаток за период и его дата
+	LowestExpected     float64    `json:"lowest_expected"`
+	LowestExpectedDate time.Time  `json:"lowest_expected_date"`
+	FirstNegativeDate  *time.Time `json:"first_negative_date,omitempty"` // когда ожидаемый остаток уйдет в минус
+	FirstRiskDate      *time.Time `json:"first_risk_date,omitempty"`     // то же по пессимистичной оценке
+}
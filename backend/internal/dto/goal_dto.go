@@ -0,0 +1,36 @@
+package dto
+
+import "time"
+
+type GoalRequest struct {
+	Name         string  `json:"name" binding:"required"`
+	TargetAmount float64 `json:"target_amount" binding:"required,gt=0"`
+	Deadline     string  `json:"deadline,omitempty"`
+	CategoryID   *uint   `json:"category_id,omitempty"` // без категории взносами считаются все сбережения бюджета
+	StartDate    string  `json:"start_date,omitempty"`  // по умолчанию — момент создания
+}
+
+type GoalResponse struct {
+	ID           uint         `json:"id"`
+	Name         string       `json:"name"`
+	TargetAmount float64      `json:"target_amount"`
+	Deadline     *time.Time   `json:"deadline,omitempty"`
+	CategoryID   *uint        `json:"category_id,omitempty"`
+	StartDate    time.Time    `json:"start_date"`
+	Progress     GoalProgress `json:"progress"`
+}
+
+// GoalProgress состояние цели на текущий момент
+type GoalProgress struct {
+	Saved     float64 `json:"saved"`
+	Remaining float64 `json:"remaining"`
+	Percent   float64 `json:"percent"`
+	Completed bool    `json:"completed"`
+	// MonthlyPace средний взнос в месяц за последние месяцы
+	MonthlyPace float64 `json:"monthly_pace"`
+	// RequiredMonthly взнос в месяц, нужный, чтобы успеть к сроку
+	RequiredMonthly *float64 `json:"required_monthly,omitempty"`
+	// ProjectedDate когда цель будет достигнута при текущем темпе; пусто, если темп не положительный
+	ProjectedDate *time.Time `json:"projected_date,omitempty"`
+	OnTrack       *bool      `json:"on_track,omitempty"`
+}
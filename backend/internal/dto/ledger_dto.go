@@ -0,0 +1,42 @@
+package dto
+
+import "time"
+
+type LedgerRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type LedgerResponse struct {
+	ID       uint   `json:"id"`
+	Name     string `json:"name"`
+	OwnerID  uint   `json:"owner_id"`
+	Personal bool   `json:"personal"`
+	Role     string `json:"role"`
+}
+
+type LedgerMemberResponse struct {
+	UserID    uint   `json:"user_id"`
+	Email     string `json:"email"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Role      string `json:"role"`
+}
+
+type UpdateMemberRoleRequest struct {
+	Role string `json:"role" binding:"required,oneof=editor viewer"`
+}
+
+type InviteMemberRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role" binding:"required,oneof=editor viewer"`
+}
+
+type InvitationResponse struct {
+	ID         uint      `json:"id"`
+	LedgerID   uint      `json:"ledger_id"`
+	LedgerName string    `json:"ledger_name"`
+	Email      string    `json:"email"`
+	Role       string    `json:"role"`
+	Status     string    `json:"status"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
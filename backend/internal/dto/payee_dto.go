@@ -0,0 +1,36 @@
+package dto
+
+import "time"
+
+type PayeeAliasRequest struct {
+	Pattern string `json:"pattern" binding:"required"`
+	IsRegex bool   `json:"is_regex"`
+}
+
+type PayeeRequest struct {
+	Name              string              `json:"name" binding:"required"`
+	DefaultCategoryID *uint               `json:"default_category_id,omitempty"`
+	Aliases           []PayeeAliasRequest `json:"aliases" binding:"dive"`
+}
+
+type PayeeAliasResponse struct {
+	ID      uint   `json:"id"`
+	Pattern string `json:"pattern"`
+	IsRegex bool   `json:"is_regex"`
+}
+
+type PayeeResponse struct {
+	ID                uint                 `json:"id"`
+	Name              string               `json:"name"`
+	DefaultCategoryID *uint                `json:"default_category_id,omitempty"`
+	Aliases           []PayeeAliasResponse `json:"aliases"`
+}
+
+type PayeeReport struct {
+	PayeeID      uint      `json:"payee_id"`
+	Name         string    `json:"name"`
+	TotalExpense float64   `json:"total_expense"`
+	TotalIncome  float64   `json:"total_income"`
+	Count        int64     `json:"count"`
+	LastDate     time.Time `json:"last_date"`
+}
@@ -0,0 +1,54 @@
+package dto
+
+import "time"
+
+// PriceChange изменение суммы регулярного платежа
+type PriceChange struct {
+	Previous  float64   `json:"previous"`
+	Current   float64   `json:"current"`
+	Percent   float64   `json:"percent"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+// SubscriptionCandidate регулярный платеж, найденный в истории расходов
+type SubscriptionCandidate struct {
+	Key              string       `json:"key"`
+	Name             string       `json:"name"`
+	PayeeID          *uint        `json:"payee_id,omitempty"`
+	CategoryID       *uint        `json:"category_id,omitempty"`
+	Period           string       `json:"period"`
+	Amount           float64      `json:"amount"` // последняя списанная сумма
+	MonthlyCost      float64      `json:"monthly_cost"`
+	AnnualCost       float64      `json:"annual_cost"`
+	Occurrences      int          `json:"occurrences"`
+	FirstDate        time.Time    `json:"first_date"`
+	LastDate         time.Time    `json:"last_date"`
+	NextExpectedDate time.Time    `json:"next_expected_date"`
+	PriceChange      *PriceChange `json:"price_change,omitempty"`
+	RecurringItemID  *uint        `json:"recurring_item_id,omitempty"` // уже отслеживается
+}
+
+type ConfirmSubscriptionRequest struct {
+	Key string `json:"key" binding:"required"`
+}
+
+type RecurringItemRequest struct {
+	Name       string  `json:"name" binding:"required"`
+	Amount     float64 `json:"amount" binding:"required,gt=0"`
+	Type       string  `json:"type" binding:"required,oneof=income expense"`
+	Period     string  `json:"period" binding:"required,oneof=weekly biweekly monthly quarterly yearly"`
+	NextDate   string  `json:"next_date" binding:"required"`
+	CategoryID *uint   `json:"category_id,omitempty"`
+}
+
+type RecurringItemResponse struct {
+	ID          uint      `json:"id"`
+	Name        string    `json:"name"`
+	Amount      float64   `json:"amount"`
+	Type        string    `json:"type"`
+	Period      string    `json:"period"`
+	NextDate    time.Time `json:"next_date"`
+	CategoryID  *uint     `json:"category_id,omitempty"`
+	PayeeID     *uint     `json:"payee_id,omitempty"`
+	MonthlyCost float64   `json:"monthly_cost"`
+}
@@ -0,0 +1,68 @@
+package dto
+
+import "time"
+
+type RuleRequest struct {
+	Name     string `json:"name" binding:"required"`
+	Priority int    `json:"priority"`
+	Enabled  *bool  `json:"enabled,omitempty"` // по умолчанию правило включено
+
+	// Условия
+	LedgerID            *uint    `json:"ledger_id,omitempty"`
+	DescriptionContains string   `json:"description_contains,omitempty"`
+	DescriptionRegex    string   `json:"description_regex,omitempty"`
+	MinAmount           *float64 `json:"min_amount,omitempty"`
+	MaxAmount           *float64 `json:"max_amount,omitempty"`
+	Type                string   `json:"type,omitempty" binding:"omitempty,oneof=income expense"`
+
+	// Действия
+	SetCategoryID  *uint  `json:"set_category_id,omitempty"`
+	SetDescription string `json:"set_description,omitempty"`
+	TagIDs         []uint `json:"tag_ids,omitempty"`
+	StopProcessing bool   `json:"stop_processing"`
+}
+
+type RuleResponse struct {
+	ID       uint   `json:"id"`
+	Name     string `json:"name"`
+	Priority int    `json:"priority"`
+	Enabled  bool   `json:"enabled"`
+
+	LedgerID            *uint    `json:"ledger_id,omitempty"`
+	DescriptionContains string   `json:"description_contains,omitempty"`
+	DescriptionRegex    string   `json:"description_regex,omitempty"`
+	MinAmount           *float64 `json:"min_amount,omitempty"`
+	MaxAmount           *float64 `json:"max_amount,omitempty"`
+	Type                string   `json:"type,omitempty"`
+
+	SetCategoryID  *uint         `json:"set_category_id,omitempty"`
+	SetDescription string        `json:"set_description,omitempty"`
+	Tags           []TagResponse `json:"tags,omitempty"`
+	StopProcessing bool          `json:"stop_processing"`
+}
+
+// RuleDryRunRequest позволяет проверить на истории еще не сохраненное правило
+type RuleDryRunRequest struct {
+	Rule *RuleRequest `json:"rule,omitempty"`
+}
+
+// RuleChange изменение транзакции, которое вносят правила
+type RuleChange struct {
+	TransactionID  uint          `json:"transaction_id"`
+	Date           time.Time     `json:"date"`
+	Amount         float64       `json:"amount"`
+	Type           string        `json:"type"`
+	Description    string        `json:"description"`
+	RuleIDs        []uint        `json:"rule_ids"`
+	OldCategoryID  *uint         `json:"old_category_id,omitempty"`
+	NewCategoryID  *uint         `json:"new_category_id,omitempty"`
+	NewDescription string        `json:"new_description,omitempty"`
+	AddedTags      []TagResponse `json:"added_tags,omitempty"`
+}
+
+type RuleApplyResponse struct {
+	Applied bool         `json:"applied"` // false для пробного прогона
+	Checked int          `json:"checked"`
+	Changed int          `json:"changed"`
+	Changes []RuleChange `json:"changes"`
+}
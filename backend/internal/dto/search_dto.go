@@ -0,0 +1,25 @@
+package dto
+
+type TransactionSearchQuery struct {
+	Query  string `form:"q" binding:"required"`
+	Limit  int    `form:"limit" binding:"gte=0,lte=100"`
+	Offset int    `form:"offset" binding:"gte=0"`
+}
+
+// SearchHighlights фрагменты с совпадениями, выделенными тегом <mark>.
+// Текст экранирован как HTML, другой разметки в нем нет
+type SearchHighlights struct {
+	Description string `json:"description"`
+	Notes       string `json:"notes,omitempty"`
+}
+
+type TransactionSearchResult struct {
+	TransactionResponse
+	Rank       float64          `json:"rank"`
+	Highlights SearchHighlights `json:"highlights"`
+}
+
+type TransactionSearchResponse struct {
+	Total   int64                     `json:"total"`
+	Results []TransactionSearchResult `json:"results"`
+}
@@ -0,0 +1,60 @@
+package dto
+
+import "time"
+
+type SplitParticipant struct {
+	UserID uint    `json:"user_id" binding:"required"`
+	Value  float64 `json:"value"` // доли, проценты или точная сумма в зависимости от способа
+}
+
+type CreateSplitExpenseRequest struct {
+	CategoryID   *uint              `json:"category_id,omitempty"`
+	PayeeID      *uint              `json:"payee_id,omitempty"`
+	Amount       float64            `json:"amount" binding:"required,gt=0"`
+	Description  string             `json:"description" binding:"required"`
+	Notes        string             `json:"notes,omitempty"`
+	Date         string             `json:"date" binding:"required"`
+	PaidBy       *uint              `json:"paid_by,omitempty"`
+	Method       string             `json:"method" binding:"required,oneof=equal shares percent exact"`
+	Participants []SplitParticipant `json:"participants" binding:"required,min=1,dive"`
+}
+
+type SplitResponse struct {
+	UserID uint    `json:"user_id"`
+	Amount float64 `json:"amount"`
+}
+
+type MemberBalance struct {
+	UserID    uint    `json:"user_id"`
+	FirstName string  `json:"first_name"`
+	LastName  string  `json:"last_name"`
+	Balance   float64 `json:"balance"` // положительный — участнику должны, отрицательный — должен он
+}
+
+type Debt struct {
+	FromUserID uint    `json:"from_user_id"`
+	ToUserID   uint    `json:"to_user_id"`
+	Amount     float64 `json:"amount"`
+}
+
+type BalancesResponse struct {
+	Balances []MemberBalance `json:"balances"`
+	Debts    []Debt          `json:"debts"`
+}
+
+type CreateSettlementRequest struct {
+	FromUserID *uint   `json:"from_user_id,omitempty"`
+	ToUserID   uint    `json:"to_user_id" binding:"required"`
+	Amount     float64 `json:"amount" binding:"required,gt=0"`
+	Date       string  `json:"date,omitempty"`
+	Note       string  `json:"note,omitempty"`
+}
+
+type SettlementResponse struct {
+	ID         uint      `json:"id"`
+	FromUserID uint      `json:"from_user_id"`
+	ToUserID   uint      `json:"to_user_id"`
+	Amount     float64   `json:"amount"`
+	Date       time.Time `json:"date"`
+	Note       string    `json:"note,omitempty"`
+}
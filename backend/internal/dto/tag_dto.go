@@ -0,0 +1,22 @@
+package dto
+
+type TagRequest struct {
+	Name  string `json:"name" binding:"required"`
+	Color string `json:"color,omitempty"`
+}
+
+type TagResponse struct {
+	ID    uint   `json:"id"`
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+type TagReport struct {
+	TagID        uint    `json:"tag_id"`
+	Name         string  `json:"name"`
+	Color        string  `json:"color"`
+	TotalIncome  float64 `json:"total_income"`
+	TotalExpense float64 `json:"total_expense"`
+	Balance      float64 `json:"balance"`
+	Count        int64   `json:"count"`
+}
@@ -0,0 +1,24 @@
+package dto
+
+import "time"
+
+// TrashResponse содержимое корзины бюджета
+type TrashResponse struct {
+	Transactions []TrashedTransaction `json:"transactions"`
+	Categories   []TrashedCategory    `json:"categories"`
+}
+
+type TrashedTransaction struct {
+	TransactionResponse
+	DeletedAt time.Time `json:"deleted_at"`
+	PurgeAt   time.Time `json:"purge_at"` // когда транзакция будет удалена окончательно
+}
+
+type TrashedCategory struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	Type      string    `json:"type"`
+	Color     string    `json:"color"`
+	DeletedAt time.Time `json:"deleted_at"`
+	PurgeAt   time.Time `json:"purge_at"`
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"finance-backend/internal/dto"
+	"finance-backend/internal/service"
+)
+
+type AccountHandler struct {
+	accountService *service.AccountService
+}
+
+func NewAccountHandler(as *service.AccountService) *AccountHandler {
+	return &AccountHandler{accountService: as}
+}
+
+// ExportData отдает полный архив данных пользователя в JSON
+func (h *AccountHandler) ExportData(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	export, err := h.accountService.Export(c.Request.Context(), userID)
+	if err != nil {
+		respondError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="finance-export.json"`)
+	c.JSON(http.StatusOK, export)
+}
+
+// DeleteAccount планирует удаление аккаунта
+func (h *AccountHandler) DeleteAccount(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	var req dto.DeleteAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	deleteAt, err := h.accountService.ScheduleDeletion(c.Request.Context(), userID, req)
+	if err != nil {
+		respondError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, dto.AccountDeletionResponse{
+		Message:             "account scheduled for deletion, download your data before the deadline",
+		DeletionScheduledAt: deleteAt,
+		ExportURL:           "/api/auth/export",
+	})
+}
+
+// CancelDeletion отменяет запланированное удаление аккаунта
+func (h *AccountHandler) CancelDeletion(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	if err := h.accountService.CancelDeletion(c.Request.Context(), userID); err != nil {
+		respondError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "account deletion cancelled"})
+}
@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"finance-backend/internal/dto"
+	"finance-backend/internal/service"
+)
+
+type AlertHandler struct {
+	anomalyService *service.AnomalyService
+}
+
+func NewAlertHandler(as *service.AnomalyService) *AlertHandler {
+	return &AlertHandler{anomalyService: as}
+}
+
+// GetAlerts возвращает предупреждения о необычных тратах; по умолчанию только непросмотренные
+func (h *AlertHandler) GetAlerts(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+	ledgerID := c.MustGet("ledgerID").(uint)
+
+	var query dto.AlertQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		respondError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	alerts, err := h.anomalyService.GetAlerts(c.Request.Context(), userID, ledgerID, query)
+	if err != nil {
+		respondError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, alerts)
+}
+
+// AcknowledgeAlert отмечает предупреждение как просмотренное
+func (h *AlertHandler) AcknowledgeAlert(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+	ledgerID := c.MustGet("ledgerID").(uint)
+
+	id, ok := parseIDParam(c, "id", "invalid alert ID")
+	if !ok {
+		return
+	}
+
+	alert, err := h.anomalyService.AcknowledgeAlert(c.Request.Context(), userID, ledgerID, id)
+	if err != nil {
+		respondError(c, err, http.StatusNotFound)
+		return
+	}
+
+	c.JSON(http.StatusOK, alert)
+}
@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+
+	"finance-backend/internal/service"
+)
+
+// multipartOverhead запас на заголовки multipart сверх размера файла
+const multipartOverhead = 1 << 20
+
+type AttachmentHandler struct {
+	attachmentService *service.AttachmentService
+}
+
+func NewAttachmentHandler(as *service.AttachmentService) *AttachmentHandler {
+	return &AttachmentHandler{attachmentService: as}
+}
+
+// UploadAttachment загружает файл к транзакции (multipart, поле file)
+func (h *AttachmentHandler) UploadAttachment(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+	ledgerID := c.MustGet("ledgerID").(uint)
+
+	transactionID, ok := parseIDParam(c, "id", "invalid transaction ID")
+	if !ok {
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.attachmentService.MaxFileSize()+multipartOverhead)
+
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": service.ErrFileTooLarge.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		return
+	}
+	defer file.Close()
+
+	attachment, err := h.attachmentService.Upload(c.Request.Context(), userID, ledgerID, transactionID, header.Filename, file)
+	if err != nil {
+		respondError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	c.JSON(http.StatusCreated, attachment)
+}
+
+// GetAttachments возвращает вложения транзакции
+func (h *AttachmentHandler) GetAttachments(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+	ledgerID := c.MustGet("ledgerID").(uint)
+
+	transactionID, ok := parseIDParam(c, "id", "invalid transaction ID")
+	if !ok {
+		return
+	}
+
+	attachments, err := h.attachmentService.GetTransactionAttachments(c.Request.Context(), userID, ledgerID, transactionID)
+	if err != nil {
+		respondError(c, err, http.StatusNotFound)
+		return
+	}
+
+	c.JSON(http.StatusOK, attachments)
+}
+
+// DownloadAttachment отдает файл вложения
+func (h *AttachmentHandler) DownloadAttachment(c *gin.Context) {
+	h.serve(c, false)
+}
+
+// GetThumbnail отдает миниатюру изображения
+func (h *AttachmentHandler) GetThumbnail(c *gin.Context) {
+	h.serve(c, true)
+}
+
+// DeleteAttachment удаляет вложение
+func (h *AttachmentHandler) DeleteAttachment(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+	ledgerID := c.MustGet("ledgerID").(uint)
+
+	id, ok := parseIDParam(c, "id", "invalid attachment ID")
+	if !ok {
+		return
+	}
+
+	if err := h.attachmentService.Delete(c.Request.Context(), userID, ledgerID, id); err != nil {
+		respondError(c, err, http.StatusNotFound)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "attachment deleted"})
+}
+
+func (h *AttachmentHandler) serve(c *gin.Context, thumb bool) {
+	userID := c.MustGet("userID").(uint)
+	ledgerID := c.MustGet("ledgerID").(uint)
+
+	id, ok := parseIDParam(c, "id", "invalid attachment ID")
+	if !ok {
+		return
+	}
+
+	attachment, body, err := h.attachmentService.Open(c.Request.Context(), userID, ledgerID, id, thumb)
+	if err != nil {
+		respondError(c, err, http.StatusNotFound)
+		return
+	}
+	defer body.Close()
+
+	if thumb {
+		c.DataFromReader(http.StatusOK, -1, "image/jpeg", body, nil)
+		return
+	}
+
+	disposition := "attachment"
+	if attachment.ContentType != "application/pdf" {
+		disposition = "inline"
+	}
+	c.DataFromReader(http.StatusOK, attachment.Size, attachment.ContentType, body, map[string]string{
+		"Content-Disposition":    fmt.Sprintf(`%s; filename*=UTF-8''%s`, disposition, url.PathEscape(attachment.FileName)),
+		"X-Content-Type-Options": "nosniff",
+	})
+}
@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"finance-backend/internal/dto"
+	"finance-backend/internal/model"
+	"finance-backend/internal/service"
+)
+
+type AuditHandler struct {
+	auditService  *service.AuditService
+	ledgerService *service.LedgerService
+}
+
+func NewAuditHandler(as *service.AuditService, ls *service.LedgerService) *AuditHandler {
+	return &AuditHandler{auditService: as, ledgerService: ls}
+}
+
+// GetTransactionHistory возвращает историю изменений транзакции
+func (h *AuditHandler) GetTransactionHistory(c *gin.Context) {
+	h.entityHistory(c, model.AuditEntityTransaction, "invalid transaction ID")
+}
+
+// GetCategoryHistory возвращает историю изменений категории
+func (h *AuditHandler) GetCategoryHistory(c *gin.Context) {
+	h.entityHistory(c, model.AuditEntityCategory, "invalid category ID")
+}
+
+// GetLedgerHistory возвращает историю изменений бюджета; доступна его участникам
+func (h *AuditHandler) GetLedgerHistory(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	ledgerID, ok := parseIDParam(c, "id", "invalid ledger ID")
+	if !ok {
+		return
+	}
+	if _, err := h.ledgerService.ResolveLedger(c.Request.Context(), userID, &ledgerID); err != nil {
+		respondError(c, err, http.StatusNotFound)
+		return
+	}
+
+	var query dto.AuditQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		respondError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	history, err := h.auditService.GetEntityHistory(c.Request.Context(), ledgerID, model.AuditEntityLedger, ledgerID, query)
+	if err != nil {
+		respondError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// GetActivity возвращает ленту изменений текущего пользователя
+func (h *AuditHandler) GetActivity(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	var query dto.AuditQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		respondError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	activity, err := h.auditService.GetActivity(c.Request.Context(), userID, query)
+	if err != nil {
+		respondError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, activity)
+}
+
+// entityHistory отдает историю сущности текущего бюджета; история доступна
+// и после удаления сущности
+func (h *AuditHandler) entityHistory(c *gin.Context, entityType, invalidID string) {
+	ledgerID := c.MustGet("ledgerID").(uint)
+
+	id, ok := parseIDParam(c, "id", invalidID)
+	if !ok {
+		return
+	}
+
+	var query dto.AuditQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		respondError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	history, err := h.auditService.GetEntityHistory(c.Request.Context(), ledgerID, entityType, id, query)
+	if err != nil {
+		respondError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
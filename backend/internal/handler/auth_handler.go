@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"finance-backend/internal/dto"
+	"finance-backend/internal/model"
 	"finance-backend/internal/service"
 
 	"github.com/gin-gonic/gin"
@@ -25,13 +26,13 @@ func NewAuthHandler(userService *service.UserService, authService *service.AuthS
 func (h *AuthHandler) Register(c *gin.Context) {
 	var req dto.RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(c, err, http.StatusBadRequest)
 		return
 	}
 
-	user, err := h.userService.Register(req)
+	user, err := h.userService.Register(c.Request.Context(), req)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(c, err, http.StatusBadRequest)
 		return
 	}
 
@@ -41,35 +42,20 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
-	response := dto.AuthResponse{
-		Token: token,
-		User: struct {
-			ID        uint   `json:"id"`
-			Email     string `json:"email"`
-			FirstName string `json:"first_name"`
-			LastName  string `json:"last_name"`
-		}{
-			ID:        user.ID,
-			Email:     user.Email,
-			FirstName: user.FirstName,
-			LastName:  user.LastName,
-		},
-	}
-
-	c.JSON(http.StatusCreated, response)
+	c.JSON(http.StatusCreated, newAuthResponse(token, user))
 }
 
 // Login обрабатывает вход
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req dto.LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(c, err, http.StatusBadRequest)
 		return
 	}
 
-	user, err := h.userService.Login(req)
+	user, err := h.userService.Login(c.Request.Context(), req)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		respondError(c, err, http.StatusUnauthorized)
 		return
 	}
 
@@ -79,39 +65,127 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	response := dto.AuthResponse{
-		Token: token,
-		User: struct {
-			ID        uint   `json:"id"`
-			Email     string `json:"email"`
-			FirstName string `json:"first_name"`
-			LastName  string `json:"last_name"`
-		}{
-			ID:        user.ID,
-			Email:     user.Email,
-			FirstName: user.FirstName,
-			LastName:  user.LastName,
-		},
-	}
-
-	c.JSON(http.StatusOK, response)
+	c.JSON(http.StatusOK, newAuthResponse(token, user))
 }
 
 // GetProfile возвращает профиль пользователя
 func (h *AuthHandler) GetProfile(c *gin.Context) {
 	userID := c.MustGet("userID").(uint)
 
-	user, err := h.userService.GetUserByID(userID)
+	user, err := h.userService.GetUserByID(c.Request.Context(), userID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"id":         user.ID,
-		"email":      user.Email,
-		"first_name": user.FirstName,
-		"last_name":  user.LastName,
-		"created_at": user.CreatedAt,
+		"id":             user.ID,
+		"email":          user.Email,
+		"first_name":     user.FirstName,
+		"last_name":      user.LastName,
+		"email_verified": user.EmailVerified,
+		"created_at":     user.CreatedAt,
+
+		"deletion_scheduled_at": user.DeletionScheduledAt,
 	})
 }
+
+// UpdateProfile обновляет имя и фамилию пользователя
+func (h *AuthHandler) UpdateProfile(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	var req dto.UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.userService.UpdateProfile(c.Request.Context(), userID, req)
+	if err != nil {
+		respondError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	c.JSON(http.StatusOK, newAuthUser(user))
+}
+
+// RequestEmailChange отправляет подтверждение на новый email
+func (h *AuthHandler) RequestEmailChange(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	var req dto.ChangeEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	if err := h.userService.RequestEmailChange(c.Request.Context(), userID, req); err != nil {
+		respondError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "confirmation email sent to the new address"})
+}
+
+// ConfirmEmailChange применяет смену email по ссылке из письма
+func (h *AuthHandler) ConfirmEmailChange(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "token is required"})
+		return
+	}
+
+	if _, err := h.userService.ConfirmEmailChange(c.Request.Context(), token); err != nil {
+		respondError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "email changed"})
+}
+
+// VerifyEmail подтверждает email по ссылке из письма
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "token is required"})
+		return
+	}
+
+	if _, err := h.userService.VerifyEmail(c.Request.Context(), token); err != nil {
+		respondError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "email verified"})
+}
+
+// ResendVerification повторно отправляет письмо с подтверждением email
+func (h *AuthHandler) ResendVerification(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	if err := h.userService.ResendVerificationEmail(c.Request.Context(), userID); err != nil {
+		respondError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "verification email sent"})
+}
+
+// newAuthResponse формирует ответ с токеном и данными пользователя
+func newAuthResponse(token string, user *model.User) dto.AuthResponse {
+	return dto.AuthResponse{
+		Token: token,
+		User:  newAuthUser(user),
+	}
+}
+
+// newAuthUser формирует публичное представление пользователя
+func newAuthUser(user *model.User) dto.AuthUser {
+	return dto.AuthUser{
+		ID:            user.ID,
+		Email:         user.Email,
+		FirstName:     user.FirstName,
+		LastName:      user.LastName,
+		EmailVerified: user.EmailVerified,
+	}
+}
@@ -0,0 +1,145 @@
+package handler_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"finance-backend/internal/dto"
+)
+
+func TestRegister(t *testing.T) {
+	s := newServer()
+
+	w := s.do(http.MethodPost, "/api/auth/register", "", gin.H{
+		"email": "anna@example.com", "password": "secret1", "first_name": "Анна", "last_name": "Иванова",
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("register: %d %s", w.Code, w.Body)
+	}
+	var resp dto.AuthResponse
+	decode(t, w, &resp)
+	if resp.Token == "" || resp.User.Email != "anna@example.com" || resp.User.EmailVerified {
+		t.Errorf("register response: %+v", resp)
+	}
+
+	tests := []struct {
+		name string
+		body any
+		want string
+	}{
+		{"duplicate email", gin.H{"email": "anna@example.com", "password": "secret1", "first_name": "А", "last_name": "И"},
+			"user with this email already exists"},
+		{"short password", gin.H{"email": "boris@example.com", "password": "123", "first_name": "Б", "last_name": "П"}, ""},
+		{"invalid email", gin.H{"email": "boris", "password": "secret1", "first_name": "Б", "last_name": "П"}, ""},
+		{"malformed JSON", `{"email":`, ""},
+	}
+	for _, tt := range tests {
+		w := s.do(http.MethodPost, "/api/auth/register", "", tt.body)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", tt.name, w.Code)
+			continue
+		}
+		if msg := errorMessage(t, w); tt.want != "" && msg != tt.want {
+			t.Errorf("%s: error %q, want %q", tt.name, msg, tt.want)
+		}
+	}
+}
+
+func TestLogin(t *testing.T) {
+	s := newServer()
+	userID, _ := s.register(t, "anna@example.com")
+
+	w := s.do(http.MethodPost, "/api/auth/login", "", gin.H{"email": "anna@example.com", "password": "secret1"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("login: %d %s", w.Code, w.Body)
+	}
+	var resp dto.AuthResponse
+	decode(t, w, &resp)
+	if resp.Token == "" || resp.User.ID != userID {
+		t.Errorf("login response: %+v", resp)
+	}
+
+	// Токен из ответа открывает защищенные маршруты
+	if w := s.do(http.MethodGet, "/api/auth/profile", resp.Token, nil); w.Code != http.StatusOK {
+		t.Errorf("profile with the login token: %d %s", w.Code, w.Body)
+	}
+
+	for _, body := range []gin.H{
+		{"email": "anna@example.com", "password": "wrong"},
+		{"email": "nobody@example.com", "password": "secret1"},
+	} {
+		w := s.do(http.MethodPost, "/api/auth/login", "", body)
+		if w.Code != http.StatusUnauthorized || errorMessage(t, w) != "invalid email or password" {
+			t.Errorf("login %v: %d %s", body, w.Code, w.Body)
+		}
+	}
+}
+
+func TestProfile(t *testing.T) {
+	s := newServer()
+	_, token := s.register(t, "anna@example.com")
+
+	for _, tt := range []struct {
+		name, header string
+	}{
+		{"no header", ""},
+		{"not bearer", "Basic abc"},
+		{"invalid token", "Bearer garbage"},
+	} {
+		w := s.do(http.MethodGet, "/api/auth/profile", "", nil, "Authorization", tt.header)
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("%s: status %d, want 401", tt.name, w.Code)
+		}
+	}
+
+	w := s.do(http.MethodPut, "/api/auth/profile", token, gin.H{"first_name": "Мария", "last_name": "Петрова"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("update profile: %d %s", w.Code, w.Body)
+	}
+
+	w = s.do(http.MethodGet, "/api/auth/profile", token, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("profile: %d %s", w.Code, w.Body)
+	}
+	var profile struct {
+		Email     string `json:"email"`
+		FirstName string `json:"first_name"`
+		LastName  string `json:"last_name"`
+	}
+	decode(t, w, &profile)
+	if profile.Email != "anna@example.com" || profile.FirstName != "Мария" || profile.LastName != "Петрова" {
+		t.Errorf("profile = %+v", profile)
+	}
+
+	if w := s.do(http.MethodPut, "/api/auth/profile", token, gin.H{"first_name": "Мария"}); w.Code != http.StatusBadRequest {
+		t.Errorf("update profile without last name: %d", w.Code)
+	}
+}
+
+func TestVerifyEmail(t *testing.T) {
+	s := newServer()
+	_, token := s.register(t, "anna@example.com")
+
+	if w := s.do(http.MethodGet, "/api/auth/verify-email", "", nil); w.Code != http.StatusBadRequest {
+		t.Errorf("verify without token: %d", w.Code)
+	}
+	if w := s.do(http.MethodGet, "/api/auth/verify-email?token=garbage", "", nil); w.Code != http.StatusBadRequest {
+		t.Errorf("verify with an invalid token: %d", w.Code)
+	}
+
+	w := s.do(http.MethodGet, "/api/auth/verify-email?token="+s.mailbox.token(t, "anna@example.com"), "", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("verify: %d %s", w.Code, w.Body)
+	}
+
+	w = s.do(http.MethodGet, "/api/auth/profile", token, nil)
+	var profile struct {
+		EmailVerified bool `json:"email_verified"`
+	}
+	decode(t, w, &profile)
+	if !profile.EmailVerified {
+		t.Error("email is not verified after following the link")
+	}
+}
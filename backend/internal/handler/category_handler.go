@@ -11,26 +11,28 @@ import (
 )
 
 type CategoryHandler struct {
-	categoryService *service.CategoryService
+	categoryService   *service.CategoryService
+	suggestionService *service.SuggestionService
 }
 
-func NewCategoryHandler(cs *service.CategoryService) *CategoryHandler {
-	return &CategoryHandler{categoryService: cs}
+func NewCategoryHandler(cs *service.CategoryService, ss *service.SuggestionService) *CategoryHandler {
+	return &CategoryHandler{categoryService: cs, suggestionService: ss}
 }
 
 // CreateCategory создает категорию
 func (h *CategoryHandler) CreateCategory(c *gin.Context) {
 	userID := c.MustGet("userID").(uint)
+	ledgerID := c.MustGet("ledgerID").(uint)
 
 	var req dto.CreateCategoryRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(c, err, http.StatusBadRequest)
 		return
 	}
 
-	category, err := h.categoryService.CreateCategory(userID, req)
+	category, err := h.categoryService.CreateCategory(c.Request.Context(), userID, ledgerID, req)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(c, err, http.StatusBadRequest)
 		return
 	}
 
@@ -40,19 +42,44 @@ func (h *CategoryHandler) CreateCategory(c *gin.Context) {
 // GetCategories возвращает категории пользователя
 func (h *CategoryHandler) GetCategories(c *gin.Context) {
 	userID := c.MustGet("userID").(uint)
+	ledgerID := c.MustGet("ledgerID").(uint)
 
-	categories, err := h.categoryService.GetUserCategories(userID)
+	categories, err := h.categoryService.GetLedgerCategories(c.Request.Context(), userID, ledgerID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err, http.StatusInternalServerError)
 		return
 	}
 
 	c.JSON(http.StatusOK, categories)
 }
 
+// SuggestCategories подсказывает категорию для описания по истории пользователя
+func (h *CategoryHandler) SuggestCategories(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+	ledgerID := c.MustGet("ledgerID").(uint)
+
+	var query dto.SuggestCategoryQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		respondError(c, err, http.StatusBadRequest)
+		return
+	}
+	if query.Limit == 0 {
+		query.Limit = 3
+	}
+
+	suggestions, err := h.suggestionService.SuggestCategories(c.Request.Context(), userID, ledgerID, query.Description, query.Amount, query.Type, query.Limit)
+	if err != nil {
+		respondError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, suggestions)
+}
+
 // DeleteCategory удаляет категорию
 func (h *CategoryHandler) DeleteCategory(c *gin.Context) {
 	userID := c.MustGet("userID").(uint)
+	ledgerID := c.MustGet("ledgerID").(uint)
 
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
@@ -61,9 +88,9 @@ func (h *CategoryHandler) DeleteCategory(c *gin.Context) {
 		return
 	}
 
-	err = h.categoryService.DeleteCategory(userID, uint(id))
+	err = h.categoryService.DeleteCategory(c.Request.Context(), userID, ledgerID, uint(id))
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		respondError(c, err, http.StatusNotFound)
 		return
 	}
 
@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"finance-backend/internal/dto"
+	"finance-backend/internal/service"
+)
+
+type DuplicateHandler struct {
+	duplicateService *service.DuplicateService
+}
+
+func NewDuplicateHandler(ds *service.DuplicateService) *DuplicateHandler {
+	return &DuplicateHandler{duplicateService: ds}
+}
+
+// GetDuplicates возвращает пары транзакций, похожих на дубликаты
+func (h *DuplicateHandler) GetDuplicates(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+	ledgerID := c.MustGet("ledgerID").(uint)
+
+	from, to := parseDateRange(c)
+
+	candidates, err := h.duplicateService.FindDuplicates(c.Request.Context(), userID, ledgerID, from, to)
+	if err != nil {
+		respondError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, candidates)
+}
+
+// MergeDuplicates объединяет две транзакции в одну
+func (h *DuplicateHandler) MergeDuplicates(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+	ledgerID := c.MustGet("ledgerID").(uint)
+
+	var req dto.MergeDuplicateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	transaction, err := h.duplicateService.Merge(c.Request.Context(), userID, ledgerID, req)
+	if err != nil {
+		respondError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	c.JSON(http.StatusOK, transaction)
+}
+
+// DismissDuplicates отмечает пару как не дубликат
+func (h *DuplicateHandler) DismissDuplicates(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+	ledgerID := c.MustGet("ledgerID").(uint)
+
+	var req dto.DismissDuplicateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	if err := h.duplicateService.Dismiss(c.Request.Context(), userID, ledgerID, req); err != nil {
+		respondError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "duplicate dismissed"})
+}
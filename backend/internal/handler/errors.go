@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"finance-backend/internal/repository"
+	"finance-backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respondError отвечает ошибкой сервиса. Причина внутренних ошибок попадает
+// в лог запроса, а клиент получает только общее сообщение
+func respondError(c *gin.Context, err error, fallback int) {
+	status := errorStatus(err, fallback)
+	if status >= http.StatusInternalServerError {
+		c.Error(err)
+		c.JSON(status, gin.H{"error": "internal server error"})
+		return
+	}
+	c.JSON(status, gin.H{"error": err.Error()})
+}
+
+// errorStatus подбирает HTTP статус для ошибки сервиса
+func errorStatus(err error, fallback int) int {
+	switch {
+	case errors.Is(err, repository.ErrDatabase):
+		return http.StatusInternalServerError
+	case errors.Is(err, service.ErrForbidden), errors.Is(err, service.ErrQuotaExceeded):
+		return http.StatusForbidden
+	case errors.Is(err, service.ErrFileTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, service.ErrUnsupportedFile):
+		return http.StatusUnsupportedMediaType
+	}
+	return fallback
+}
@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"finance-backend/internal/dto"
+	"finance-backend/internal/service"
+)
+
+type ForecastHandler struct {
+	forecastService *service.ForecastService
+}
+
+func NewForecastHandler(fs *service.ForecastService) *ForecastHandler {
+	return &ForecastHandler{forecastService: fs}
+}
+
+// GetForecast возвращает прогноз ежедневного остатка бюджета на несколько месяцев
+func (h *ForecastHandler) GetForecast(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+	ledgerID := c.MustGet("ledgerID").(uint)
+
+	var query dto.ForecastQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		respondError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	forecast, err := h.forecastService.Forecast(c.Request.Context(), userID, ledgerID, query)
+	if err != nil {
+		respondError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, forecast)
+}
@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"finance-backend/internal/dto"
+	"finance-backend/internal/service"
+)
+
+type GoalHandler struct {
+	goalService *service.GoalService
+}
+
+func NewGoalHandler(gs *service.GoalService) *GoalHandler {
+	return &GoalHandler{goalService: gs}
+}
+
+// CreateGoal создает цель накопления
+func (h *GoalHandler) CreateGoal(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+	ledgerID := c.MustGet("ledgerID").(uint)
+
+	var req dto.GoalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	goal, err := h.goalService.CreateGoal(c.Request.Context(), userID, ledgerID, req)
+	if err != nil {
+		respondError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	c.JSON(http.StatusCreated, goal)
+}
+
+// GetGoals возвращает цели бюджета с прогрессом
+func (h *GoalHandler) GetGoals(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+	ledgerID := c.MustGet("ledgerID").(uint)
+
+	goals, err := h.goalService.GetLedgerGoals(c.Request.Context(), userID, ledgerID)
+	if err != nil {
+		respondError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, goals)
+}
+
+// GetGoal возвращает цель с прогрессом и прогнозом
+func (h *GoalHandler) GetGoal(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+	ledgerID := c.MustGet("ledgerID").(uint)
+
+	id, ok := parseIDParam(c, "id", "invalid goal ID")
+	if !ok {
+		return
+	}
+
+	goal, err := h.goalService.GetGoal(c.Request.Context(), userID, ledgerID, id)
+	if err != nil {
+		respondError(c, err, http.StatusNotFound)
+		return
+	}
+
+	c.JSON(http.StatusOK, goal)
+}
+
+// UpdateGoal изменяет цель
+func (h *GoalHandler) UpdateGoal(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+	ledgerID := c.MustGet("ledgerID").(uint)
+
+	id, ok := parseIDParam(c, "id", "invalid goal ID")
+	if !ok {
+		return
+	}
+
+	var req dto.GoalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	goal, err := h.goalService.UpdateGoal(c.Request.Context(), userID, ledgerID, id, req)
+	if err != nil {
+		respondError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	c.JSON(http.StatusOK, goal)
+}
+
+// DeleteGoal удаляет цель
+func (h *GoalHandler) DeleteGoal(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+	ledgerID := c.MustGet("ledgerID").(uint)
+
+	id, ok := parseIDParam(c, "id", "invalid goal ID")
+	if !ok {
+		return
+	}
+
+	if err := h.goalService.DeleteGoal(c.Request.Context(), userID, ledgerID, id); err != nil {
+		respondError(c, err, http.StatusNotFound)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "goal deleted"})
+}
@@ -0,0 +1,213 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"finance-backend/internal/dto"
+	"finance-backend/internal/handler"
+	"finance-backend/internal/middleware"
+	"finance-backend/internal/model"
+	"finance-backend/internal/repository/memory"
+	"finance-backend/internal/service"
+)
+
+// Обработчики проверяются через HTTP на сервисах с хранилищами в памяти.
+// Маршруты повторяют app.go; бюджет выбирается заголовком X-Ledger-ID,
+// по умолчанию — личный, с ID пользователя
+
+// ledgers заглушка сервиса бюджетов: роли участников берутся из хранилища
+type ledgers struct {
+	store *memory.Store
+}
+
+func (l *ledgers) addMember(ledgerID, userID uint, role string) {
+	l.store.AddLedgerMember(ledgerID, userID, role)
+}
+
+func (l *ledgers) role(ledgerID, userID uint) string {
+	return l.store.LedgerRole(ledgerID, userID)
+}
+
+func (l *ledgers) CheckWriteAccess(_ context.Context, userID, ledgerID uint) error {
+	switch l.role(ledgerID, userID) {
+	case "":
+		return errors.New("ledger not found")
+	case model.RoleViewer:
+		return service.ErrForbidden
+	}
+	return nil
+}
+
+// middleware заменяет LedgerMiddleware, которому нужен настоящий сервис бюджетов
+func (l *ledgers) middleware(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+	ledgerID := userID
+	if raw := c.GetHeader("X-Ledger-ID"); raw != "" {
+		id, _ := strconv.ParseUint(raw, 10, 32)
+		ledgerID = uint(id)
+	}
+	if l.role(ledgerID, userID) == "" {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "ledger not found"})
+		return
+	}
+	c.Set("ledgerID", ledgerID)
+	c.Next()
+}
+
+// noop заглушка журнала, правил, подсказок, получателей и поиска дубликатов
+type noop struct{}
+
+func (noop) Record(context.Context, uint, uint, string, uint, string, any, any) {}
+func (noop) ApplyRules(context.Context, uint, *model.Transaction) error         { return nil }
+func (noop) Learn(*model.Transaction)                                           {}
+func (noop) Forget(*model.Transaction)                                          {}
+func (noop) ApplyPayee(context.Context, uint, *model.Transaction, *uint, string) error {
+	return nil
+}
+func (noop) ValidatePayee(context.Context, uint, uint, *uint) error { return nil }
+func (noop) FindFor(context.Context, uint, *model.Transaction) ([]uint, error) {
+	return nil, nil
+}
+
+// mailbox запоминает отправленные письма
+type mailbox struct {
+	mu   sync.Mutex
+	sent map[string]string // адрес -> текст последнего письма
+}
+
+func (m *mailbox) Send(to, _, body string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent[to] = body
+	return nil
+}
+
+// token возвращает токен из ссылки в последнем письме на адрес to
+func (m *mailbox) token(t *testing.T, to string) string {
+	t.Helper()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, rest, ok := strings.Cut(m.sent[to], "token=")
+	if !ok {
+		t.Fatalf("no email with a token sent to %s", to)
+	}
+	token, err := url.QueryUnescape(strings.Fields(rest)[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+type server struct {
+	router  *gin.Engine
+	ledgers *ledgers
+	mailbox *mailbox
+}
+
+func newServer() *server {
+	gin.SetMode(gin.TestMode)
+
+	store := memory.NewStore()
+	s := &server{
+		router:  gin.New(),
+		ledgers: &ledgers{store: store},
+		mailbox: &mailbox{sent: make(map[string]string)},
+	}
+	s.router.Use(middleware.MetricsMiddleware())
+
+	categories := memory.NewCategoryRepository(store)
+	authService := service.NewAuthService("secret", time.Hour)
+	userService := service.NewUserService(memory.NewUserRepository(store), authService, s.mailbox, "http://app")
+	transactionService := service.NewTransactionService(memory.NewTransactionRepository(store), categories,
+		memory.NewTagRepository(store), s.ledgers, noop{}, noop{}, noop{}, noop{}, noop{})
+
+	authHandler := handler.NewAuthHandler(userService, authService)
+	transactionHandler := handler.NewTransactionHandler(transactionService)
+
+	s.router.POST("/api/auth/register", authHandler.Register)
+	s.router.POST("/api/auth/login", authHandler.Login)
+	s.router.GET("/api/auth/verify-email", authHandler.VerifyEmail)
+
+	api := s.router.Group("/api")
+	api.Use(middleware.AuthMiddleware(authService))
+	api.GET("/auth/profile", authHandler.GetProfile)
+	api.PUT("/auth/profile", authHandler.UpdateProfile)
+
+	ledger := api.Group("")
+	ledger.Use(s.ledgers.middleware)
+	ledger.POST("/transactions", transactionHandler.CreateTransaction)
+	ledger.GET("/transactions", transactionHandler.GetTransactions)
+	ledger.PUT("/transactions/:id", transactionHandler.UpdateTransaction)
+	ledger.DELETE("/transactions/:id", transactionHandler.DeleteTransaction)
+	return s
+}
+
+// do выполняет запрос; body сериализуется в JSON, если это не строка
+func (s *server) do(method, path, token string, body any, headers ...string) *httptest.ResponseRecorder {
+	var reader io.Reader
+	switch b := body.(type) {
+	case nil:
+	case string:
+		reader = strings.NewReader(b)
+	default:
+		data, _ := json.Marshal(b)
+		reader = strings.NewReader(string(data))
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+	return w
+}
+
+// register регистрирует пользователя и возвращает его ID и токен
+func (s *server) register(t *testing.T, email string) (uint, string) {
+	t.Helper()
+	w := s.do(http.MethodPost, "/api/auth/register", "", gin.H{
+		"email": email, "password": "secret1", "first_name": "Анна", "last_name": "Иванова",
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("register: %d %s", w.Code, w.Body)
+	}
+	var resp dto.AuthResponse
+	decode(t, w, &resp)
+	return resp.User.ID, resp.Token
+}
+
+func decode(t *testing.T, w *httptest.ResponseRecorder, v any) {
+	t.Helper()
+	if err := json.Unmarshal(w.Body.Bytes(), v); err != nil {
+		t.Fatalf("decode %s: %v", w.Body, err)
+	}
+}
+
+// errorMessage возвращает поле error ответа
+func errorMessage(t *testing.T, w *httptest.ResponseRecorder) string {
+	t.Helper()
+	var resp struct {
+		Error string `json:"error"`
+	}
+	decode(t, w, &resp)
+	return resp.Error
+}
@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"finance-backend/internal/migrations"
+)
+
+// readyTimeout ограничивает проверку базы, чтобы зависшее соединение не держало пробу
+const readyTimeout = 2 * time.Second
+
+type HealthHandler struct {
+	db       *sql.DB
+	migrator *migrations.Migrator
+}
+
+func NewHealthHandler(db *sql.DB, migrator *migrations.Migrator) *HealthHandler {
+	return &HealthHandler{db: db, migrator: migrator}
+}
+
+// Live сообщает, что процесс жив и обрабатывает запросы
+func (h *HealthHandler) Live(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Ready проверяет, что база доступна и все миграции применены
+func (h *HealthHandler) Ready(c *gin.Context) {
+	if err := h.check(c.Request.Context()); err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "unavailable", "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+func (h *HealthHandler) check(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, readyTimeout)
+	defer cancel()
+
+	if err := h.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("database unavailable: %w", err)
+	}
+
+	pending, err := h.migrator.Pending()
+	if err != nil {
+		return fmt.Errorf("check migrations: %w", err)
+	}
+	if pending > 0 {
+		return fmt.Errorf("%d migrations not applied", pending)
+	}
+	return nil
+}
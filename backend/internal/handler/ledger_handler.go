@@ -0,0 +1,253 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"finance-backend/internal/dto"
+	"finance-backend/internal/service"
+)
+
+type LedgerHandler struct {
+	ledgerService *service.LedgerService
+}
+
+func NewLedgerHandler(ls *service.LedgerService) *LedgerHandler {
+	return &LedgerHandler{ledgerService: ls}
+}
+
+// GetLedgers возвращает бюджеты пользователя
+func (h *LedgerHandler) GetLedgers(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	ledgers, err := h.ledgerService.GetUserLedgers(c.Request.Context(), userID)
+	if err != nil {
+		respondError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, ledgers)
+}
+
+// CreateLedger создает общий бюджет
+func (h *LedgerHandler) CreateLedger(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	var req dto.LedgerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	ledger, err := h.ledgerService.CreateLedger(c.Request.Context(), userID, req)
+	if err != nil {
+		respondError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	c.JSON(http.StatusCreated, ledger)
+}
+
+// UpdateLedger переименовывает бюджет
+func (h *LedgerHandler) UpdateLedger(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	ledgerID, ok := parseIDParam(c, "id", "invalid ledger ID")
+	if !ok {
+		return
+	}
+
+	var req dto.LedgerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	ledger, err := h.ledgerService.RenameLedger(c.Request.Context(), userID, ledgerID, req)
+	if err != nil {
+		respondError(c, err, http.StatusNotFound)
+		return
+	}
+
+	c.JSON(http.StatusOK, ledger)
+}
+
+// DeleteLedger удаляет общий бюджет
+func (h *LedgerHandler) DeleteLedger(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	ledgerID, ok := parseIDParam(c, "id", "invalid ledger ID")
+	if !ok {
+		return
+	}
+
+	if err := h.ledgerService.DeleteLedger(c.Request.Context(), userID, ledgerID); err != nil {
+		respondError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "ledger deleted"})
+}
+
+// GetMembers возвращает участников бюджета
+func (h *LedgerHandler) GetMembers(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	ledgerID, ok := parseIDParam(c, "id", "invalid ledger ID")
+	if !ok {
+		return
+	}
+
+	members, err := h.ledgerService.GetMembers(c.Request.Context(), userID, ledgerID)
+	if err != nil {
+		respondError(c, err, http.StatusNotFound)
+		return
+	}
+
+	c.JSON(http.StatusOK, members)
+}
+
+// UpdateMember меняет роль участника бюджета
+func (h *LedgerHandler) UpdateMember(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	ledgerID, ok := parseIDParam(c, "id", "invalid ledger ID")
+	if !ok {
+		return
+	}
+	memberID, ok := parseIDParam(c, "userId", "invalid user ID")
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateMemberRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	if err := h.ledgerService.UpdateMemberRole(c.Request.Context(), userID, ledgerID, memberID, req); err != nil {
+		respondError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "member updated"})
+}
+
+// RemoveMember исключает участника из бюджета или выходит из него
+func (h *LedgerHandler) RemoveMember(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	ledgerID, ok := parseIDParam(c, "id", "invalid ledger ID")
+	if !ok {
+		return
+	}
+	memberID, ok := parseIDParam(c, "userId", "invalid user ID")
+	if !ok {
+		return
+	}
+
+	if err := h.ledgerService.RemoveMember(c.Request.Context(), userID, ledgerID, memberID); err != nil {
+		respondError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "member removed"})
+}
+
+// Invite приглашает пользователя в бюджет
+func (h *LedgerHandler) Invite(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	ledgerID, ok := parseIDParam(c, "id", "invalid ledger ID")
+	if !ok {
+		return
+	}
+
+	var req dto.InviteMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	invitation, err := h.ledgerService.Invite(c.Request.Context(), userID, ledgerID, req)
+	if err != nil {
+		respondError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	c.JSON(http.StatusCreated, invitation)
+}
+
+// GetLedgerInvitations возвращает приглашения в бюджет
+func (h *LedgerHandler) GetLedgerInvitations(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	ledgerID, ok := parseIDParam(c, "id", "invalid ledger ID")
+	if !ok {
+		return
+	}
+
+	invitations, err := h.ledgerService.GetLedgerInvitations(c.Request.Context(), userID, ledgerID)
+	if err != nil {
+		respondError(c, err, http.StatusNotFound)
+		return
+	}
+
+	c.JSON(http.StatusOK, invitations)
+}
+
+// GetMyInvitations возвращает приглашения текущего пользователя
+func (h *LedgerHandler) GetMyInvitations(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	invitations, err := h.ledgerService.GetMyInvitations(c.Request.Context(), userID)
+	if err != nil {
+		respondError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, invitations)
+}
+
+// AcceptInvitation принимает приглашение
+func (h *LedgerHandler) AcceptInvitation(c *gin.Context) {
+	h.respondToInvitation(c, true)
+}
+
+// DeclineInvitation отклоняет приглашение
+func (h *LedgerHandler) DeclineInvitation(c *gin.Context) {
+	h.respondToInvitation(c, false)
+}
+
+func (h *LedgerHandler) respondToInvitation(c *gin.Context, accept bool) {
+	userID := c.MustGet("userID").(uint)
+
+	invitationID, ok := parseIDParam(c, "id", "invalid invitation ID")
+	if !ok {
+		return
+	}
+
+	if err := h.ledgerService.RespondToInvitation(c.Request.Context(), userID, invitationID, accept); err != nil {
+		respondError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	if accept {
+		c.JSON(http.StatusOK, gin.H{"message": "invitation accepted"})
+	} else {
+		c.JSON(http.StatusOK, gin.H{"message": "invitation declined"})
+	}
+}
+
+// parseIDParam разбирает числовой параметр пути; при ошибке отвечает 400
+func parseIDParam(c *gin.Context, name, message string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": message})
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -0,0 +1,76 @@
+package handler_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"finance-backend/internal/metrics"
+)
+
+// metricValue возвращает значение ряда series из ответа /metrics, или -1, если ряда нет
+func metricValue(t *testing.T, series string) float64 {
+	t.Helper()
+	w := httptest.NewRecorder()
+	metrics.Handler("").ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	for _, line := range strings.Split(w.Body.String(), "\n") {
+		if value, ok := strings.CutPrefix(line, series+" "); ok {
+			v, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				t.Fatalf("%s: %v", line, err)
+			}
+			return v
+		}
+	}
+	return -1
+}
+
+func TestMetricsAfterRequests(t *testing.T) {
+	s := newServer()
+	_, token := s.register(t, "anna@example.com")
+
+	const (
+		createdSeries  = `finance_transactions_created_total{type="expense"}`
+		failuresSeries = `finance_login_failures_total`
+		deleteSeries   = `http_request_duration_seconds_count{method="DELETE",route="/api/transactions/:id",status="200"}`
+	)
+	created := max(metricValue(t, createdSeries), 0)
+	failures := max(metricValue(t, failuresSeries), 0)
+	deletes := max(metricValue(t, deleteSeries), 0)
+
+	transaction := createTransaction(t, s, token, gin.H{
+		"amount": 100, "type": "expense", "description": "Кафе", "date": "2026-03-10T10:00:00Z",
+	})
+	path := fmt.Sprintf("/api/transactions/%d", transaction.ID)
+	if w := s.do(http.MethodDelete, path, token, nil); w.Code != http.StatusOK {
+		t.Fatalf("delete: %d %s", w.Code, w.Body)
+	}
+	s.do(http.MethodPost, "/api/auth/login", "", gin.H{"email": "anna@example.com", "password": "wrong"})
+	s.do(http.MethodGet, "/no/such/route", "", nil)
+
+	if got := metricValue(t, deleteSeries); got != deletes+1 {
+		t.Errorf("%s = %v, want %v", deleteSeries, got, deletes+1)
+	}
+	if got := metricValue(t, `http_request_duration_seconds_count{method="POST",route="/api/transactions",status="201"}`); got < 1 {
+		t.Error("no series for the create request")
+	}
+	if got := metricValue(t, `http_request_duration_seconds_count{method="GET",route="unmatched",status="404"}`); got < 1 {
+		t.Error("no series for the unmatched request")
+	}
+	// Путь с ID не должен порождать отдельный ряд
+	if got := metricValue(t, fmt.Sprintf(`http_request_duration_seconds_count{method="DELETE",route="%s",status="200"}`, path)); got != -1 {
+		t.Errorf("raw path is used as a route label")
+	}
+
+	if got := metricValue(t, createdSeries); got != created+1 {
+		t.Errorf("%s = %v, want %v", createdSeries, got, created+1)
+	}
+	if got := metricValue(t, failuresSeries); got != failures+1 {
+		t.Errorf("%s = %v, want %v", failuresSeries, got, failures+1)
+	}
+}
@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"finance-backend/internal/dto"
+	"finance-backend/internal/service"
+)
+
+type PayeeHandler struct {
+	payeeService *service.PayeeService
+}
+
+func NewPayeeHandler(ps *service.PayeeService) *PayeeHandler {
+	return &PayeeHandler{payeeService: ps}
+}
+
+// CreatePayee создает получателя
+func (h *PayeeHandler) CreatePayee(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+	ledgerID := c.MustGet("ledgerID").(uint)
+
+	var req dto.PayeeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	payee, err := h.payeeService.CreatePayee(c.Request.Context(), userID, ledgerID, req)
+	if err != nil {
+		respondError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	c.JSON(http.StatusCreated, payee)
+}
+
+// GetPayees возвращает получателей бюджета
+func (h *PayeeHandler) GetPayees(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+	ledgerID := c.MustGet("ledgerID").(uint)
+
+	payees, err := h.payeeService.GetLedgerPayees(c.Request.Context(), userID, ledgerID)
+	if err != nil {
+		respondError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, payees)
+}
+
+// UpdatePayee изменяет получателя
+func (h *PayeeHandler) UpdatePayee(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+	ledgerID := c.MustGet("ledgerID").(uint)
+
+	id, ok := parseIDParam(c, "id", "invalid payee ID")
+	if !ok {
+		return
+	}
+
+	var req dto.PayeeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	payee, err := h.payeeService.UpdatePayee(c.Request.Context(), userID, ledgerID, id, req)
+	if err != nil {
+		respondError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	c.JSON(http.StatusOK, payee)
+}
+
+// DeletePayee удаляет получателя
+func (h *PayeeHandler) DeletePayee(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+	ledgerID := c.MustGet("ledgerID").(uint)
+
+	id, ok := parseIDParam(c, "id", "invalid payee ID")
+	if !ok {
+		return
+	}
+
+	if err := h.payeeService.DeletePayee(c.Request.Context(), userID, ledgerID, id); err != nil {
+		respondError(c, err, http.StatusNotFound)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "payee deleted"})
+}
+
+// GetTopPayees возвращает получателей с наибольшими расходами за период
+func (h *PayeeHandler) GetTopPayees(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+	ledgerID := c.MustGet("ledgerID").(uint)
+
+	from, to := parseDateRange(c)
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	report, err := h.payeeService.GetTopPayees(c.Request.Context(), userID, ledgerID, from, to, limit)
+	if err != nil {
+		respondError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
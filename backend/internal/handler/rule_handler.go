@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"finance-backend/internal/dto"
+	"finance-backend/internal/service"
+)
+
+type RuleHandler struct {
+	ruleService *service.RuleService
+}
+
+func NewRuleHandler(rs *service.RuleService) *RuleHandler {
+	return &RuleHandler{ruleService: rs}
+}
+
+// CreateRule создает правило категоризации
+func (h *RuleHandler) CreateRule(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	var req dto.RuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	rule, err := h.ruleService.CreateRule(c.Request.Context(), userID, req)
+	if err != nil {
+		respondError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	c.JSON(http.StatusCreated, rule)
+}
+
+// GetRules возвращает правила пользователя
+func (h *RuleHandler) GetRules(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	rules, err := h.ruleService.GetUserRules(c.Request.Context(), userID)
+	if err != nil {
+		respondError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, rules)
+}
+
+// UpdateRule изменяет правило
+func (h *RuleHandler) UpdateRule(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	id, ok := parseIDParam(c, "id", "invalid rule ID")
+	if !ok {
+		return
+	}
+
+	var req dto.RuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	rule, err := h.ruleService.UpdateRule(c.Request.Context(), userID, id, req)
+	if err != nil {
+		respondError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// DeleteRule удаляет правило
+func (h *RuleHandler) DeleteRule(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	id, ok := parseIDParam(c, "id", "invalid rule ID")
+	if !ok {
+		return
+	}
+
+	if err := h.ruleService.DeleteRule(c.Request.Context(), userID, id); err != nil {
+		respondError(c, err, http.StatusNotFound)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "rule deleted"})
+}
+
+// DryRunRules показывает, какие транзакции бюджета изменят правила, ничего не сохраняя
+func (h *RuleHandler) DryRunRules(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+	ledgerID := c.MustGet("ledgerID").(uint)
+
+	// Тело необязательно: без него проверяются сохраненные правила
+	var req dto.RuleDryRunRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondError(c, err, http.StatusBadRequest)
+			return
+		}
+	}
+
+	from, to := parseDateRange(c)
+
+	result, err := h.ruleService.DryRun(c.Request.Context(), userID, ledgerID, from, to, req)
+	if err != nil {
+		respondError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ReapplyRules применяет правила к уже существующим транзакциям бюджета
+func (h *RuleHandler) ReapplyRules(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+	ledgerID := c.MustGet("ledgerID").(uint)
+
+	from, to := parseDateRange(c)
+
+	result, err := h.ruleService.Reapply(c.Request.Context(), userID, ledgerID, from, to)
+	if err != nil {
+		respondError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"finance-backend/internal/dto"
+	"finance-backend/internal/service"
+)
+
+type SearchHandler struct {
+	searchService *service.SearchService
+}
+
+func NewSearchHandler(ss *service.SearchService) *SearchHandler {
+	return &SearchHandler{searchService: ss}
+}
+
+// SearchTransactions ищет транзакции по тексту с учетом периода
+func (h *SearchHandler) SearchTransactions(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+	ledgerID := c.MustGet("ledgerID").(uint)
+
+	var query dto.TransactionSearchQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		respondError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	from, to := parseDateRange(c)
+
+	result, err := h.searchService.SearchTransactions(c.Request.Context(), userID, ledgerID, query, from, to)
+	if err != nil {
+		respondError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"finance-backend/internal/dto"
+	"finance-backend/internal/service"
+)
+
+type SplitHandler struct {
+	splitService *service.SplitService
+}
+
+func NewSplitHandler(ss *service.SplitService) *SplitHandler {
+	return &SplitHandler{splitService: ss}
+}
+
+// CreateSplitExpense создает общий расход с разделением между участниками
+func (h *SplitHandler) CreateSplitExpense(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+	ledgerID := c.MustGet("ledgerID").(uint)
+
+	var req dto.CreateSplitExpenseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	transaction, err := h.splitService.CreateSplitExpense(c.Request.Context(), userID, ledgerID, req)
+	if err != nil {
+		respondError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	c.JSON(http.StatusCreated, transaction)
+}
+
+// GetBalances возвращает балансы участников и упрощенный список долгов
+func (h *SplitHandler) GetBalances(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+	ledgerID := c.MustGet("ledgerID").(uint)
+
+	balances, err := h.splitService.GetBalances(c.Request.Context(), userID, ledgerID)
+	if err != nil {
+		respondError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, balances)
+}
+
+// CreateSettlement записывает возврат долга
+func (h *SplitHandler) CreateSettlement(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+	ledgerID := c.MustGet("ledgerID").(uint)
+
+	var req dto.CreateSettlementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	settlement, err := h.splitService.CreateSettlement(c.Request.Context(), userID, ledgerID, req)
+	if err != nil {
+		respondError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	c.JSON(http.StatusCreated, settlement)
+}
+
+// GetSettlements возвращает историю возвратов долгов
+func (h *SplitHandler) GetSettlements(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+	ledgerID := c.MustGet("ledgerID").(uint)
+
+	settlements, err := h.splitService.GetSettlements(c.Request.Context(), userID, ledgerID)
+	if err != nil {
+		respondError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, settlements)
+}
@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"finance-backend/internal/dto"
+	"finance-backend/internal/service"
+)
+
+type SubscriptionHandler struct {
+	subscriptionService *service.SubscriptionService
+}
+
+func NewSubscriptionHandler(ss *service.SubscriptionService) *SubscriptionHandler {
+	return &SubscriptionHandler{subscriptionService: ss}
+}
+
+// GetSubscriptions возвращает регулярные платежи, найденные в истории расходов
+func (h *SubscriptionHandler) GetSubscriptions(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+	ledgerID := c.MustGet("ledgerID").(uint)
+
+	subscriptions, err := h.subscriptionService.DetectSubscriptions(c.Request.Context(), userID, ledgerID)
+	if err != nil {
+		respondError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, subscriptions)
+}
+
+// ConfirmSubscription превращает найденную подписку в отслеживаемый регулярный платеж
+func (h *SubscriptionHandler) ConfirmSubscription(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+	ledgerID := c.MustGet("ledgerID").(uint)
+
+	var req dto.ConfirmSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	item, err := h.subscriptionService.ConfirmSubscription(c.Request.Context(), userID, ledgerID, req)
+	if err != nil {
+		respondError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	c.JSON(http.StatusCreated, item)
+}
+
+// GetRecurringItems возвращает отслеживаемые регулярные платежи
+func (h *SubscriptionHandler) GetRecurringItems(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+	ledgerID := c.MustGet("ledgerID").(uint)
+
+	items, err := h.subscriptionService.GetRecurringItems(c.Request.Context(), userID, ledgerID)
+	if err != nil {
+		respondError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, items)
+}
+
+// UpdateRecurringItem изменяет регулярный платеж
+func (h *SubscriptionHandler) UpdateRecurringItem(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+	ledgerID := c.MustGet("ledgerID").(uint)
+
+	id, ok := parseIDParam(c, "id", "invalid recurring item ID")
+	if !ok {
+		return
+	}
+
+	var req dto.RecurringItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	item, err := h.subscriptionService.UpdateRecurringItem(c.Request.Context(), userID, ledgerID, id, req)
+	if err != nil {
+		respondError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	c.JSON(http.StatusOK, item)
+}
+
+// DeleteRecurringItem прекращает отслеживать регулярный платеж
+func (h *SubscriptionHandler) DeleteRecurringItem(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+	ledgerID := c.MustGet("ledgerID").(uint)
+
+	id, ok := parseIDParam(c, "id", "invalid recurring item ID")
+	if !ok {
+		return
+	}
+
+	if err := h.subscriptionService.DeleteRecurringItem(c.Request.Context(), userID, ledgerID, id); err != nil {
+		respondError(c, err, http.StatusNotFound)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "recurring item deleted"})
+}
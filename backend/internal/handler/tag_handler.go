@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"finance-backend/internal/dto"
+	"finance-backend/internal/service"
+)
+
+type TagHandler struct {
+	tagService *service.TagService
+}
+
+func NewTagHandler(ts *service.TagService) *TagHandler {
+	return &TagHandler{tagService: ts}
+}
+
+// CreateTag создает тег
+func (h *TagHandler) CreateTag(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	var req dto.TagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	tag, err := h.tagService.CreateTag(c.Request.Context(), userID, req)
+	if err != nil {
+		respondError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	c.JSON(http.StatusCreated, tag)
+}
+
+// GetTags возвращает теги пользователя
+func (h *TagHandler) GetTags(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	tags, err := h.tagService.GetUserTags(c.Request.Context(), userID)
+	if err != nil {
+		respondError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, tags)
+}
+
+// UpdateTag изменяет тег
+func (h *TagHandler) UpdateTag(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	id, ok := parseIDParam(c, "id", "invalid tag ID")
+	if !ok {
+		return
+	}
+
+	var req dto.TagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	tag, err := h.tagService.UpdateTag(c.Request.Context(), userID, id, req)
+	if err != nil {
+		respondError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	c.JSON(http.StatusOK, tag)
+}
+
+// DeleteTag удаляет тег
+func (h *TagHandler) DeleteTag(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	id, ok := parseIDParam(c, "id", "invalid tag ID")
+	if !ok {
+		return
+	}
+
+	if err := h.tagService.DeleteTag(c.Request.Context(), userID, id); err != nil {
+		respondError(c, err, http.StatusNotFound)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "tag deleted"})
+}
+
+// GetTagReport возвращает суммы по тегам за период
+func (h *TagHandler) GetTagReport(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+	ledgerID := c.MustGet("ledgerID").(uint)
+
+	from, to := parseDateRange(c)
+
+	report, err := h.tagService.GetTagReport(c.Request.Context(), userID, ledgerID, from, to)
+	if err != nil {
+		respondError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
@@ -3,6 +3,7 @@ package handler
 import (
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -22,42 +23,76 @@ func NewTransactionHandler(ts *service.TransactionService) *TransactionHandler {
 // CreateTransaction создает транзакцию
 func (h *TransactionHandler) CreateTransaction(c *gin.Context) {
 	userID := c.MustGet("userID").(uint)
+	ledgerID := c.MustGet("ledgerID").(uint)
 
 	var req dto.CreateTransactionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(c, err, http.StatusBadRequest)
 		return
 	}
 
-	transaction, err := h.transactionService.CreateTransaction(userID, req)
+	transaction, err := h.transactionService.CreateTransaction(c.Request.Context(), userID, ledgerID, req)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(c, err, http.StatusBadRequest)
 		return
 	}
 
 	c.JSON(http.StatusCreated, transaction)
 }
 
+// UpdateTransaction изменяет транзакцию
+func (h *TransactionHandler) UpdateTransaction(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+	ledgerID := c.MustGet("ledgerID").(uint)
+
+	id, ok := parseIDParam(c, "id", "invalid transaction ID")
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateTransactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	transaction, err := h.transactionService.UpdateTransaction(c.Request.Context(), userID, ledgerID, id, req)
+	if err != nil {
+		respondError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	c.JSON(http.StatusOK, transaction)
+}
+
 // GetTransactions возвращает транзакции пользователя
 func (h *TransactionHandler) GetTransactions(c *gin.Context) {
 	userID := c.MustGet("userID").(uint)
+	ledgerID := c.MustGet("ledgerID").(uint)
 
 	// Парсим параметры дат
-	var from, to *time.Time
-	if fromStr := c.Query("from"); fromStr != "" {
-		if t, err := time.Parse("2006-01-02", fromStr); err == nil {
-			from = &t
-		}
+	from, to := parseDateRange(c)
+	filter := dto.TransactionFilter{
+		From:         from,
+		To:           to,
+		MatchAllTags: c.Query("tag_mode") == "all",
 	}
-	if toStr := c.Query("to"); toStr != "" {
-		if t, err := time.Parse("2006-01-02", toStr); err == nil {
-			to = &t
+
+	// Фильтр по тегам: ?tags=1,2,3&tag_mode=any|all
+	if tagsStr := c.Query("tags"); tagsStr != "" {
+		for _, part := range strings.Split(tagsStr, ",") {
+			id, err := strconv.ParseUint(strings.TrimSpace(part), 10, 32)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid tag ID"})
+				return
+			}
+			filter.TagIDs = append(filter.TagIDs, uint(id))
 		}
 	}
 
-	transactions, err := h.transactionService.GetUserTransactions(userID, from, to)
+	transactions, err := h.transactionService.GetLedgerTransactions(c.Request.Context(), userID, ledgerID, filter)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err, http.StatusInternalServerError)
 		return
 	}
 
@@ -67,22 +102,13 @@ func (h *TransactionHandler) GetTransactions(c *gin.Context) {
 // GetSummary возвращает финансовую сводку
 func (h *TransactionHandler) GetSummary(c *gin.Context) {
 	userID := c.MustGet("userID").(uint)
+	ledgerID := c.MustGet("ledgerID").(uint)
 
-	var from, to *time.Time
-	if fromStr := c.Query("from"); fromStr != "" {
-		if t, err := time.Parse("2006-01-02", fromStr); err == nil {
-			from = &t
-		}
-	}
-	if toStr := c.Query("to"); toStr != "" {
-		if t, err := time.Parse("2006-01-02", toStr); err == nil {
-			to = &t
-		}
-	}
+	from, to := parseDateRange(c)
 
-	summary, err := h.transactionService.GetFinancialSummary(userID, from, to)
+	summary, err := h.transactionService.GetFinancialSummary(c.Request.Context(), userID, ledgerID, from, to)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err, http.StatusInternalServerError)
 		return
 	}
 
@@ -92,6 +118,7 @@ func (h *TransactionHandler) GetSummary(c *gin.Context) {
 // DeleteTransaction удаляет транзакцию
 func (h *TransactionHandler) DeleteTransaction(c *gin.Context) {
 	userID := c.MustGet("userID").(uint)
+	ledgerID := c.MustGet("ledgerID").(uint)
 
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
@@ -100,11 +127,26 @@ func (h *TransactionHandler) DeleteTransaction(c *gin.Context) {
 		return
 	}
 
-	err = h.transactionService.DeleteTransaction(userID, uint(id))
+	err = h.transactionService.DeleteTransaction(c.Request.Context(), userID, ledgerID, uint(id))
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		respondError(c, err, http.StatusNotFound)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "transaction deleted"})
 }
+
+// parseDateRange разбирает параметры from и to в формате YYYY-MM-DD
+func parseDateRange(c *gin.Context) (from, to *time.Time) {
+	if fromStr := c.Query("from"); fromStr != "" {
+		if t, err := time.Parse("2006-01-02", fromStr); err == nil {
+			from = &t
+		}
+	}
+	if toStr := c.Query("to"); toStr != "" {
+		if t, err := time.Parse("2006-01-02", toStr); err == nil {
+			to = &t
+		}
+	}
+	return from, to
+}
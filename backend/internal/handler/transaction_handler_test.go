@@ -0,0 +1,172 @@
+package handler_test
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"finance-backend/internal/dto"
+	"finance-backend/internal/model"
+)
+
+func createTransaction(t *testing.T, s *server, token string, body gin.H) model.Transaction {
+	t.Helper()
+	w := s.do(http.MethodPost, "/api/transactions", token, body)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create transaction: %d %s", w.Code, w.Body)
+	}
+	var transaction model.Transaction
+	decode(t, w, &transaction)
+	return transaction
+}
+
+func listTransactions(t *testing.T, s *server, token string) []dto.TransactionResponse {
+	t.Helper()
+	w := s.do(http.MethodGet, "/api/transactions", token, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("list transactions: %d %s", w.Code, w.Body)
+	}
+	var list []dto.TransactionResponse
+	decode(t, w, &list)
+	return list
+}
+
+func TestCreateTransactionHandler(t *testing.T) {
+	s := newServer()
+	userID, token := s.register(t, "anna@example.com")
+
+	created := createTransaction(t, s, token, gin.H{
+		"amount": 350, "type": "expense", "description": "Обед", "date": "2026-03-10T13:00:00+03:00",
+	})
+	if created.ID == 0 || created.UserID != userID || created.LedgerID != userID || created.Amount != 350 {
+		t.Errorf("created transaction: %+v", created)
+	}
+
+	tests := []struct {
+		name string
+		body any
+		want string
+	}{
+		{"missing amount", gin.H{"type": "expense", "description": "x", "date": "2026-03-10T10:00:00Z"}, ""},
+		{"negative amount", gin.H{"amount": -1, "type": "expense", "description": "x", "date": "2026-03-10T10:00:00Z"}, ""},
+		{"unknown type", gin.H{"amount": 1, "type": "transfer", "description": "x", "date": "2026-03-10T10:00:00Z"}, ""},
+		{"invalid date", gin.H{"amount": 1, "type": "expense", "description": "x", "date": "10.03.2026"}, "invalid date format"},
+		{"foreign tag", gin.H{"amount": 1, "type": "expense", "description": "x", "date": "2026-03-10T10:00:00Z", "tag_ids": []uint{42}}, "tag not found"},
+		{"malformed JSON", `{"amount":`, ""},
+	}
+	for _, tt := range tests {
+		w := s.do(http.MethodPost, "/api/transactions", token, tt.body)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", tt.name, w.Code)
+			continue
+		}
+		if msg := errorMessage(t, w); tt.want != "" && msg != tt.want {
+			t.Errorf("%s: error %q, want %q", tt.name, msg, tt.want)
+		}
+	}
+
+	if w := s.do(http.MethodPost, "/api/transactions", "", gin.H{}); w.Code != http.StatusUnauthorized {
+		t.Errorf("create without token: %d, want 401", w.Code)
+	}
+	if list := listTransactions(t, s, token); len(list) != 1 {
+		t.Errorf("transactions = %+v, want only the created one", list)
+	}
+}
+
+func TestUpdateTransactionHandler(t *testing.T) {
+	s := newServer()
+	_, token := s.register(t, "anna@example.com")
+	created := createTransaction(t, s, token, gin.H{
+		"amount": 100, "type": "expense", "description": "Билеты", "date": "2026-03-10T10:00:00Z",
+	})
+	path := "/api/transactions/" + strconv.FormatUint(uint64(created.ID), 10)
+
+	w := s.do(http.MethodPut, path, token, gin.H{
+		"amount": 250, "type": "expense", "description": "Билеты на поезд", "date": "2026-03-11T10:00:00Z",
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("update: %d %s", w.Code, w.Body)
+	}
+	list := listTransactions(t, s, token)
+	if len(list) != 1 || list[0].Amount != 250 || list[0].Description != "Билеты на поезд" {
+		t.Errorf("transactions after update = %+v", list)
+	}
+
+	valid := gin.H{"amount": 1, "type": "expense", "description": "x", "date": "2026-03-11T10:00:00Z"}
+	tests := []struct {
+		name string
+		path string
+		body any
+		want int
+	}{
+		{"invalid ID", "/api/transactions/abc", valid, http.StatusBadRequest},
+		{"missing transaction", "/api/transactions/999", valid, http.StatusBadRequest},
+		{"invalid body", path, gin.H{"amount": 1}, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		if w := s.do(http.MethodPut, tt.path, token, tt.body); w.Code != tt.want {
+			t.Errorf("%s: status %d, want %d", tt.name, w.Code, tt.want)
+		}
+	}
+}
+
+func TestDeleteTransactionHandler(t *testing.T) {
+	s := newServer()
+	_, token := s.register(t, "anna@example.com")
+	created := createTransaction(t, s, token, gin.H{
+		"amount": 100, "type": "expense", "description": "Кафе", "date": "2026-03-10T10:00:00Z",
+	})
+	path := fmt.Sprintf("/api/transactions/%d", created.ID)
+
+	if w := s.do(http.MethodDelete, "/api/transactions/abc", token, nil); w.Code != http.StatusBadRequest {
+		t.Errorf("delete with invalid ID: %d, want 400", w.Code)
+	}
+	if w := s.do(http.MethodDelete, path, token, nil); w.Code != http.StatusOK {
+		t.Fatalf("delete: %d %s", w.Code, w.Body)
+	}
+	if w := s.do(http.MethodDelete, path, token, nil); w.Code != http.StatusNotFound {
+		t.Errorf("second delete: %d, want 404", w.Code)
+	}
+	if list := listTransactions(t, s, token); len(list) != 0 {
+		t.Errorf("transactions after delete = %+v", list)
+	}
+}
+
+func TestTransactionHandlerLedgerAccess(t *testing.T) {
+	s := newServer()
+	ownerID, ownerToken := s.register(t, "owner@example.com")
+	viewerID, viewerToken := s.register(t, "viewer@example.com")
+	_, strangerToken := s.register(t, "stranger@example.com")
+	s.ledgers.addMember(ownerID, viewerID, model.RoleViewer)
+	ledger := strconv.FormatUint(uint64(ownerID), 10)
+
+	created := createTransaction(t, s, ownerToken, gin.H{
+		"amount": 100, "type": "expense", "description": "Такси", "date": "2026-03-10T10:00:00Z",
+	})
+	path := fmt.Sprintf("/api/transactions/%d", created.ID)
+	body := gin.H{"amount": 1, "type": "expense", "description": "x", "date": "2026-03-10T10:00:00Z"}
+
+	if w := s.do(http.MethodPost, "/api/transactions", viewerToken, body, "X-Ledger-ID", ledger); w.Code != http.StatusForbidden {
+		t.Errorf("create by viewer: %d, want 403", w.Code)
+	}
+	if w := s.do(http.MethodPut, path, viewerToken, body, "X-Ledger-ID", ledger); w.Code != http.StatusForbidden {
+		t.Errorf("update by viewer: %d, want 403", w.Code)
+	}
+	if w := s.do(http.MethodDelete, path, viewerToken, nil, "X-Ledger-ID", ledger); w.Code != http.StatusForbidden {
+		t.Errorf("delete by viewer: %d, want 403", w.Code)
+	}
+	if w := s.do(http.MethodDelete, path, strangerToken, nil, "X-Ledger-ID", ledger); w.Code != http.StatusNotFound {
+		t.Errorf("delete by non-member: %d, want 404", w.Code)
+	}
+
+	// В своем личном бюджете постороннему чужая транзакция не видна
+	if w := s.do(http.MethodDelete, path, strangerToken, nil); w.Code != http.StatusNotFound {
+		t.Errorf("delete from own ledger: %d, want 404", w.Code)
+	}
+	if list := listTransactions(t, s, ownerToken); len(list) != 1 {
+		t.Errorf("owner transactions = %+v, want the transaction untouched", list)
+	}
+}
@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"finance-backend/internal/service"
+)
+
+type TrashHandler struct {
+	trashService *service.TrashService
+}
+
+func NewTrashHandler(ts *service.TrashService) *TrashHandler {
+	return &TrashHandler{trashService: ts}
+}
+
+// GetTrash возвращает содержимое корзины бюджета
+func (h *TrashHandler) GetTrash(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+	ledgerID := c.MustGet("ledgerID").(uint)
+
+	trash, err := h.trashService.GetTrash(c.Request.Context(), userID, ledgerID)
+	if err != nil {
+		respondError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, trash)
+}
+
+// RestoreTransaction возвращает транзакцию из корзины
+func (h *TrashHandler) RestoreTransaction(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+	ledgerID := c.MustGet("ledgerID").(uint)
+
+	id, ok := parseIDParam(c, "id", "invalid transaction ID")
+	if !ok {
+		return
+	}
+
+	transaction, err := h.trashService.RestoreTransaction(c.Request.Context(), userID, ledgerID, id)
+	if err != nil {
+		respondError(c, err, http.StatusNotFound)
+		return
+	}
+
+	c.JSON(http.StatusOK, transaction)
+}
+
+// RestoreCategory возвращает категорию из корзины
+func (h *TrashHandler) RestoreCategory(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+	ledgerID := c.MustGet("ledgerID").(uint)
+
+	id, ok := parseIDParam(c, "id", "invalid category ID")
+	if !ok {
+		return
+	}
+
+	category, err := h.trashService.RestoreCategory(c.Request.Context(), userID, ledgerID, id)
+	if err != nil {
+		respondError(c, err, http.StatusNotFound)
+		return
+	}
+
+	c.JSON(http.StatusOK, category)
+}
@@ -0,0 +1,74 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("finance-backend/internal/job")
+
+// Group запускает фоновые задачи и позволяет дождаться их завершения при
+// остановке, чтобы база не закрылась посреди запуска
+type Group struct {
+	wg sync.WaitGroup
+}
+
+// Every запускает fn сразу и затем с заданным интервалом, пока не отменен контекст
+func (g *Group) Every(ctx context.Context, name string, interval time.Duration, fn func(ctx context.Context) error) {
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			run(ctx, name, fn)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Wait ждет, пока задачи завершатся после отмены их контекста, но не дольше,
+// чем до отмены ctx
+func (g *Group) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		g.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// run выполняет один запуск задачи в отдельной трассировке, чтобы запросы
+// задачи к базе собирались под одним спаном
+func run(ctx context.Context, name string, fn func(ctx context.Context) error) {
+	ctx, span := tracer.Start(ctx, "job "+name, trace.WithNewRoot())
+	defer span.End()
+
+	err := fn(ctx)
+	// Запуск, прерванный остановкой, ошибкой не считается
+	if err != nil && !(errors.Is(err, context.Canceled) && ctx.Err() != nil) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		slog.ErrorContext(ctx, "job failed", "job", name, "error", err)
+	}
+}
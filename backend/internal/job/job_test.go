@@ -0,0 +1,140 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestGroupWaitsForRunningJob(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	started := make(chan struct{})
+	var finished atomic.Bool
+
+	var g Group
+	g.Every(ctx, "slow", time.Hour, func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		// Задача дописывает свое, прежде чем вернуться
+		time.Sleep(50 * time.Millisecond)
+		finished.Store(true)
+		return ctx.Err()
+	})
+
+	<-started
+	cancel()
+
+	waitCtx, cancelWait := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelWait()
+	if err := g.Wait(waitCtx); err != nil {
+		t.Fatalf("Wait: %v", err)
+	}
+	if !finished.Load() {
+		t.Error("Wait returned before the job finished")
+	}
+}
+
+func TestGroupWaitIsBounded(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	release := make(chan struct{})
+	defer close(release)
+	started := make(chan struct{})
+
+	var g Group
+	g.Every(ctx, "stuck", time.Hour, func(context.Context) error {
+		close(started)
+		<-release
+		return nil
+	})
+
+	<-started
+	cancel()
+
+	waitCtx, cancelWait := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancelWait()
+	if err := g.Wait(waitCtx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Wait = %v, want DeadlineExceeded", err)
+	}
+}
+
+func TestGroupRunsOnInterval(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var runs atomic.Int32
+
+	var g Group
+	g.Every(ctx, "tick", 10*time.Millisecond, func(context.Context) error {
+		runs.Add(1)
+		return nil
+	})
+
+	deadline := time.Now().Add(5 * time.Second)
+	for runs.Load() < 3 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+	if err := g.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if runs.Load() < 3 {
+		t.Errorf("job ran %d times, want at least 3", runs.Load())
+	}
+}
+
+func TestRunSpans(t *testing.T) {
+	// Трассировщик пакета привязывается к первому глобальному провайдеру
+	rec := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+
+	parentCtx, parent := otel.Tracer("test").Start(context.Background(), "startup")
+	defer parent.End()
+	canceled, cancel := context.WithCancel(parentCtx)
+	cancel()
+
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		err    error
+		status codes.Code
+	}{
+		{"success", parentCtx, nil, codes.Unset},
+		{"failure", parentCtx, errors.New("smtp is down"), codes.Error},
+		{"stopped", canceled, context.Canceled, codes.Unset},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec.Reset()
+			run(tt.ctx, "digest", func(ctx context.Context) error {
+				// Запросы задачи собираются под ее спаном
+				_, child := otel.Tracer("test").Start(ctx, "db.query")
+				child.End()
+				return tt.err
+			})
+
+			ended := rec.Ended()
+			if len(ended) != 2 {
+				t.Fatalf("got %d spans, want 2", len(ended))
+			}
+			child, span := ended[0], ended[1]
+			if span.Name() != "job digest" {
+				t.Errorf("span name = %q, want %q", span.Name(), "job digest")
+			}
+			if span.Parent().IsValid() || span.SpanContext().TraceID() == parent.SpanContext().TraceID() {
+				t.Error("job span continues the caller trace, want a new root")
+			}
+			if child.Parent().SpanID() != span.SpanContext().SpanID() {
+				t.Error("query span is not a child of the job span")
+			}
+			if span.Status().Code != tt.status {
+				t.Errorf("status = %v, want %v", span.Status().Code, tt.status)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package mailer
+
+import (
+	"fmt"
+	"log/slog"
+	"mime"
+	"net/smtp"
+	"strings"
+)
+
+// Mailer отправляет письма пользователям
+type Mailer interface {
+	Send(to, subject, body string) error
+}
+
+// LogMailer выводит письма в лог вместо отправки (для локальной разработки)
+type LogMailer struct{}
+
+func NewLogMailer() *LogMailer {
+	return &LogMailer{}
+}
+
+// Send печатает письмо в лог
+func (m *LogMailer) Send(to, subject, body string) error {
+	slog.Info("mail", "to", to, "subject", subject, "body", body)
+	return nil
+}
+
+// SMTPMailer отправляет письма через SMTP сервер
+type SMTPMailer struct {
+	host     string
+	port     string
+	username string
+	password string
+	from     string
+}
+
+func NewSMTPMailer(host, port, username, password, from string) *SMTPMailer {
+	return &SMTPMailer{
+		host:     host,
+		port:     port,
+		username: username,
+		password: password,
+		from:     from,
+	}
+}
+
+// Send отправляет текстовое письмо
+func (m *SMTPMailer) Send(to, subject, body string) error {
+	var auth smtp.Auth
+	if m.username != "" {
+		auth = smtp.PlainAuth("", m.username, m.password, m.host)
+	}
+
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", m.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", to)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	msg.WriteString("\r\n")
+	msg.WriteString(body)
+
+	return smtp.SendMail(m.host+":"+m.port, auth, m.from, []string{to}, []byte(msg.String()))
+}
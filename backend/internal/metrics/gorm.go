@@ -0,0 +1,51 @@
+package metrics
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const startKey = "finance:metrics:start"
+
+// GormPlugin измеряет длительность запросов GORM по операции и таблице
+type GormPlugin struct{}
+
+func (GormPlugin) Name() string {
+	return "finance:metrics"
+}
+
+func (GormPlugin) Initialize(db *gorm.DB) error {
+	before := func(tx *gorm.DB) {
+		tx.InstanceSet(startKey, time.Now())
+	}
+	after := func(operation string) func(*gorm.DB) {
+		return func(tx *gorm.DB) {
+			start, ok := tx.InstanceGet(startKey)
+			if !ok {
+				return
+			}
+			failed := tx.Error != nil && !errors.Is(tx.Error, gorm.ErrRecordNotFound)
+			dbQueryDuration.WithLabelValues(operation, tx.Statement.Table, strconv.FormatBool(failed)).
+				Observe(time.Since(start.(time.Time)).Seconds())
+		}
+	}
+
+	cb := db.Callback()
+	return errors.Join(
+		cb.Create().Before("*").Register("finance:metrics:before", before),
+		cb.Create().After("*").Register("finance:metrics:after", after("create")),
+		cb.Query().Before("*").Register("finance:metrics:before", before),
+		cb.Query().After("*").Register("finance:metrics:after", after("query")),
+		cb.Update().Before("*").Register("finance:metrics:before", before),
+		cb.Update().After("*").Register("finance:metrics:after", after("update")),
+		cb.Delete().Before("*").Register("finance:metrics:before", before),
+		cb.Delete().After("*").Register("finance:metrics:after", after("delete")),
+		cb.Row().Before("*").Register("finance:metrics:before", before),
+		cb.Row().After("*").Register("finance:metrics:after", after("row")),
+		cb.Raw().Before("*").Register("finance:metrics:before", before),
+		cb.Raw().After("*").Register("finance:metrics:after", after("raw")),
+	)
+}
@@ -0,0 +1,92 @@
+package metrics
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Registry собственный реестр, чтобы в /metrics попадали только наши метрики и метрики рантайма
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Длительность обработки HTTP-запросов.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	dbQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "db_query_duration_seconds",
+		Help:    "Длительность запросов GORM.",
+		Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation", "table", "error"})
+
+	// TransactionsCreated число созданных транзакций по типу (income, expense)
+	TransactionsCreated = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "finance_transactions_created_total",
+		Help: "Созданные транзакции.",
+	}, []string{"type"})
+
+	// ImportsProcessed число обработанных импортов выписок по результату
+	// (success, failed) и формату файла (csv, ofx и т. п.)
+	ImportsProcessed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "finance_imports_processed_total",
+		Help: "Обработанные импорты выписок.",
+	}, []string{"status", "format"})
+
+	// LoginFailures число неудачных попыток входа
+	LoginFailures = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "finance_login_failures_total",
+		Help: "Неудачные попытки входа.",
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpDuration,
+		dbQueryDuration,
+		TransactionsCreated,
+		ImportsProcessed,
+		LoginFailures,
+	)
+}
+
+// RegisterDB добавляет статистику пула соединений базы
+func RegisterDB(db *sql.DB, name string) error {
+	return Registry.Register(collectors.NewDBStatsCollector(db, name))
+}
+
+// ObserveHTTP учитывает обработанный HTTP-запрос. route — шаблон маршрута,
+// а не путь, иначе каждый ID порождал бы отдельный ряд
+func ObserveHTTP(method, route string, status int, duration time.Duration) {
+	if route == "" {
+		route = "unmatched"
+	}
+	httpDuration.WithLabelValues(method, route, strconv.Itoa(status)).Observe(duration.Seconds())
+}
+
+// Handler отдает метрики; если token задан, требует заголовок Authorization: Bearer <token>
+func Handler(token string) http.Handler {
+	h := promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+	if token == "" {
+		return h
+	}
+
+	expected := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expected) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
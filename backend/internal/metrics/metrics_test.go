@@ -0,0 +1,115 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// scrape возвращает ответ /metrics в текстовом формате
+func scrape(t *testing.T, h http.Handler, token string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+func TestHandlerToken(t *testing.T) {
+	h := Handler("s3cret")
+
+	for _, token := range []string{"", "wrong", "s3cret "} {
+		if w := scrape(t, h, token); w.Code != http.StatusUnauthorized {
+			t.Errorf("token %q: status %d, want 401", token, w.Code)
+		}
+	}
+	w := scrape(t, h, "s3cret")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "go_goroutines") {
+		t.Errorf("valid token: %d %.200s", w.Code, w.Body)
+	}
+}
+
+func TestObserveHTTP(t *testing.T) {
+	ObserveHTTP(http.MethodPost, "/api/things/:id", http.StatusCreated, 10*time.Millisecond)
+	ObserveHTTP(http.MethodGet, "", http.StatusNotFound, time.Millisecond)
+
+	body := scrape(t, Handler(""), "").Body.String()
+	for _, series := range []string{
+		`http_request_duration_seconds_count{method="POST",route="/api/things/:id",status="201"} 1`,
+		`http_request_duration_seconds_count{method="GET",route="unmatched",status="404"} 1`,
+	} {
+		if !strings.Contains(body, series) {
+			t.Errorf("no %s in /metrics", series)
+		}
+	}
+}
+
+func TestBusinessCounters(t *testing.T) {
+	TransactionsCreated.WithLabelValues("expense").Inc()
+	ImportsProcessed.WithLabelValues("success", "csv").Inc()
+	LoginFailures.Inc()
+
+	body := scrape(t, Handler(""), "").Body.String()
+	for _, series := range []string{
+		`finance_transactions_created_total{type="expense"}`,
+		`finance_imports_processed_total{format="csv",status="success"}`,
+		`finance_login_failures_total`,
+	} {
+		if !strings.Contains(body, series) {
+			t.Errorf("no %s in /metrics", series)
+		}
+	}
+}
+
+func TestGormPlugin(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(t.TempDir()+"/metrics.db"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Use(GormPlugin{}); err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sqlDB.Close()
+	if err := RegisterDB(sqlDB, "metrics_test"); err != nil {
+		t.Fatal(err)
+	}
+
+	type widget struct {
+		ID   uint
+		Name string
+	}
+	if err := db.Exec("CREATE TABLE widgets (id integer PRIMARY KEY, name text)").Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Create(&widget{Name: "a"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	var found widget
+	db.First(&found)
+	db.Table("missing_table").Find(&[]widget{})
+
+	body := scrape(t, Handler(""), "").Body.String()
+	for _, series := range []string{
+		`db_query_duration_seconds_count{error="false",operation="create",table="widgets"} 1`,
+		`db_query_duration_seconds_count{error="false",operation="query",table="widgets"} 1`,
+		`db_query_duration_seconds_count{error="true",operation="query",table="missing_table"} 1`,
+		`go_sql_open_connections{db_name="metrics_test"}`,
+	} {
+		if !strings.Contains(body, series) {
+			t.Errorf("no %s in /metrics", series)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"runtime/debug"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"finance-backend/internal/logging"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+// validRequestID ограничивает ID, пришедший от клиента или прокси, чтобы он
+// не засорял логи
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestIDMiddleware присваивает запросу ID: берет X-Request-ID от прокси или
+// генерирует новый, возвращает его в ответе и добавляет во все записи лога
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID.MatchString(id) {
+			id = newRequestID()
+		}
+
+		c.Set("requestID", id)
+		c.Header(RequestIDHeader, id)
+		c.Request = c.Request.WithContext(logging.With(c.Request.Context(), slog.String("request_id", id)))
+		c.Next()
+	}
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// LoggerMiddleware пишет по записи на запрос. Контекст читается после обработки,
+// поэтому запись содержит user_id и ledger_id, добавленные следующими middleware.
+// Успешные запросы к quietPaths (пробы оркестратора) не пишутся
+func LoggerMiddleware(quietPaths ...string) gin.HandlerFunc {
+	quiet := make(map[string]bool, len(quietPaths))
+	for _, path := range quietPaths {
+		quiet[path] = true
+	}
+
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		if quiet[c.Request.URL.Path] && status < http.StatusBadRequest {
+			return
+		}
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("path", c.Request.URL.Path),
+			slog.String("route", route),
+			slog.Int("status", status),
+			slog.Int("bytes", c.Writer.Size()),
+			slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.String("client_ip", c.ClientIP()),
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("error", c.Errors.String()))
+		}
+		slog.LogAttrs(c.Request.Context(), level, "request", attrs...)
+	}
+}
+
+// RecoveryMiddleware отвечает 500 на панику в обработчике и пишет ее в лог со стеком
+func RecoveryMiddleware() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(nil, func(c *gin.Context, err any) {
+		slog.ErrorContext(c.Request.Context(), "panic recovered",
+			slog.Any("panic", err),
+			slog.String("stack", string(debug.Stack())))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	})
+}
@@ -0,0 +1,238 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"finance-backend/internal/logging"
+)
+
+// captureLogs направляет slog в буфер на время теста и возвращает функцию,
+// разбирающую записанные JSON-записи
+func captureLogs(t *testing.T) func() []map[string]any {
+	t.Helper()
+	var buf bytes.Buffer
+	logger, err := logging.New(&buf, "debug", "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous := slog.Default()
+	slog.SetDefault(logger)
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	return func() []map[string]any {
+		var records []map[string]any
+		scanner := bufio.NewScanner(bytes.NewReader(buf.Bytes()))
+		for scanner.Scan() {
+			var record map[string]any
+			if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+				t.Fatalf("log line %q: %v", scanner.Text(), err)
+			}
+			records = append(records, record)
+		}
+		return records
+	}
+}
+
+// findRecord возвращает первую запись с сообщением msg
+func findRecord(t *testing.T, records []map[string]any, msg string) map[string]any {
+	t.Helper()
+	for _, record := range records {
+		if record["msg"] == msg {
+			return record
+		}
+	}
+	t.Fatalf("no %q record in %v", msg, records)
+	return nil
+}
+
+var generatedID = regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name     string
+		incoming string
+		keep     bool
+	}{
+		{"from proxy", "req-42.a:b_c", true},
+		{"missing", "", false},
+		{"invalid characters", "bad id\n", false},
+		{"too long", string(bytes.Repeat([]byte("a"), 129)), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs := captureLogs(t)
+			var fromContext any
+
+			r := gin.New()
+			r.Use(RequestIDMiddleware())
+			r.GET("/ping", func(c *gin.Context) {
+				fromContext, _ = c.Get("requestID")
+				slog.InfoContext(c.Request.Context(), "handled")
+				c.Status(http.StatusNoContent)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+			if tt.incoming != "" {
+				req.Header.Set(RequestIDHeader, tt.incoming)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			id := w.Header().Get(RequestIDHeader)
+			if tt.keep && id != tt.incoming {
+				t.Errorf("response ID = %q, want the incoming %q", id, tt.incoming)
+			}
+			if !tt.keep && !generatedID.MatchString(id) {
+				t.Errorf("response ID = %q, want a generated one", id)
+			}
+			if fromContext != id {
+				t.Errorf("gin context ID = %v, want %q", fromContext, id)
+			}
+			if got := findRecord(t, logs(), "handled")["request_id"]; got != id {
+				t.Errorf("log request_id = %v, want %q", got, id)
+			}
+		})
+	}
+}
+
+func TestRequestIDsAreUnique(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(RequestIDMiddleware())
+	r.GET("/ping", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	seen := make(map[string]bool)
+	for range 100 {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ping", nil))
+		id := w.Header().Get(RequestIDHeader)
+		if seen[id] {
+			t.Fatalf("request ID %q generated twice", id)
+		}
+		seen[id] = true
+	}
+}
+
+func TestLoggerMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(RequestIDMiddleware(), LoggerMiddleware("/healthz"))
+	// Поля, добавленные в контекст после LoggerMiddleware, тоже попадают в запись
+	r.Use(func(c *gin.Context) {
+		c.Request = c.Request.WithContext(logging.With(c.Request.Context(), slog.Any("user_id", uint(7))))
+		c.Next()
+	})
+	r.GET("/items/:id", func(c *gin.Context) {
+		switch c.Param("id") {
+		case "missing":
+			c.JSON(http.StatusNotFound, gin.H{"error": "item not found"})
+		case "broken":
+			c.Error(errors.New("database is down"))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		default:
+			c.JSON(http.StatusOK, gin.H{"id": c.Param("id")})
+		}
+	})
+	r.GET("/healthz", func(c *gin.Context) {
+		if c.Query("fail") != "" {
+			c.Status(http.StatusServiceUnavailable)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+
+	tests := []struct {
+		name   string
+		path   string
+		logged bool
+		level  string
+		route  string
+		status float64
+		err    string
+	}{
+		{"success", "/items/42", true, "INFO", "/items/:id", 200, ""},
+		{"client error", "/items/missing", true, "WARN", "/items/:id", 404, ""},
+		{"server error", "/items/broken", true, "ERROR", "/items/:id", 500, "database is down"},
+		{"unmatched route", "/nowhere", true, "WARN", "unmatched", 404, ""},
+		{"quiet probe", "/healthz", false, "", "", 0, ""},
+		{"failing probe", "/healthz?fail=1", true, "ERROR", "/healthz", 503, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs := captureLogs(t)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			records := logs()
+			if !tt.logged {
+				if len(records) != 0 {
+					t.Errorf("logged %v, want nothing", records)
+				}
+				return
+			}
+			if len(records) != 1 {
+				t.Fatalf("got %d records, want 1: %v", len(records), records)
+			}
+
+			record := findRecord(t, records, "request")
+			want := map[string]any{
+				"level":      tt.level,
+				"method":     http.MethodGet,
+				"route":      tt.route,
+				"status":     tt.status,
+				"request_id": w.Header().Get(RequestIDHeader),
+			}
+			for key, value := range want {
+				if record[key] != value {
+					t.Errorf("%s = %v, want %v", key, record[key], value)
+				}
+			}
+			if tt.route != "unmatched" && record["user_id"] != float64(7) {
+				t.Errorf("user_id = %v, want 7", record["user_id"])
+			}
+			if _, ok := record["duration_ms"].(float64); !ok {
+				t.Errorf("duration_ms missing: %v", record)
+			}
+			if msg, _ := record["error"].(string); tt.err != "" && !strings.Contains(msg, tt.err) {
+				t.Errorf("error = %q, want it to contain %q", msg, tt.err)
+			}
+		})
+	}
+}
+
+func TestRecoveryMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logs := captureLogs(t)
+
+	r := gin.New()
+	r.Use(RequestIDMiddleware(), RecoveryMiddleware())
+	r.GET("/panic", func(c *gin.Context) { panic("boom") })
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/panic", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want 500", w.Code)
+	}
+
+	record := findRecord(t, logs(), "panic recovered")
+	if record["panic"] != "boom" || record["stack"] == "" {
+		t.Errorf("panic record = %v", record)
+	}
+	if record["request_id"] != w.Header().Get(RequestIDHeader) {
+		t.Errorf("panic record request_id = %v", record["request_id"])
+	}
+}
@@ -0,0 +1,18 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"finance-backend/internal/metrics"
+)
+
+// MetricsMiddleware учитывает длительность и статус запроса в метриках
+func MetricsMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+		metrics.ObserveHTTP(c.Request.Method, c.FullPath(), c.Writer.Status(), time.Since(start))
+	}
+}
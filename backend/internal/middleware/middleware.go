@@ -1,8 +1,13 @@
 package middleware
 
 import (
+	"errors"
+	"finance-backend/internal/logging"
+	"finance-backend/internal/repository"
 	"finance-backend/internal/service"
+	"log/slog"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -34,6 +39,91 @@ func AuthMiddleware(authService *service.AuthService) gin.HandlerFunc {
 		}
 
 		c.Set("userID", userID)
+		c.Request = c.Request.WithContext(logging.With(c.Request.Context(), slog.Any("user_id", userID)))
+		c.Next()
+	}
+}
+
+// EmailVerifiedMiddleware запрещает изменяющие запросы пользователям с неподтвержденным email
+func EmailVerifiedMiddleware(userService *service.UserService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+
+		userID := c.MustGet("userID").(uint)
+		verified, err := userService.IsEmailVerified(c.Request.Context(), userID)
+		if errors.Is(err, repository.ErrDatabase) {
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			c.Abort()
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "user not found"})
+			c.Abort()
+			return
+		}
+
+		if !verified {
+			c.JSON(http.StatusForbidden, gin.H{"error": "email is not verified"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// ClientIPMiddleware сохраняет IP клиента в контексте запроса для журнала изменений
+func ClientIPMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = c.Request.WithContext(service.WithClientIP(c.Request.Context(), c.ClientIP()))
+		c.Next()
+	}
+}
+
+// LedgerMiddleware определяет бюджет запроса по заголовку X-Ledger-ID или параметру
+// ledger_id; без них используется личный бюджет пользователя
+func LedgerMiddleware(ledgerService *service.LedgerService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.MustGet("userID").(uint)
+
+		raw := c.GetHeader("X-Ledger-ID")
+		if raw == "" {
+			raw = c.Query("ledger_id")
+		}
+
+		var ledgerID *uint
+		if raw != "" {
+			id, err := strconv.ParseUint(raw, 10, 32)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ledger ID"})
+				c.Abort()
+				return
+			}
+			v := uint(id)
+			ledgerID = &v
+		}
+
+		member, err := ledgerService.ResolveLedger(c.Request.Context(), userID, ledgerID)
+		if errors.Is(err, repository.ErrDatabase) {
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			c.Abort()
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "ledger not found"})
+			c.Abort()
+			return
+		}
+
+		c.Set("ledgerID", member.LedgerID)
+		c.Set("ledgerRole", member.Role)
+		c.Request = c.Request.WithContext(logging.With(c.Request.Context(), slog.Any("ledger_id", member.LedgerID)))
 		c.Next()
 	}
 }
@@ -0,0 +1,17 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+)
+
+// TracingMiddleware открывает серверный спан на запрос и продолжает трассировку
+// из traceparent. Запросы к skipPaths (пробы, метрики) не трассируются
+func TracingMiddleware(service string, skipPaths ...string) gin.HandlerFunc {
+	return otelgin.Middleware(service, otelgin.WithFilter(func(req *http.Request) bool {
+		return !slices.Contains(skipPaths, req.URL.Path)
+	}))
+}
@@ -0,0 +1,118 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	spans     = tracetest.NewSpanRecorder()
+	spansOnce sync.Once
+)
+
+// recordSpans направляет спаны в память. Глобальный провайдер ставится один
+// раз: трассировщики, полученные до этого, привязываются к первому провайдеру
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	spansOnce.Do(func() {
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)))
+		otel.SetTextMapPropagator(propagation.TraceContext{})
+	})
+	spans.Reset()
+	return spans
+}
+
+func attrValue(span sdktrace.ReadOnlySpan, key attribute.Key) attribute.Value {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestTracingMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rec := recordSpans(t)
+
+	r := gin.New()
+	r.Use(RequestIDMiddleware(), TracingMiddleware("finance-test", "/healthz"), LoggerMiddleware())
+	r.GET("/items/:id", func(c *gin.Context) {
+		if c.Param("id") == "broken" {
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+	r.GET("/healthz", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	const (
+		traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+		spanID  = "00f067aa0ba902b7"
+	)
+	tests := []struct {
+		name        string
+		path        string
+		traceparent string
+		traced      bool
+		status      codes.Code
+	}{
+		{"route", "/items/42", "", true, codes.Unset},
+		{"server error", "/items/broken", "", true, codes.Error},
+		{"continues caller trace", "/items/1", "00-" + traceID + "-" + spanID + "-01", true, codes.Unset},
+		{"skipped probe", "/healthz", "", false, codes.Unset},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec.Reset()
+			logs := captureLogs(t)
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.traceparent != "" {
+				req.Header.Set("traceparent", tt.traceparent)
+			}
+			r.ServeHTTP(httptest.NewRecorder(), req)
+
+			ended := rec.Ended()
+			if !tt.traced {
+				if len(ended) != 0 {
+					t.Errorf("got %d spans, want none", len(ended))
+				}
+				return
+			}
+			if len(ended) != 1 {
+				t.Fatalf("got %d spans, want 1", len(ended))
+			}
+			span := ended[0]
+			if span.Name() != "GET /items/:id" || span.SpanKind() != trace.SpanKindServer {
+				t.Errorf("span = %q (%v), want server span GET /items/:id", span.Name(), span.SpanKind())
+			}
+			if got := attrValue(span, "http.route").AsString(); got != "/items/:id" {
+				t.Errorf("http.route = %q", got)
+			}
+			if span.Status().Code != tt.status {
+				t.Errorf("status = %v, want %v", span.Status().Code, tt.status)
+			}
+			if tt.traceparent != "" {
+				if span.SpanContext().TraceID().String() != traceID || span.Parent().SpanID().String() != spanID {
+					t.Errorf("span %v is not a child of the incoming traceparent", span.SpanContext())
+				}
+			}
+			// Запись лога о запросе связана со спаном
+			record := findRecord(t, logs(), "request")
+			if record["trace_id"] != span.SpanContext().TraceID().String() {
+				t.Errorf("log trace_id = %v, want %s", record["trace_id"], span.SpanContext().TraceID())
+			}
+		})
+	}
+}
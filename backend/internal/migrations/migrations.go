@@ -0,0 +1,320 @@
+package migrations
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Скрипты лежат в sql/<диалект>: схема Postgres и SQLite ведется параллельно
+//
+//go:embed sql
+var files embed.FS
+
+// lockKey ключ advisory lock: миграции выполняет только одна реплика за раз
+const lockKey int64 = 0x66696e616e6365 // "finance"
+
+// fileName имя файла миграции: 0001_name.up.sql или 0001_name.down.sql
+var fileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migration пронумерованная миграция со скриптами применения и отката
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status состояние миграции в базе
+type Status struct {
+	Version   int
+	Name      string
+	AppliedAt *time.Time // nil — не применена
+}
+
+// appliedMigration запись в таблице schema_migrations
+type appliedMigration struct {
+	Version   int       `gorm:"primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"not null"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+func (appliedMigration) TableName() string {
+	return "schema_migrations"
+}
+
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+func New(db *gorm.DB) (*Migrator, error) {
+	dir := path.Join("sql", db.Dialector.Name())
+	if _, err := fs.Stat(files, dir); err != nil {
+		return nil, fmt.Errorf("no migrations for database %q", db.Dialector.Name())
+	}
+
+	migrations, err := load(files, dir)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// load читает миграции и проверяет, что у каждой есть оба скрипта
+func load(fsys fs.FS, dir string) ([]Migration, error) {
+	paths, err := fs.Glob(fsys, path.Join(dir, "*.sql"))
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, p := range paths {
+		m := fileName.FindStringSubmatch(path.Base(p))
+		if m == nil {
+			return nil, fmt.Errorf("invalid migration file name: %s", p)
+		}
+		version, _ := strconv.Atoi(m[1])
+		if version == 0 {
+			return nil, fmt.Errorf("migration version must be positive: %s", p)
+		}
+
+		data, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: m[2]}
+			byVersion[version] = migration
+		} else if migration.Name != m[2] {
+			return nil, fmt.Errorf("migration %d has conflicting names: %s and %s", version, migration.Name, m[2])
+		}
+
+		if m[3] == "up" {
+			migration.Up = string(data)
+		} else {
+			migration.Down = string(data)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %04d_%s must have both up and down scripts", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// Latest номер последней известной миграции
+func (m *Migrator) Latest() int {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Up применяет все неприменённые миграции
+func (m *Migrator) Up() error {
+	return m.To(m.Latest())
+}
+
+// Down откатывает последнюю применённую миграцию
+func (m *Migrator) Down() error {
+	return m.withLock(func(conn *gorm.DB) error {
+		applied, err := m.applied(conn)
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			return nil
+		}
+
+		versions := make([]int, 0, len(applied))
+		for v := range applied {
+			versions = append(versions, v)
+		}
+		sort.Ints(versions)
+
+		target := 0
+		if len(versions) > 1 {
+			target = versions[len(versions)-2]
+		}
+		return m.migrate(conn, applied, target)
+	})
+}
+
+// To применяет или откатывает миграции до указанной версии, 0 — откатить все
+func (m *Migrator) To(version int) error {
+	if version != 0 && m.find(version) == nil {
+		return fmt.Errorf("unknown migration version: %d", version)
+	}
+
+	return m.withLock(func(conn *gorm.DB) error {
+		applied, err := m.applied(conn)
+		if err != nil {
+			return err
+		}
+		return m.migrate(conn, applied, version)
+	})
+}
+
+// Status возвращает известные миграции с отметкой о применении, а также
+// применённые миграции, которых нет в этой сборке
+func (m *Migrator) Status() ([]Status, error) {
+	applied := map[int]appliedMigration{}
+	if m.db.Migrator().HasTable(&appliedMigration{}) {
+		var err error
+		if applied, err = m.applied(m.db); err != nil {
+			return nil, err
+		}
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := Status{Version: migration.Version, Name: migration.Name}
+		if a, ok := applied[migration.Version]; ok {
+			status.AppliedAt = &a.AppliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	for v, a := range applied {
+		if m.find(v) == nil {
+			statuses = append(statuses, Status{Version: v, Name: a.Name, AppliedAt: &a.AppliedAt})
+		}
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Version < statuses[j].Version
+	})
+	return statuses, nil
+}
+
+// Pending возвращает число известных этой сборке, но не применённых миграций
+func (m *Migrator) Pending() (int, error) {
+	statuses, err := m.Status()
+	if err != nil {
+		return 0, err
+	}
+
+	pending := 0
+	for _, status := range statuses {
+		if status.AppliedAt == nil {
+			pending++
+		}
+	}
+	return pending, nil
+}
+
+// migrate приводит базу к целевой версии: сначала откатывает лишние миграции
+// в обратном порядке, затем применяет недостающие. Каждая миграция выполняется
+// в своей транзакции вместе с записью в schema_migrations
+func (m *Migrator) migrate(conn *gorm.DB, applied map[int]appliedMigration, target int) error {
+	for v := range applied {
+		if m.find(v) == nil {
+			return fmt.Errorf("database has migration %d unknown to this build", v)
+		}
+	}
+
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		migration := m.migrations[i]
+		if _, ok := applied[migration.Version]; !ok || migration.Version <= target {
+			continue
+		}
+
+		err := conn.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec(migration.Down).Error; err != nil {
+				return err
+			}
+			return tx.Delete(&appliedMigration{}, migration.Version).Error
+		})
+		if err != nil {
+			return fmt.Errorf("rollback migration %04d_%s: %w", migration.Version, migration.Name, err)
+		}
+		slog.Info("migration rolled back", "version", migration.Version, "name", migration.Name)
+	}
+
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; ok || migration.Version > target {
+			continue
+		}
+
+		err := conn.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec(migration.Up).Error; err != nil {
+				return err
+			}
+			return tx.Create(&appliedMigration{
+				Version:   migration.Version,
+				Name:      migration.Name,
+				AppliedAt: time.Now(),
+			}).Error
+		})
+		if err != nil {
+			return fmt.Errorf("apply migration %04d_%s: %w", migration.Version, migration.Name, err)
+		}
+		slog.Info("migration applied", "version", migration.Version, "name", migration.Name)
+	}
+	return nil
+}
+
+// withLock выполняет fn на одном соединении. В Postgres соединение берет
+// advisory lock, чтобы реплики, запущенные одновременно, не применяли миграции
+// параллельно. SQLite рассчитан на один процесс, а запись в нем и так
+// сериализуется блокировкой файла базы
+func (m *Migrator) withLock(fn func(conn *gorm.DB) error) error {
+	return m.db.Connection(func(conn *gorm.DB) (err error) {
+		if conn.Dialector.Name() == "postgres" {
+			if err := conn.Exec("SELECT pg_advisory_lock(?)", lockKey).Error; err != nil {
+				return fmt.Errorf("acquire migration lock: %w", err)
+			}
+			defer func() {
+				if unlockErr := conn.Exec("SELECT pg_advisory_unlock(?)", lockKey).Error; unlockErr != nil {
+					err = errors.Join(err, fmt.Errorf("release migration lock: %w", unlockErr))
+				}
+			}()
+		}
+
+		if !conn.Migrator().HasTable(&appliedMigration{}) {
+			if err := conn.Migrator().CreateTable(&appliedMigration{}); err != nil {
+				return err
+			}
+		}
+		return fn(conn)
+	})
+}
+
+func (m *Migrator) applied(db *gorm.DB) (map[int]appliedMigration, error) {
+	var rows []appliedMigration
+	if err := db.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int]appliedMigration, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+	return applied, nil
+}
+
+func (m *Migrator) find(version int) *Migration {
+	for i := range m.migrations {
+		if m.migrations[i].Version == version {
+			return &m.migrations[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+DROP TABLE IF EXISTS
+	alerts,
+	recurring_items,
+	goals,
+	audit_logs,
+	duplicate_dismissals,
+	rule_tags,
+	rules,
+	attachments,
+	settlements,
+	transaction_splits,
+	transaction_tags,
+	transactions,
+	payee_aliases,
+	payees,
+	tags,
+	categories,
+	ledger_invitations,
+	ledger_members,
+	ledgers,
+	users;
+
+DROP FUNCTION IF EXISTS transactions_search_vector();
+DROP FUNCTION IF EXISTS categories_search_vector();
+DROP FUNCTION IF EXISTS payees_search_vector();
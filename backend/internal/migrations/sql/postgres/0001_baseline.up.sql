@@ -0,0 +1,371 @@
+-- Схема на момент перехода с AutoMigrate на миграции. IF NOT EXISTS позволяет
+-- применить миграцию к базе, созданной AutoMigrate: недостающие таблицы и
+-- индексы создаются, существующие не меняются. Столбцы, которые AutoMigrate
+-- добавлял в users, categories и transactions после их создания, добавляются
+-- отдельно: в базе, которую последний раз обновляла ранняя версия, их нет
+
+CREATE TABLE IF NOT EXISTS users (
+	id bigserial PRIMARY KEY,
+	email text NOT NULL,
+	password text NOT NULL,
+	first_name text NOT NULL,
+	last_name text NOT NULL,
+	email_verified boolean NOT NULL DEFAULT false,
+	email_verified_at timestamptz,
+	verification_sent_at timestamptz,
+	deletion_scheduled_at timestamptz,
+	created_at timestamptz,
+	updated_at timestamptz,
+	deleted_at timestamptz
+);
+ALTER TABLE users
+	ADD COLUMN IF NOT EXISTS email_verified boolean NOT NULL DEFAULT false,
+	ADD COLUMN IF NOT EXISTS email_verified_at timestamptz,
+	ADD COLUMN IF NOT EXISTS verification_sent_at timestamptz,
+	ADD COLUMN IF NOT EXISTS deletion_scheduled_at timestamptz;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users (email);
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_users_deletion_scheduled_at ON users (deletion_scheduled_at);
+
+CREATE TABLE IF NOT EXISTS ledgers (
+	id bigserial PRIMARY KEY,
+	name text NOT NULL,
+	owner_id bigint NOT NULL,
+	personal boolean NOT NULL DEFAULT false,
+	created_at timestamptz,
+	updated_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_ledgers_owner_id ON ledgers (owner_id);
+
+CREATE TABLE IF NOT EXISTS ledger_members (
+	id bigserial PRIMARY KEY,
+	ledger_id bigint NOT NULL,
+	user_id bigint NOT NULL,
+	role varchar(10) NOT NULL,
+	created_at timestamptz,
+	CONSTRAINT fk_ledgers_members FOREIGN KEY (ledger_id) REFERENCES ledgers (id),
+	CONSTRAINT fk_ledger_members_user FOREIGN KEY (user_id) REFERENCES users (id),
+	CONSTRAINT chk_ledger_members_role CHECK (role IN ('owner', 'editor', 'viewer'))
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ledger_member ON ledger_members (ledger_id, user_id);
+CREATE INDEX IF NOT EXISTS idx_ledger_members_user_id ON ledger_members (user_id);
+
+CREATE TABLE IF NOT EXISTS ledger_invitations (
+	id bigserial PRIMARY KEY,
+	ledger_id bigint NOT NULL,
+	email text NOT NULL,
+	role varchar(10) NOT NULL,
+	invited_by_id bigint NOT NULL,
+	status varchar(10) NOT NULL DEFAULT 'pending',
+	expires_at timestamptz NOT NULL,
+	created_at timestamptz,
+	updated_at timestamptz,
+	CONSTRAINT fk_ledger_invitations_ledger FOREIGN KEY (ledger_id) REFERENCES ledgers (id),
+	CONSTRAINT chk_ledger_invitations_role CHECK (role IN ('editor', 'viewer')),
+	CONSTRAINT chk_ledger_invitations_status CHECK (status IN ('pending', 'accepted', 'declined'))
+);
+CREATE INDEX IF NOT EXISTS idx_ledger_invitations_ledger_id ON ledger_invitations (ledger_id);
+CREATE INDEX IF NOT EXISTS idx_ledger_invitations_email ON ledger_invitations (email);
+
+CREATE TABLE IF NOT EXISTS categories (
+	id bigserial PRIMARY KEY,
+	user_id bigint NOT NULL,
+	ledger_id bigint,
+	name text NOT NULL,
+	type varchar(10) NOT NULL,
+	color text DEFAULT '#6B7280',
+	created_at timestamptz,
+	updated_at timestamptz,
+	deleted_at timestamptz,
+	CONSTRAINT fk_users_categories FOREIGN KEY (user_id) REFERENCES users (id),
+	CONSTRAINT chk_categories_type CHECK (type IN ('income', 'expense'))
+);
+ALTER TABLE categories
+	ADD COLUMN IF NOT EXISTS ledger_id bigint,
+	ADD COLUMN IF NOT EXISTS deleted_at timestamptz;
+CREATE INDEX IF NOT EXISTS idx_categories_user_id ON categories (user_id);
+CREATE INDEX IF NOT EXISTS idx_categories_ledger_id ON categories (ledger_id);
+CREATE INDEX IF NOT EXISTS idx_categories_deleted_at ON categories (deleted_at);
+
+CREATE TABLE IF NOT EXISTS tags (
+	id bigserial PRIMARY KEY,
+	user_id bigint NOT NULL,
+	name text NOT NULL,
+	color text DEFAULT '#6B7280',
+	created_at timestamptz,
+	updated_at timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_tag_name ON tags (user_id, name);
+
+CREATE TABLE IF NOT EXISTS payees (
+	id bigserial PRIMARY KEY,
+	user_id bigint NOT NULL,
+	ledger_id bigint NOT NULL,
+	name text NOT NULL,
+	default_category_id bigint,
+	created_at timestamptz,
+	updated_at timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ledger_payee_name ON payees (ledger_id, name);
+CREATE INDEX IF NOT EXISTS idx_payees_user_id ON payees (user_id);
+
+CREATE TABLE IF NOT EXISTS payee_aliases (
+	id bigserial PRIMARY KEY,
+	payee_id bigint NOT NULL,
+	pattern text NOT NULL,
+	is_regex boolean NOT NULL DEFAULT false,
+	CONSTRAINT fk_payees_aliases FOREIGN KEY (payee_id) REFERENCES payees (id)
+);
+CREATE INDEX IF NOT EXISTS idx_payee_aliases_payee_id ON payee_aliases (payee_id);
+
+CREATE TABLE IF NOT EXISTS transactions (
+	id bigserial PRIMARY KEY,
+	user_id bigint NOT NULL,
+	ledger_id bigint,
+	category_id bigint,
+	payee_id bigint,
+	amount decimal NOT NULL,
+	type varchar(10) NOT NULL,
+	description text NOT NULL,
+	notes text NOT NULL DEFAULT '',
+	date timestamptz NOT NULL,
+	paid_by_id bigint,
+	created_at timestamptz,
+	updated_at timestamptz,
+	deleted_at timestamptz,
+	CONSTRAINT fk_users_transactions FOREIGN KEY (user_id) REFERENCES users (id),
+	CONSTRAINT fk_categories_transactions FOREIGN KEY (category_id) REFERENCES categories (id),
+	CONSTRAINT fk_transactions_payee FOREIGN KEY (payee_id) REFERENCES payees (id),
+	CONSTRAINT chk_transactions_type CHECK (type IN ('income', 'expense'))
+);
+ALTER TABLE transactions
+	ADD COLUMN IF NOT EXISTS ledger_id bigint,
+	ADD COLUMN IF NOT EXISTS payee_id bigint CONSTRAINT fk_transactions_payee REFERENCES payees (id),
+	ADD COLUMN IF NOT EXISTS notes text NOT NULL DEFAULT '',
+	ADD COLUMN IF NOT EXISTS paid_by_id bigint,
+	ADD COLUMN IF NOT EXISTS deleted_at timestamptz;
+CREATE INDEX IF NOT EXISTS idx_transactions_user_id ON transactions (user_id);
+CREATE INDEX IF NOT EXISTS idx_transactions_ledger_id ON transactions (ledger_id);
+CREATE INDEX IF NOT EXISTS idx_transactions_category_id ON transactions (category_id);
+CREATE INDEX IF NOT EXISTS idx_transactions_payee_id ON transactions (payee_id);
+CREATE INDEX IF NOT EXISTS idx_transactions_date ON transactions (date);
+CREATE INDEX IF NOT EXISTS idx_transactions_deleted_at ON transactions (deleted_at);
+
+CREATE TABLE IF NOT EXISTS transaction_tags (
+	transaction_id bigint,
+	tag_id bigint,
+	PRIMARY KEY (transaction_id, tag_id),
+	CONSTRAINT fk_transaction_tags_transaction FOREIGN KEY (transaction_id) REFERENCES transactions (id),
+	CONSTRAINT fk_transaction_tags_tag FOREIGN KEY (tag_id) REFERENCES tags (id)
+);
+
+CREATE TABLE IF NOT EXISTS transaction_splits (
+	id bigserial PRIMARY KEY,
+	transaction_id bigint NOT NULL,
+	user_id bigint NOT NULL,
+	amount decimal NOT NULL,
+	created_at timestamptz,
+	CONSTRAINT fk_transactions_splits FOREIGN KEY (transaction_id) REFERENCES transactions (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_split_member ON transaction_splits (transaction_id, user_id);
+CREATE INDEX IF NOT EXISTS idx_transaction_splits_user_id ON transaction_splits (user_id);
+
+CREATE TABLE IF NOT EXISTS settlements (
+	id bigserial PRIMARY KEY,
+	ledger_id bigint NOT NULL,
+	from_user_id bigint NOT NULL,
+	to_user_id bigint NOT NULL,
+	amount decimal NOT NULL,
+	date timestamptz NOT NULL,
+	note text,
+	created_by_id bigint NOT NULL,
+	created_at timestamptz,
+	CONSTRAINT chk_settlements_amount CHECK (amount > 0)
+);
+CREATE INDEX IF NOT EXISTS idx_settlements_ledger_id ON settlements (ledger_id);
+
+CREATE TABLE IF NOT EXISTS attachments (
+	id bigserial PRIMARY KEY,
+	transaction_id bigint NOT NULL,
+	user_id bigint NOT NULL,
+	file_name text NOT NULL,
+	content_type text NOT NULL,
+	size bigint NOT NULL,
+	storage_key text NOT NULL,
+	thumbnail_key text,
+	created_at timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_attachments_storage_key ON attachments (storage_key);
+CREATE INDEX IF NOT EXISTS idx_attachments_transaction_id ON attachments (transaction_id);
+CREATE INDEX IF NOT EXISTS idx_attachments_user_id ON attachments (user_id);
+
+CREATE TABLE IF NOT EXISTS rules (
+	id bigserial PRIMARY KEY,
+	user_id bigint NOT NULL,
+	name text NOT NULL,
+	priority bigint NOT NULL DEFAULT 0,
+	enabled boolean NOT NULL,
+	ledger_id bigint,
+	description_contains text,
+	description_regex text,
+	min_amount decimal,
+	max_amount decimal,
+	type varchar(10),
+	set_category_id bigint,
+	set_description text,
+	stop_processing boolean,
+	created_at timestamptz,
+	updated_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_rules_user_id ON rules (user_id);
+CREATE INDEX IF NOT EXISTS idx_rules_ledger_id ON rules (ledger_id);
+
+CREATE TABLE IF NOT EXISTS rule_tags (
+	rule_id bigint,
+	tag_id bigint,
+	PRIMARY KEY (rule_id, tag_id),
+	CONSTRAINT fk_rule_tags_rule FOREIGN KEY (rule_id) REFERENCES rules (id),
+	CONSTRAINT fk_rule_tags_tag FOREIGN KEY (tag_id) REFERENCES tags (id)
+);
+
+CREATE TABLE IF NOT EXISTS duplicate_dismissals (
+	id bigserial PRIMARY KEY,
+	ledger_id bigint NOT NULL,
+	transaction_id bigint NOT NULL,
+	other_id bigint NOT NULL,
+	user_id bigint NOT NULL,
+	created_at timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_duplicate_pair ON duplicate_dismissals (transaction_id, other_id);
+CREATE INDEX IF NOT EXISTS idx_duplicate_dismissals_ledger_id ON duplicate_dismissals (ledger_id);
+
+CREATE TABLE IF NOT EXISTS audit_logs (
+	id bigserial PRIMARY KEY,
+	user_id bigint NOT NULL,
+	ledger_id bigint NOT NULL,
+	entity_type varchar(20) NOT NULL,
+	entity_id bigint NOT NULL,
+	action varchar(10) NOT NULL,
+	before text NOT NULL DEFAULT '',
+	after text NOT NULL DEFAULT '',
+	changes text NOT NULL DEFAULT '',
+	ip varchar(45) NOT NULL DEFAULT '',
+	created_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_audit_entity ON audit_logs (ledger_id, entity_type, entity_id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_user_id ON audit_logs (user_id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_created_at ON audit_logs (created_at);
+
+CREATE TABLE IF NOT EXISTS goals (
+	id bigserial PRIMARY KEY,
+	user_id bigint NOT NULL,
+	ledger_id bigint NOT NULL,
+	name text NOT NULL,
+	target_amount decimal NOT NULL,
+	deadline timestamptz,
+	category_id bigint,
+	start_date timestamptz NOT NULL,
+	created_at timestamptz,
+	updated_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_goals_user_id ON goals (user_id);
+CREATE INDEX IF NOT EXISTS idx_goals_ledger_id ON goals (ledger_id);
+CREATE INDEX IF NOT EXISTS idx_goals_category_id ON goals (category_id);
+
+CREATE TABLE IF NOT EXISTS recurring_items (
+	id bigserial PRIMARY KEY,
+	user_id bigint NOT NULL,
+	ledger_id bigint NOT NULL,
+	name text NOT NULL,
+	amount decimal NOT NULL,
+	type varchar(10) NOT NULL,
+	period varchar(10) NOT NULL,
+	next_date timestamptz NOT NULL,
+	category_id bigint,
+	payee_id bigint,
+	match_key text NOT NULL DEFAULT '',
+	created_at timestamptz,
+	updated_at timestamptz,
+	CONSTRAINT chk_recurring_items_type CHECK (type IN ('income', 'expense'))
+);
+CREATE INDEX IF NOT EXISTS idx_recurring_items_user_id ON recurring_items (user_id);
+CREATE INDEX IF NOT EXISTS idx_recurring_items_ledger_id ON recurring_items (ledger_id);
+CREATE INDEX IF NOT EXISTS idx_recurring_items_category_id ON recurring_items (category_id);
+CREATE INDEX IF NOT EXISTS idx_recurring_items_payee_id ON recurring_items (payee_id);
+
+CREATE TABLE IF NOT EXISTS alerts (
+	id bigserial PRIMARY KEY,
+	ledger_id bigint NOT NULL,
+	key text NOT NULL,
+	kind varchar(20) NOT NULL,
+	transaction_id bigint,
+	category_id bigint,
+	payee_id bigint,
+	amount decimal,
+	expected decimal,
+	message text NOT NULL,
+	acknowledged_at timestamptz,
+	acknowledged_by_id bigint,
+	created_at timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_alert_key ON alerts (ledger_id, key);
+CREATE INDEX IF NOT EXISTS idx_alerts_transaction_id ON alerts (transaction_id);
+CREATE INDEX IF NOT EXISTS idx_alerts_created_at ON alerts (created_at);
+
+-- Поисковый вектор транзакций: описание, заметки, получатель и название
+-- категории в русской и английской конфигурациях с разными весами. Получатель
+-- и категория хранятся в других таблицах, поэтому вектор считается триггером,
+-- а не генерируемым столбцом
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS search_vector tsvector;
+
+CREATE OR REPLACE FUNCTION transactions_search_vector() RETURNS trigger AS $$
+DECLARE
+	category_name text;
+	payee_name text;
+BEGIN
+	SELECT name INTO category_name FROM categories WHERE id = NEW.category_id;
+	SELECT name INTO payee_name FROM payees WHERE id = NEW.payee_id;
+	NEW.search_vector :=
+		setweight(to_tsvector('russian', coalesce(NEW.description, '')), 'A') ||
+		setweight(to_tsvector('english', coalesce(NEW.description, '')), 'A') ||
+		setweight(to_tsvector('russian', coalesce(payee_name, '')), 'A') ||
+		setweight(to_tsvector('english', coalesce(payee_name, '')), 'A') ||
+		setweight(to_tsvector('russian', coalesce(NEW.notes, '')), 'B') ||
+		setweight(to_tsvector('english', coalesce(NEW.notes, '')), 'B') ||
+		setweight(to_tsvector('russian', coalesce(category_name, '')), 'C') ||
+		setweight(to_tsvector('english', coalesce(category_name, '')), 'C');
+	RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS transactions_search_vector_update ON transactions;
+CREATE TRIGGER transactions_search_vector_update BEFORE INSERT OR UPDATE ON transactions
+	FOR EACH ROW EXECUTE FUNCTION transactions_search_vector();
+
+-- При переименовании категории или получателя пересчитываем векторы их транзакций
+CREATE OR REPLACE FUNCTION categories_search_vector() RETURNS trigger AS $$
+BEGIN
+	UPDATE transactions SET search_vector = NULL WHERE category_id = NEW.id;
+	RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS categories_search_vector_update ON categories;
+CREATE TRIGGER categories_search_vector_update AFTER UPDATE OF name ON categories
+	FOR EACH ROW EXECUTE FUNCTION categories_search_vector();
+
+CREATE OR REPLACE FUNCTION payees_search_vector() RETURNS trigger AS $$
+BEGIN
+	UPDATE transactions SET search_vector = NULL WHERE payee_id = NEW.id;
+	RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS payees_search_vector_update ON payees;
+CREATE TRIGGER payees_search_vector_update AFTER UPDATE OF name ON payees
+	FOR EACH ROW EXECUTE FUNCTION payees_search_vector();
+
+CREATE INDEX IF NOT EXISTS idx_transactions_search_vector ON transactions USING GIN (search_vector);
+
+-- Заполняем векторы транзакций, созданных до появления поиска
+UPDATE transactions SET search_vector = NULL WHERE search_vector IS NULL;
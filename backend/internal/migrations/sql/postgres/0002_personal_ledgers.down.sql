@@ -0,0 +1,2 @@
+-- Созданные бюджеты остаются: записи в них уже перенесены
+DROP INDEX IF EXISTS idx_ledgers_personal_owner;
@@ -0,0 +1,20 @@
+DROP TABLE IF EXISTS alerts;
+DROP TABLE IF EXISTS recurring_items;
+DROP TABLE IF EXISTS goals;
+DROP TABLE IF EXISTS audit_logs;
+DROP TABLE IF EXISTS duplicate_dismissals;
+DROP TABLE IF EXISTS rule_tags;
+DROP TABLE IF EXISTS rules;
+DROP TABLE IF EXISTS attachments;
+DROP TABLE IF EXISTS settlements;
+DROP TABLE IF EXISTS transaction_splits;
+DROP TABLE IF EXISTS transaction_tags;
+DROP TABLE IF EXISTS transactions;
+DROP TABLE IF EXISTS payee_aliases;
+DROP TABLE IF EXISTS payees;
+DROP TABLE IF EXISTS tags;
+DROP TABLE IF EXISTS categories;
+DROP TABLE IF EXISTS ledger_invitations;
+DROP TABLE IF EXISTS ledger_members;
+DROP TABLE IF EXISTS ledgers;
+DROP TABLE IF EXISTS users;
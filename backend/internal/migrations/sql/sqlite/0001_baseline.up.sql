@@ -0,0 +1,296 @@
+-- Схема SQLite, повторяющая схему Postgres: те же ограничения и индексы.
+-- Поиск в SQLite работает через LIKE, поэтому поискового вектора нет
+
+CREATE TABLE IF NOT EXISTS users (
+	id integer PRIMARY KEY AUTOINCREMENT,
+	email text NOT NULL,
+	password text NOT NULL,
+	first_name text NOT NULL,
+	last_name text NOT NULL,
+	email_verified numeric NOT NULL DEFAULT 0,
+	email_verified_at datetime,
+	verification_sent_at datetime,
+	deletion_scheduled_at datetime,
+	created_at datetime,
+	updated_at datetime,
+	deleted_at datetime
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users (email);
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_users_deletion_scheduled_at ON users (deletion_scheduled_at);
+
+CREATE TABLE IF NOT EXISTS ledgers (
+	id integer PRIMARY KEY AUTOINCREMENT,
+	name text NOT NULL,
+	owner_id integer NOT NULL,
+	personal numeric NOT NULL DEFAULT 0,
+	created_at datetime,
+	updated_at datetime
+);
+CREATE INDEX IF NOT EXISTS idx_ledgers_owner_id ON ledgers (owner_id);
+
+CREATE TABLE IF NOT EXISTS ledger_members (
+	id integer PRIMARY KEY AUTOINCREMENT,
+	ledger_id integer NOT NULL,
+	user_id integer NOT NULL,
+	role varchar(10) NOT NULL,
+	created_at datetime,
+	CONSTRAINT fk_ledgers_members FOREIGN KEY (ledger_id) REFERENCES ledgers (id),
+	CONSTRAINT fk_ledger_members_user FOREIGN KEY (user_id) REFERENCES users (id),
+	CONSTRAINT chk_ledger_members_role CHECK (role IN ('owner', 'editor', 'viewer'))
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ledger_member ON ledger_members (ledger_id, user_id);
+CREATE INDEX IF NOT EXISTS idx_ledger_members_user_id ON ledger_members (user_id);
+
+CREATE TABLE IF NOT EXISTS ledger_invitations (
+	id integer PRIMARY KEY AUTOINCREMENT,
+	ledger_id integer NOT NULL,
+	email text NOT NULL,
+	role varchar(10) NOT NULL,
+	invited_by_id integer NOT NULL,
+	status varchar(10) NOT NULL DEFAULT 'pending',
+	expires_at datetime NOT NULL,
+	created_at datetime,
+	updated_at datetime,
+	CONSTRAINT fk_ledger_invitations_ledger FOREIGN KEY (ledger_id) REFERENCES ledgers (id),
+	CONSTRAINT chk_ledger_invitations_role CHECK (role IN ('editor', 'viewer')),
+	CONSTRAINT chk_ledger_invitations_status CHECK (status IN ('pending', 'accepted', 'declined'))
+);
+CREATE INDEX IF NOT EXISTS idx_ledger_invitations_ledger_id ON ledger_invitations (ledger_id);
+CREATE INDEX IF NOT EXISTS idx_ledger_invitations_email ON ledger_invitations (email);
+
+CREATE TABLE IF NOT EXISTS categories (
+	id integer PRIMARY KEY AUTOINCREMENT,
+	user_id integer NOT NULL,
+	ledger_id integer,
+	name text NOT NULL,
+	type varchar(10) NOT NULL,
+	color text DEFAULT '#6B7280',
+	created_at datetime,
+	updated_at datetime,
+	deleted_at datetime,
+	CONSTRAINT fk_users_categories FOREIGN KEY (user_id) REFERENCES users (id),
+	CONSTRAINT chk_categories_type CHECK (type IN ('income', 'expense'))
+);
+CREATE INDEX IF NOT EXISTS idx_categories_user_id ON categories (user_id);
+CREATE INDEX IF NOT EXISTS idx_categories_ledger_id ON categories (ledger_id);
+CREATE INDEX IF NOT EXISTS idx_categories_deleted_at ON categories (deleted_at);
+
+CREATE TABLE IF NOT EXISTS tags (
+	id integer PRIMARY KEY AUTOINCREMENT,
+	user_id integer NOT NULL,
+	name text NOT NULL,
+	color text DEFAULT '#6B7280',
+	created_at datetime,
+	updated_at datetime
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_tag_name ON tags (user_id, name);
+
+CREATE TABLE IF NOT EXISTS payees (
+	id integer PRIMARY KEY AUTOINCREMENT,
+	user_id integer NOT NULL,
+	ledger_id integer NOT NULL,
+	name text NOT NULL,
+	default_category_id integer,
+	created_at datetime,
+	updated_at datetime
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ledger_payee_name ON payees (ledger_id, name);
+CREATE INDEX IF NOT EXISTS idx_payees_user_id ON payees (user_id);
+
+CREATE TABLE IF NOT EXISTS payee_aliases (
+	id integer PRIMARY KEY AUTOINCREMENT,
+	payee_id integer NOT NULL,
+	pattern text NOT NULL,
+	is_regex numeric NOT NULL DEFAULT 0,
+	CONSTRAINT fk_payees_aliases FOREIGN KEY (payee_id) REFERENCES payees (id)
+);
+CREATE INDEX IF NOT EXISTS idx_payee_aliases_payee_id ON payee_aliases (payee_id);
+
+CREATE TABLE IF NOT EXISTS transactions (
+	id integer PRIMARY KEY AUTOINCREMENT,
+	user_id integer NOT NULL,
+	ledger_id integer,
+	category_id integer,
+	payee_id integer,
+	amount real NOT NULL,
+	type varchar(10) NOT NULL,
+	description text NOT NULL,
+	notes text NOT NULL DEFAULT '',
+	date datetime NOT NULL,
+	paid_by_id integer,
+	created_at datetime,
+	updated_at datetime,
+	deleted_at datetime,
+	CONSTRAINT fk_users_transactions FOREIGN KEY (user_id) REFERENCES users (id),
+	CONSTRAINT fk_categories_transactions FOREIGN KEY (category_id) REFERENCES categories (id),
+	CONSTRAINT fk_transactions_payee FOREIGN KEY (payee_id) REFERENCES payees (id),
+	CONSTRAINT chk_transactions_type CHECK (type IN ('income', 'expense'))
+);
+CREATE INDEX IF NOT EXISTS idx_transactions_user_id ON transactions (user_id);
+CREATE INDEX IF NOT EXISTS idx_transactions_ledger_id ON transactions (ledger_id);
+CREATE INDEX IF NOT EXISTS idx_transactions_category_id ON transactions (category_id);
+CREATE INDEX IF NOT EXISTS idx_transactions_payee_id ON transactions (payee_id);
+CREATE INDEX IF NOT EXISTS idx_transactions_date ON transactions (date);
+CREATE INDEX IF NOT EXISTS idx_transactions_deleted_at ON transactions (deleted_at);
+
+CREATE TABLE IF NOT EXISTS transaction_tags (
+	transaction_id integer,
+	tag_id integer,
+	PRIMARY KEY (transaction_id, tag_id),
+	CONSTRAINT fk_transaction_tags_transaction FOREIGN KEY (transaction_id) REFERENCES transactions (id),
+	CONSTRAINT fk_transaction_tags_tag FOREIGN KEY (tag_id) REFERENCES tags (id)
+);
+
+CREATE TABLE IF NOT EXISTS transaction_splits (
+	id integer PRIMARY KEY AUTOINCREMENT,
+	transaction_id integer NOT NULL,
+	user_id integer NOT NULL,
+	amount real NOT NULL,
+	created_at datetime,
+	CONSTRAINT fk_transactions_splits FOREIGN KEY (transaction_id) REFERENCES transactions (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_split_member ON transaction_splits (transaction_id, user_id);
+CREATE INDEX IF NOT EXISTS idx_transaction_splits_user_id ON transaction_splits (user_id);
+
+CREATE TABLE IF NOT EXISTS settlements (
+	id integer PRIMARY KEY AUTOINCREMENT,
+	ledger_id integer NOT NULL,
+	from_user_id integer NOT NULL,
+	to_user_id integer NOT NULL,
+	amount real NOT NULL,
+	date datetime NOT NULL,
+	note text,
+	created_by_id integer NOT NULL,
+	created_at datetime,
+	CONSTRAINT chk_settlements_amount CHECK (amount > 0)
+);
+CREATE INDEX IF NOT EXISTS idx_settlements_ledger_id ON settlements (ledger_id);
+
+CREATE TABLE IF NOT EXISTS attachments (
+	id integer PRIMARY KEY AUTOINCREMENT,
+	transaction_id integer NOT NULL,
+	user_id integer NOT NULL,
+	file_name text NOT NULL,
+	content_type text NOT NULL,
+	size integer NOT NULL,
+	storage_key text NOT NULL,
+	thumbnail_key text,
+	created_at datetime
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_attachments_storage_key ON attachments (storage_key);
+CREATE INDEX IF NOT EXISTS idx_attachments_transaction_id ON attachments (transaction_id);
+CREATE INDEX IF NOT EXISTS idx_attachments_user_id ON attachments (user_id);
+
+CREATE TABLE IF NOT EXISTS rules (
+	id integer PRIMARY KEY AUTOINCREMENT,
+	user_id integer NOT NULL,
+	name text NOT NULL,
+	priority integer NOT NULL DEFAULT 0,
+	enabled numeric NOT NULL,
+	ledger_id integer,
+	description_contains text,
+	description_regex text,
+	min_amount real,
+	max_amount real,
+	type varchar(10),
+	set_category_id integer,
+	set_description text,
+	stop_processing numeric,
+	created_at datetime,
+	updated_at datetime
+);
+CREATE INDEX IF NOT EXISTS idx_rules_user_id ON rules (user_id);
+CREATE INDEX IF NOT EXISTS idx_rules_ledger_id ON rules (ledger_id);
+
+CREATE TABLE IF NOT EXISTS rule_tags (
+	rule_id integer,
+	tag_id integer,
+	PRIMARY KEY (rule_id, tag_id),
+	CONSTRAINT fk_rule_tags_rule FOREIGN KEY (rule_id) REFERENCES rules (id),
+	CONSTRAINT fk_rule_tags_tag FOREIGN KEY (tag_id) REFERENCES tags (id)
+);
+
+CREATE TABLE IF NOT EXISTS duplicate_dismissals (
+	id integer PRIMARY KEY AUTOINCREMENT,
+	ledger_id integer NOT NULL,
+	transaction_id integer NOT NULL,
+	other_id integer NOT NULL,
+	user_id integer NOT NULL,
+	created_at datetime
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_duplicate_pair ON duplicate_dismissals (transaction_id, other_id);
+CREATE INDEX IF NOT EXISTS idx_duplicate_dismissals_ledger_id ON duplicate_dismissals (ledger_id);
+
+CREATE TABLE IF NOT EXISTS audit_logs (
+	id integer PRIMARY KEY AUTOINCREMENT,
+	user_id integer NOT NULL,
+	ledger_id integer NOT NULL,
+	entity_type varchar(20) NOT NULL,
+	entity_id integer NOT NULL,
+	action varchar(10) NOT NULL,
+	before text NOT NULL DEFAULT '',
+	after text NOT NULL DEFAULT '',
+	changes text NOT NULL DEFAULT '',
+	ip varchar(45) NOT NULL DEFAULT '',
+	created_at datetime
+);
+CREATE INDEX IF NOT EXISTS idx_audit_entity ON audit_logs (ledger_id, entity_type, entity_id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_user_id ON audit_logs (user_id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_created_at ON audit_logs (created_at);
+
+CREATE TABLE IF NOT EXISTS goals (
+	id integer PRIMARY KEY AUTOINCREMENT,
+	user_id integer NOT NULL,
+	ledger_id integer NOT NULL,
+	name text NOT NULL,
+	target_amount real NOT NULL,
+	deadline datetime,
+	category_id integer,
+	start_date datetime NOT NULL,
+	created_at datetime,
+	updated_at datetime
+);
+CREATE INDEX IF NOT EXISTS idx_goals_user_id ON goals (user_id);
+CREATE INDEX IF NOT EXISTS idx_goals_ledger_id ON goals (ledger_id);
+CREATE INDEX IF NOT EXISTS idx_goals_category_id ON goals (category_id);
+
+CREATE TABLE IF NOT EXISTS recurring_items (
+	id integer PRIMARY KEY AUTOINCREMENT,
+	user_id integer NOT NULL,
+	ledger_id integer NOT NULL,
+	name text NOT NULL,
+	amount real NOT NULL,
+	type varchar(10) NOT NULL,
+	period varchar(10) NOT NULL,
+	next_date datetime NOT NULL,
+	category_id integer,
+	payee_id integer,
+	match_key text NOT NULL DEFAULT '',
+	created_at datetime,
+	updated_at datetime,
+	CONSTRAINT chk_recurring_items_type CHECK (type IN ('income', 'expense'))
+);
+CREATE INDEX IF NOT EXISTS idx_recurring_items_user_id ON recurring_items (user_id);
+CREATE INDEX IF NOT EXISTS idx_recurring_items_ledger_id ON recurring_items (ledger_id);
+CREATE INDEX IF NOT EXISTS idx_recurring_items_category_id ON recurring_items (category_id);
+CREATE INDEX IF NOT EXISTS idx_recurring_items_payee_id ON recurring_items (payee_id);
+
+CREATE TABLE IF NOT EXISTS alerts (
+	id integer PRIMARY KEY AUTOINCREMENT,
+	ledger_id integer NOT NULL,
+	key text NOT NULL,
+	kind varchar(20) NOT NULL,
+	transaction_id integer,
+	category_id integer,
+	payee_id integer,
+	amount real,
+	expected real,
+	message text NOT NULL,
+	acknowledged_at datetime,
+	acknowledged_by_id integer,
+	created_at datetime
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_alert_key ON alerts (ledger_id, key);
+CREATE INDEX IF NOT EXISTS idx_alerts_transaction_id ON alerts (transaction_id);
+CREATE INDEX IF NOT EXISTS idx_alerts_created_at ON alerts (created_at);
@@ -0,0 +1,22 @@
+-- Один личный бюджет на пользователя. Индекс создается до заполнения: если
+-- дубликаты уже есть, миграция остановится, а не спрячет их
+CREATE UNIQUE INDEX idx_ledgers_personal_owner ON ledgers (owner_id) WHERE personal = 1;
+
+-- Личные бюджеты пользователям, зарегистрированным до их появления, и перенос
+-- в них записей без бюджета
+INSERT INTO ledgers (name, owner_id, personal, created_at, updated_at)
+SELECT 'Personal', users.id, 1, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP FROM users
+WHERE NOT EXISTS (SELECT 1 FROM ledgers WHERE ledgers.owner_id = users.id AND ledgers.personal = 1);
+
+INSERT INTO ledger_members (ledger_id, user_id, role, created_at)
+SELECT ledgers.id, ledgers.owner_id, 'owner', CURRENT_TIMESTAMP FROM ledgers
+WHERE ledgers.personal = 1 AND NOT EXISTS (
+	SELECT 1 FROM ledger_members WHERE ledger_members.ledger_id = ledgers.id AND ledger_members.user_id = ledgers.owner_id);
+
+UPDATE transactions SET ledger_id =
+	(SELECT ledgers.id FROM ledgers WHERE ledgers.owner_id = transactions.user_id AND ledgers.personal = 1)
+WHERE ledger_id IS NULL OR ledger_id = 0;
+
+UPDATE categories SET ledger_id =
+	(SELECT ledgers.id FROM ledgers WHERE ledgers.owner_id = categories.user_id AND ledgers.personal = 1)
+WHERE ledger_id IS NULL OR ledger_id = 0;
@@ -0,0 +1,28 @@
+package model
+
+import "time"
+
+// Виды предупреждений о необычных тратах
+const (
+	AlertAmountOutlier = "amount_outlier" // сумма намного выше обычной для категории
+	AlertNewPayee      = "new_payee"      // крупная сумма новому получателю
+	AlertCategoryPace  = "category_pace"  // траты категории с начала месяца опережают обычный темп
+)
+
+// Alert предупреждение о необычной трате в бюджете. Key исключает повторные
+// предупреждения об одном и том же
+type Alert struct {
+	ID               uint       `json:"id" gorm:"primaryKey"`
+	LedgerID         uint       `json:"ledger_id" gorm:"not null;uniqueIndex:idx_alert_key"`
+	Key              string     `json:"-" gorm:"not null;uniqueIndex:idx_alert_key"`
+	Kind             string     `json:"kind" gorm:"type:varchar(20);not null"`
+	TransactionID    *uint      `json:"transaction_id,omitempty" gorm:"index"`
+	CategoryID       *uint      `json:"category_id,omitempty"`
+	PayeeID          *uint      `json:"payee_id,omitempty"`
+	Amount           float64    `json:"amount"`
+	Expected         float64    `json:"expected"` // обычное значение, с которым сравнивалась сумма
+	Message          string     `json:"message" gorm:"not null"`
+	AcknowledgedAt   *time.Time `json:"acknowledged_at,omitempty"`
+	AcknowledgedByID *uint      `json:"acknowledged_by_id,omitempty"`
+	CreatedAt        time.Time  `json:"created_at" gorm:"index"`
+}
@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// Attachment файл (чек, гарантийный талон), приложенный к транзакции
+type Attachment struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	TransactionID uint      `json:"transaction_id" gorm:"not null;index"`
+	UserID        uint      `json:"user_id" gorm:"not null;index"` // кто загрузил, учитывается в квоте
+	FileName      string    `json:"file_name" gorm:"not null"`
+	ContentType   string    `json:"content_type" gorm:"not null"`
+	Size          int64     `json:"size" gorm:"not null"`
+	StorageKey    string    `json:"-" gorm:"not null;uniqueIndex"`
+	ThumbnailKey  string    `json:"-"`
+	CreatedAt     time.Time `json:"created_at"`
+}
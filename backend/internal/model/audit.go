@@ -0,0 +1,34 @@
+package model
+
+import "time"
+
+// Сущности журнала изменений
+const (
+	AuditEntityTransaction = "transaction"
+	AuditEntityCategory    = "category"
+	AuditEntityLedger      = "ledger"
+)
+
+// Действия журнала изменений
+const (
+	AuditActionCreate  = "create"
+	AuditActionUpdate  = "update"
+	AuditActionDelete  = "delete"
+	AuditActionRestore = "restore"
+)
+
+// AuditLog запись журнала изменений. Записи только добавляются: состояние до и
+// после изменения и разница между ними хранятся как JSON
+type AuditLog struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	UserID     uint      `json:"user_id" gorm:"not null;index"` // кто изменил
+	LedgerID   uint      `json:"ledger_id" gorm:"not null;index:idx_audit_entity"`
+	EntityType string    `json:"entity_type" gorm:"type:varchar(20);not null;index:idx_audit_entity"`
+	EntityID   uint      `json:"entity_id" gorm:"not null;index:idx_audit_entity"`
+	Action     string    `json:"action" gorm:"type:varchar(10);not null"`
+	Before     string    `json:"before" gorm:"type:text;not null;default:''"`
+	After      string    `json:"after" gorm:"type:text;not null;default:''"`
+	Changes    string    `json:"changes" gorm:"type:text;not null;default:''"`
+	IP         string    `json:"ip" gorm:"type:varchar(45);not null;default:''"`
+	CreatedAt  time.Time `json:"created_at" gorm:"index"`
+}
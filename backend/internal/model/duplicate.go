@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+// DuplicateDismissal пара транзакций, которую пользователь отметил как не дубликат.
+// TransactionID всегда меньше OtherID
+type DuplicateDismissal struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	LedgerID      uint      `json:"ledger_id" gorm:"not null;index"`
+	TransactionID uint      `json:"transaction_id" gorm:"not null;uniqueIndex:idx_duplicate_pair"`
+	OtherID       uint      `json:"other_id" gorm:"not null;uniqueIndex:idx_duplicate_pair"`
+	UserID        uint      `json:"user_id" gorm:"not null"`
+	CreatedAt     time.Time `json:"created_at"`
+}
@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// Goal цель накопления. Взносами считаются транзакции бюджета начиная со StartDate:
+// с категорией — суммы транзакций этой категории, без нее — доходы за вычетом расходов
+type Goal struct {
+	ID           uint       `json:"id" gorm:"primaryKey"`
+	UserID       uint       `json:"user_id" gorm:"not null;index"`
+	LedgerID     uint       `json:"ledger_id" gorm:"not null;index"`
+	Name         string     `json:"name" gorm:"not null"`
+	TargetAmount float64    `json:"target_amount" gorm:"not null"`
+	Deadline     *time.Time `json:"deadline,omitempty"`
+	CategoryID   *uint      `json:"category_id,omitempty" gorm:"index"`
+	StartDate    time.Time  `json:"start_date" gorm:"not null"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
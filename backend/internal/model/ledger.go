@@ -0,0 +1,54 @@
+package model
+
+import "time"
+
+// Роли участников бюджета
+const (
+	RoleOwner  = "owner"
+	RoleEditor = "editor"
+	RoleViewer = "viewer"
+)
+
+// Статусы приглашений в бюджет
+const (
+	InvitationPending  = "pending"
+	InvitationAccepted = "accepted"
+	InvitationDeclined = "declined"
+)
+
+// Ledger общий бюджет, которому принадлежат категории и транзакции
+type Ledger struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Name      string    `json:"name" gorm:"not null"`
+	OwnerID   uint      `json:"owner_id" gorm:"not null;index"`
+	Personal  bool      `json:"personal" gorm:"not null;default:false"` // личный бюджет, создается при регистрации
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Members []LedgerMember `json:"members,omitempty"`
+}
+
+type LedgerMember struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	LedgerID  uint      `json:"ledger_id" gorm:"not null;uniqueIndex:idx_ledger_member"`
+	UserID    uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_ledger_member;index"`
+	Role      string    `json:"role" gorm:"type:varchar(10);not null;check:role IN ('owner', 'editor', 'viewer')"`
+	CreatedAt time.Time `json:"created_at"`
+
+	Ledger *Ledger `json:"ledger,omitempty"`
+	User   *User   `json:"user,omitempty"`
+}
+
+type LedgerInvitation struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	LedgerID    uint      `json:"ledger_id" gorm:"not null;index"`
+	Email       string    `json:"email" gorm:"not null;index"`
+	Role        string    `json:"role" gorm:"type:varchar(10);not null;check:role IN ('editor', 'viewer')"`
+	InvitedByID uint      `json:"invited_by_id" gorm:"not null"`
+	Status      string    `json:"status" gorm:"type:varchar(10);not null;default:'pending';check:status IN ('pending', 'accepted', 'declined')"`
+	ExpiresAt   time.Time `json:"expires_at" gorm:"not null"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	Ledger *Ledger `json:"ledger,omitempty"`
+}
@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// Payee получатель или источник платежа в бюджете. Псевдонимы сопоставляют
+// сырые описания из банка ("YANDEX*TAXI 1234") с получателем
+type Payee struct {
+	ID                uint      `json:"id" gorm:"primaryKey"`
+	UserID            uint      `json:"user_id" gorm:"not null;index"`
+	LedgerID          uint      `json:"ledger_id" gorm:"not null;uniqueIndex:idx_ledger_payee_name"`
+	Name              string    `json:"name" gorm:"not null;uniqueIndex:idx_ledger_payee_name"`
+	DefaultCategoryID *uint     `json:"default_category_id,omitempty"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+
+	Aliases []PayeeAlias `json:"aliases,omitempty"`
+}
+
+// PayeeAlias шаблон описания: последовательность слов без учета регистра и
+// знаков препинания либо регулярное выражение
+type PayeeAlias struct {
+	ID      uint   `json:"id" gorm:"primaryKey"`
+	PayeeID uint   `json:"payee_id" gorm:"not null;index"`
+	Pattern string `json:"pattern" gorm:"not null"`
+	IsRegex bool   `json:"is_regex" gorm:"not null;default:false"`
+}
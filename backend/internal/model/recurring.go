@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+// Периоды регулярных платежей
+const (
+	PeriodWeekly    = "weekly"
+	PeriodBiweekly  = "biweekly"
+	PeriodMonthly   = "monthly"
+	PeriodQuarterly = "quarterly"
+	PeriodYearly    = "yearly"
+)
+
+// RecurringItem отслеживаемый регулярный платеж, например подписка. MatchKey
+// связывает его с транзакциями, по которым он был обнаружен
+type RecurringItem struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	UserID     uint      `json:"user_id" gorm:"not null;index"`
+	LedgerID   uint      `json:"ledger_id" gorm:"not null;index"`
+	Name       string    `json:"name" gorm:"not null"`
+	Amount     float64   `json:"amount" gorm:"not null"`
+	Type       string    `json:"type" gorm:"type:varchar(10);not null;check:type IN ('income', 'expense')"`
+	Period     string    `json:"period" gorm:"type:varchar(10);not null"`
+	NextDate   time.Time `json:"next_date" gorm:"not null"`
+	CategoryID *uint     `json:"category_id,omitempty" gorm:"index"`
+	PayeeID    *uint     `json:"payee_id,omitempty" gorm:"index"`
+	MatchKey   string    `json:"match_key" gorm:"not null;default:''"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
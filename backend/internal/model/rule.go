@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+// Rule пользовательское правило автоматической категоризации транзакций.
+// Пустое условие не ограничивает выборку; правила применяются по возрастанию приоритета
+type Rule struct {
+	ID       uint   `json:"id" gorm:"primaryKey"`
+	UserID   uint   `json:"user_id" gorm:"not null;index"`
+	Name     string `json:"name" gorm:"not null"`
+	Priority int    `json:"priority" gorm:"not null;default:0"`
+	Enabled  bool   `json:"enabled" gorm:"not null"`
+
+	// Условия
+	LedgerID            *uint    `json:"ledger_id,omitempty" gorm:"index"`
+	DescriptionContains string   `json:"description_contains"`
+	DescriptionRegex    string   `json:"description_regex"`
+	MinAmount           *float64 `json:"min_amount,omitempty"`
+	MaxAmount           *float64 `json:"max_amount,omitempty"`
+	Type                string   `json:"type" gorm:"type:varchar(10)"`
+
+	// Действия
+	SetCategoryID  *uint  `json:"set_category_id,omitempty"`
+	SetDescription string `json:"set_description"` // с регулярным выражением поддерживает $1, ${name}
+	StopProcessing bool   `json:"stop_processing"`
+	Tags           []Tag  `json:"tags,omitempty" gorm:"many2many:rule_tags"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
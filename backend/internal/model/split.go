@@ -0,0 +1,33 @@
+package model
+
+import "time"
+
+// Способы разделения общего расхода
+const (
+	SplitEqual   = "equal"
+	SplitShares  = "shares"
+	SplitPercent = "percent"
+	SplitExact   = "exact"
+)
+
+// TransactionSplit доля участника в общем расходе
+type TransactionSplit struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	TransactionID uint      `json:"transaction_id" gorm:"not null;uniqueIndex:idx_split_member"`
+	UserID        uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_split_member;index"`
+	Amount        float64   `json:"amount" gorm:"not null"` // сколько участник должен плательщику
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// Settlement запись о возврате долга между участниками бюджета
+type Settlement struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	LedgerID    uint      `json:"ledger_id" gorm:"not null;index"`
+	FromUserID  uint      `json:"from_user_id" gorm:"not null"`
+	ToUserID    uint      `json:"to_user_id" gorm:"not null"`
+	Amount      float64   `json:"amount" gorm:"not null;check:amount > 0"`
+	Date        time.Time `json:"date" gorm:"not null"`
+	Note        string    `json:"note"`
+	CreatedByID uint      `json:"created_by_id" gorm:"not null"`
+	CreatedAt   time.Time `json:"created_at"`
+}
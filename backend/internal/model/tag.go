@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+// Tag пользовательская метка для транзакций, дополняющая категории
+type Tag struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_user_tag_name"`
+	Name      string    `json:"name" gorm:"not null;uniqueIndex:idx_user_tag_name"`
+	Color     string    `json:"color" gorm:"default:'#6B7280'"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
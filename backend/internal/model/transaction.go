@@ -1,18 +1,33 @@
 package model
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type Transaction struct {
-	ID          uint      `json:"id" gorm:"primaryKey"`
-	UserID      uint      `json:"user_id" gorm:"not null;index"`
-	CategoryID  *uint     `json:"category_id,omitempty" gorm:"index"`
-	Amount      float64   `json:"amount" gorm:"not null"`
-	Type        string    `json:"type" gorm:"type:varchar(10);not null;check:type IN ('income', 'expense')"`
-	Description string    `json:"description" gorm:"not null"`
-	Date        time.Time `json:"date" gorm:"not null;index"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	UserID      uint           `json:"user_id" gorm:"not null;index"`
+	LedgerID    uint           `json:"ledger_id" gorm:"index"`
+	CategoryID  *uint          `json:"category_id,omitempty" gorm:"index"`
+	PayeeID     *uint          `json:"payee_id,omitempty" gorm:"index"`
+	Amount      float64        `json:"amount" gorm:"not null"`
+	Type        string         `json:"type" gorm:"type:varchar(10);not null;check:type IN ('income', 'expense')"`
+	Description string         `json:"description" gorm:"not null"`
+	Notes       string         `json:"notes" gorm:"not null;default:''"`
+	Date        time.Time      `json:"date" gorm:"not null;index"`
+	PaidByID    *uint          `json:"paid_by_id,omitempty"` // плательщик общего расхода
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"` // в корзине до окончательного удаления
+
+	User     User               `json:"user,omitempty"`
+	Category *Category          `json:"category,omitempty"`
+	Payee    *Payee             `json:"payee,omitempty"`
+	Splits   []TransactionSplit `json:"splits,omitempty"`
+	Tags     []Tag              `json:"tags,omitempty" gorm:"many2many:transaction_tags"`
 
-	User     User      `json:"user,omitempty"`
-	Category *Category `json:"category,omitempty"`
+	// Заполняется при создании: похожие транзакции, которые могут быть дубликатами
+	PossibleDuplicates []uint `json:"possible_duplicates,omitempty" gorm:"-"`
 }
@@ -7,11 +7,19 @@ import (
 )
 
 type User struct {
-	ID        uint           `json:"id" gorm:"primaryKey"`
-	Email     string         `json:"email" gorm:"uniqueIndex;not null"`
-	Password  string         `json:"-" gorm:"not null"`
-	FirstName string         `json:"first_name" gorm:"not null"`
-	LastName  string         `json:"last_name" gorm:"not null"`
+	ID        uint   `json:"id" gorm:"primaryKey"`
+	Email     string `json:"email" gorm:"uniqueIndex;not null"`
+	Password  string `json:"-" gorm:"not null"`
+	FirstName string `json:"first_name" gorm:"not null"`
+	LastName  string `json:"last_name" gorm:"not null"`
+
+	EmailVerified      bool       `json:"email_verified" gorm:"not null;default:false"`
+	EmailVerifiedAt    *time.Time `json:"email_verified_at,omitempty"`
+	VerificationSentAt *time.Time `json:"-"`
+
+	// Момент, после которого аккаунт и все данные будут удалены безвозвратно
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty" gorm:"index"`
+
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"finance-backend/internal/model"
+)
+
+type AlertRepository struct {
+	db *gorm.DB
+}
+
+func NewAlertRepository(db *gorm.DB) *AlertRepository {
+	return &AlertRepository{db: db}
+}
+
+// GetActiveLedgers возвращает бюджеты, в которых с момента since добавлялись транзакции
+func (r *AlertRepository) GetActiveLedgers(ctx context.Context, since time.Time) ([]uint, error) {
+	var ids []uint
+	err := r.db.WithContext(ctx).Model(&model.Transaction{}).Where("created_at >= ?", since).Distinct().Pluck("ledger_id", &ids).Error
+	return ids, err
+}
+
+// GetExpensesSince возвращает расходы бюджета с датой не раньше from
+func (r *AlertRepository) GetExpensesSince(ctx context.Context, ledgerID uint, from time.Time) ([]model.Transaction, error) {
+	var transactions []model.Transaction
+	err := r.db.WithContext(ctx).
+		Select("id", "category_id", "payee_id", "amount", "description", "date", "created_at").
+		Where("ledger_id = ? AND type = ? AND date >= ?", ledgerID, "expense", from).
+		Order("date, id").
+		Find(&transactions).Error
+	return transactions, err
+}
+
+// Create сохраняет предупреждения, пропуская уже записанные
+func (r *AlertRepository) Create(ctx context.Context, alerts []model.Alert) (int64, error) {
+	if len(alerts) == 0 {
+		return 0, nil
+	}
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&alerts)
+	return result.RowsAffected, result.Error
+}
+
+// GetByLedger возвращает предупреждения бюджета, новые первыми
+func (r *AlertRepository) GetByLedger(ctx context.Context, userID, ledgerID uint, includeAcknowledged bool) ([]model.Alert, error) {
+	query := r.db.WithContext(ctx).Scopes(inLedger(userID, ledgerID))
+	if !includeAcknowledged {
+		query = query.Where("acknowledged_at IS NULL")
+	}
+
+	var alerts []model.Alert
+	err := query.Order("created_at DESC, id DESC").Find(&alerts).Error
+	return alerts, err
+}
+
+// Acknowledge отмечает предупреждение как просмотренное
+func (r *AlertRepository) Acknowledge(ctx context.Context, userID, ledgerID, id uint) (*model.Alert, error) {
+	var alert model.Alert
+	if err := r.db.WithContext(ctx).Scopes(inLedger(userID, ledgerID)).Where("id = ?", id).First(&alert).Error; err != nil {
+		return nil, notFound(err, "alert not found")
+	}
+	if alert.AcknowledgedAt != nil {
+		return &alert, nil
+	}
+
+	now := time.Now()
+	alert.AcknowledgedAt = &now
+	alert.AcknowledgedByID = &userID
+	err := r.db.WithContext(ctx).Model(&alert).Updates(map[string]interface{}{
+		"acknowledged_at":    alert.AcknowledgedAt,
+		"acknowledged_by_id": userID,
+	}).Error
+	return &alert, err
+}
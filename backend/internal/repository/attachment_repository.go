@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"finance-backend/internal/model"
+)
+
+type AttachmentRepository struct {
+	db *gorm.DB
+}
+
+func NewAttachmentRepository(db *gorm.DB) *AttachmentRepository {
+	return &AttachmentRepository{db: db}
+}
+
+// transactionInLedger ограничивает вложения транзакциями доступного пользователю бюджета;
+// вложения транзакций в корзине недоступны до восстановления
+func transactionInLedger(userID, ledgerID uint) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("EXISTS (SELECT 1 FROM transactions WHERE transactions.id = attachments.transaction_id AND transactions.deleted_at IS NULL "+
+			"AND transactions.ledger_id = ? AND EXISTS (SELECT 1 FROM ledger_members WHERE ledger_members.ledger_id = ? AND ledger_members.user_id = ?))",
+			ledgerID, ledgerID, userID)
+	}
+}
+
+// CreateWithinQuota сохраняет вложение, если с ним файлы пользователя не превысят
+// quota байт, и возвращает false, если превысят. Строка пользователя
+// блокируется, чтобы параллельные загрузки не прошли проверку одновременно
+func (r *AttachmentRepository) CreateWithinQuota(ctx context.Context, attachment *model.Attachment, quota int64) (bool, error) {
+	created := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var user model.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&user, attachment.UserID).Error; err != nil {
+			return notFound(err, "user not found")
+		}
+
+		var used int64
+		if err := tx.Model(&model.Attachment{}).Where("user_id = ?", attachment.UserID).
+			Select("COALESCE(SUM(size), 0)").Scan(&used).Error; err != nil {
+			return err
+		}
+		if used+attachment.Size > quota {
+			return nil
+		}
+
+		if err := tx.Create(attachment).Error; err != nil {
+			return err
+		}
+		created = true
+		return nil
+	})
+	return created, err
+}
+
+// GetByID возвращает вложение с проверкой доступа к бюджету
+func (r *AttachmentRepository) GetByID(ctx context.Context, userID, ledgerID uint, id uint) (*model.Attachment, error) {
+	var attachment model.Attachment
+	err := r.db.WithContext(ctx).Scopes(transactionInLedger(userID, ledgerID)).Where("id = ?", id).First(&attachment).Error
+	if err != nil {
+		return nil, notFound(err, "attachment not found")
+	}
+	return &attachment, nil
+}
+
+// GetByTransaction возвращает вложения транзакции
+func (r *AttachmentRepository) GetByTransaction(ctx context.Context, userID, ledgerID uint, transactionID uint) ([]model.Attachment, error) {
+	var attachments []model.Attachment
+	err := r.db.WithContext(ctx).Scopes(transactionInLedger(userID, ledgerID)).
+		Where("transaction_id = ?", transactionID).
+		Order("created_at").
+		Find(&attachments).Error
+	return attachments, err
+}
+
+// TotalSizeByUser возвращает суммарный размер файлов, загруженных пользователем
+func (r *AttachmentRepository) TotalSizeByUser(ctx context.Context, userID uint) (int64, error) {
+	var total int64
+	err := r.db.WithContext(ctx).Model(&model.Attachment{}).Where("user_id = ?", userID).Select("COALESCE(SUM(size), 0)").Scan(&total).Error
+	return total, err
+}
+
+// Delete удаляет запись о вложении
+func (r *AttachmentRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&model.Attachment{}, id).Error
+}
+
+// GetOrphans возвращает вложения, транзакции которых уже удалены
+func (r *AttachmentRepository) GetOrphans(ctx context.Context, limit int) ([]model.Attachment, error) {
+	var attachments []model.Attachment
+	err := r.db.WithContext(ctx).Where("NOT EXISTS (SELECT 1 FROM transactions WHERE transactions.id = attachments.transaction_id)").
+		Limit(limit).
+		Find(&attachments).Error
+	return attachments, err
+}
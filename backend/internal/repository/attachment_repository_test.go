@@ -0,0 +1,56 @@
+package repository_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"finance-backend/internal/model"
+	"finance-backend/internal/repository"
+)
+
+func TestCreateWithinQuotaConcurrent(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, db *gorm.DB) {
+		ctx := context.Background()
+		repo := repository.NewAttachmentRepository(db)
+		userID, ledgerID := newUser(t, db, "uploader@example.com")
+		transaction := newTransaction(t, db, userID, ledgerID, "Чек", time.Now())
+
+		// Квоты хватает ровно на два файла из десяти параллельных
+		var wg sync.WaitGroup
+		results := make([]bool, 10)
+		errs := make([]error, len(results))
+		for i := range results {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				results[i], errs[i] = repo.CreateWithinQuota(ctx, &model.Attachment{
+					TransactionID: transaction.ID, UserID: userID, FileName: "check.pdf",
+					ContentType: "application/pdf", Size: 40, StorageKey: fmt.Sprintf("key-%d", i),
+				}, 100)
+			}()
+		}
+		wg.Wait()
+
+		created := 0
+		for i, ok := range results {
+			if errs[i] != nil {
+				t.Fatalf("upload %d: %v", i, errs[i])
+			}
+			if ok {
+				created++
+			}
+		}
+		used, err := repo.TotalSizeByUser(ctx, userID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if created != 2 || used != 80 {
+			t.Errorf("created %d attachments of %d bytes in total, want 2 of 80", created, used)
+		}
+	})
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"finance-backend/internal/model"
+)
+
+type AuditRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditRepository(db *gorm.DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+// Create добавляет запись в журнал изменений
+func (r *AuditRepository) Create(ctx context.Context, entry *model.AuditLog) error {
+	return r.db.WithContext(ctx).Create(entry).Error
+}
+
+// GetEntityHistory возвращает историю изменений сущности бюджета, новые записи первыми
+func (r *AuditRepository) GetEntityHistory(ctx context.Context, ledgerID uint, entityType string, entityID uint, limit, offset int) ([]model.AuditLog, int64, error) {
+	query := r.db.WithContext(ctx).Model(&model.AuditLog{}).
+		Where("ledger_id = ? AND entity_type = ? AND entity_id = ?", ledgerID, entityType, entityID)
+	return r.page(query, limit, offset)
+}
+
+// GetByUser возвращает изменения, сделанные пользователем, новые записи первыми
+func (r *AuditRepository) GetByUser(ctx context.Context, userID uint, limit, offset int) ([]model.AuditLog, int64, error) {
+	query := r.db.WithContext(ctx).Model(&model.AuditLog{}).Where("user_id = ?", userID)
+	return r.page(query, limit, offset)
+}
+
+func (r *AuditRepository) page(query *gorm.DB, limit, offset int) ([]model.AuditLog, int64, error) {
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var entries []model.AuditLog
+	err := query.Order("created_at DESC, id DESC").Limit(limit).Offset(offset).Find(&entries).Error
+	return entries, total, err
+}
@@ -0,0 +1,149 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gorm.io/gorm/logger"
+
+	"finance-backend/internal/database"
+	"finance-backend/internal/dto"
+	"finance-backend/internal/migrations"
+	"finance-backend/internal/repository"
+)
+
+// autoMigrateSchema схема, которую AutoMigrate создавал в самой первой версии
+// приложения: только пользователи, категории и транзакции
+const autoMigrateSchema = `
+CREATE TABLE users (
+	id bigserial PRIMARY KEY,
+	email text NOT NULL,
+	password text NOT NULL,
+	first_name text NOT NULL,
+	last_name text NOT NULL,
+	created_at timestamptz,
+	updated_at timestamptz,
+	deleted_at timestamptz
+);
+CREATE UNIQUE INDEX idx_users_email ON users (email);
+CREATE INDEX idx_users_deleted_at ON users (deleted_at);
+
+CREATE TABLE categories (
+	id bigserial PRIMARY KEY,
+	user_id bigint NOT NULL,
+	name text NOT NULL,
+	type varchar(10) NOT NULL,
+	color text DEFAULT '#6B7280',
+	created_at timestamptz,
+	updated_at timestamptz,
+	CONSTRAINT fk_users_categories FOREIGN KEY (user_id) REFERENCES users (id),
+	CONSTRAINT chk_categories_type CHECK (type IN ('income', 'expense'))
+);
+CREATE INDEX idx_categories_user_id ON categories (user_id);
+
+CREATE TABLE transactions (
+	id bigserial PRIMARY KEY,
+	user_id bigint NOT NULL,
+	category_id bigint,
+	amount decimal NOT NULL,
+	type varchar(10) NOT NULL,
+	description text NOT NULL,
+	date timestamptz NOT NULL,
+	created_at timestamptz,
+	updated_at timestamptz,
+	CONSTRAINT fk_users_transactions FOREIGN KEY (user_id) REFERENCES users (id),
+	CONSTRAINT fk_categories_transactions FOREIGN KEY (category_id) REFERENCES categories (id),
+	CONSTRAINT chk_transactions_type CHECK (type IN ('income', 'expense'))
+);
+CREATE INDEX idx_transactions_user_id ON transactions (user_id);
+CREATE INDEX idx_transactions_category_id ON transactions (category_id);
+CREATE INDEX idx_transactions_date ON transactions (date);
+
+INSERT INTO users (email, password, first_name, last_name, created_at, updated_at)
+	VALUES ('old@example.com', 'hash', 'Old', 'User', now(), now());
+INSERT INTO categories (user_id, name, type, created_at, updated_at)
+	VALUES (1, 'Еда', 'expense', now(), now());
+INSERT INTO transactions (user_id, category_id, amount, type, description, date, created_at, updated_at)
+	VALUES (1, 1, 250, 'expense', 'Продукты', now(), now(), now());
+`
+
+// Миграции применяются к базе, созданной AutoMigrate первой версии: базовая
+// миграция добавляет недостающие столбцы, а данные переносятся в личный бюджет
+func TestMigrateAutoMigrateSchema(t *testing.T) {
+	cfg, ok := postgresConfig()
+	if !ok {
+		t.Skip("TEST_POSTGRES_HOST or TEST_POSTGRES_DB is not set")
+	}
+	db, err := database.Open(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.Logger = logger.Default.LogMode(logger.Silent)
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+
+	if err := db.Exec("DROP SCHEMA public CASCADE; CREATE SCHEMA public").Error; err != nil {
+		t.Fatalf("reset schema: %v", err)
+	}
+	if err := db.Exec(autoMigrateSchema).Error; err != nil {
+		t.Fatalf("create AutoMigrate schema: %v", err)
+	}
+	migrator, err := migrations.New(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := migrator.Up(); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	added := map[string][]string{
+		"users":        {"email_verified", "email_verified_at", "verification_sent_at", "deletion_scheduled_at"},
+		"categories":   {"ledger_id", "deleted_at"},
+		"transactions": {"ledger_id", "payee_id", "notes", "paid_by_id", "deleted_at", "search_vector"},
+	}
+	for table, columns := range added {
+		for _, column := range columns {
+			if !db.Migrator().HasColumn(table, column) {
+				t.Errorf("column %s.%s is missing", table, column)
+			}
+		}
+	}
+	if !db.Migrator().HasConstraint("transactions", "fk_transactions_payee") {
+		t.Error("foreign key fk_transactions_payee is missing")
+	}
+
+	ctx := context.Background()
+	user, err := repository.NewUserRepository(db).GetByEmail(ctx, "old@example.com")
+	if err != nil {
+		t.Fatalf("user: %v", err)
+	}
+	if user.EmailVerified || user.DeletionScheduledAt != nil {
+		t.Errorf("user = %+v, want unverified and not scheduled for deletion", user)
+	}
+	ledger, err := repository.NewLedgerRepository(db).GetPersonal(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("personal ledger: %v", err)
+	}
+
+	categories, err := repository.NewCategoryRepository(db).GetByLedger(ctx, user.ID, ledger.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(categories) != 1 || categories[0].Name != "Еда" {
+		t.Errorf("categories = %+v, want the old one in the personal ledger", categories)
+	}
+	transactions, err := repository.NewTransactionRepository(db).GetByLedger(ctx, user.ID, ledger.ID, dto.TransactionFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(transactions) != 1 || transactions[0].Description != "Продукты" || transactions[0].Notes != "" {
+		t.Errorf("transactions = %+v, want the old one in the personal ledger", transactions)
+	}
+
+	// Новые записи сохраняются во всех столбцах текущей модели
+	newTransaction(t, db, user.ID, ledger.ID, "После миграции", time.Now())
+}
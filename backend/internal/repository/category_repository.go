@@ -1,7 +1,9 @@
 package repository
 
 import (
+	"context"
 	"errors"
+	"time"
 
 	"gorm.io/gorm"
 
@@ -17,32 +19,96 @@ func NewCategoryRepository(db *gorm.DB) *CategoryRepository {
 }
 
 // Create создает новую категорию
-func (r *CategoryRepository) Create(category *model.Category) error {
-	return r.db.Create(category).Error
+func (r *CategoryRepository) Create(ctx context.Context, category *model.Category) error {
+	return r.db.WithContext(ctx).Create(category).Error
 }
 
-// GetByUserID возвращает все категории пользователя
-func (r *CategoryRepository) GetByUserID(userID uint) ([]model.Category, error) {
+// GetByLedger возвращает все категории бюджета
+func (r *CategoryRepository) GetByLedger(ctx context.Context, userID, ledgerID uint) ([]model.Category, error) {
 	var categories []model.Category
-	err := r.db.Where("user_id = ?", userID).Find(&categories).Error
+	err := r.db.WithContext(ctx).Scopes(inLedger(userID, ledgerID)).Find(&categories).Error
 	return categories, err
 }
 
-// GetByID возвращает категорию по ID с проверкой пользователя
-func (r *CategoryRepository) GetByID(userID uint, id uint) (*model.Category, error) {
+// GetByID возвращает категорию по ID с проверкой доступа к бюджету
+func (r *CategoryRepository) GetByID(ctx context.Context, userID, ledgerID uint, id uint) (*model.Category, error) {
 	var category model.Category
-	err := r.db.Where("id = ? AND user_id = ?", id, userID).First(&category).Error
+	err := r.db.WithContext(ctx).Scopes(inLedger(userID, ledgerID)).Where("id = ?", id).First(&category).Error
 	if err != nil {
-		return nil, errors.New("category not found")
+		return nil, notFound(err, "category not found")
 	}
 	return &category, nil
 }
 
-// Delete удаляет категорию
-func (r *CategoryRepository) Delete(userID uint, id uint) error {
-	result := r.db.Where("user_id = ?", userID).Delete(&model.Category{}, id)
+// Delete перемещает категорию в корзину; транзакции сохраняют ссылку на нее до очистки
+func (r *CategoryRepository) Delete(ctx context.Context, userID, ledgerID uint, id uint) error {
+	result := r.db.WithContext(ctx).Scopes(inLedger(userID, ledgerID)).Delete(&model.Category{}, id)
+	if result.Error != nil {
+		return dbError(result.Error)
+	}
 	if result.RowsAffected == 0 {
 		return errors.New("category not found")
 	}
-	return result.Error
+	return nil
+}
+
+// GetTrashed возвращает категории бюджета в корзине
+func (r *CategoryRepository) GetTrashed(ctx context.Context, userID, ledgerID uint) ([]model.Category, error) {
+	var categories []model.Category
+	err := r.db.WithContext(ctx).Unscoped().
+		Scopes(inLedger(userID, ledgerID)).
+		Where("deleted_at IS NOT NULL").
+		Order("deleted_at DESC").
+		Find(&categories).Error
+	return categories, err
+}
+
+// Restore возвращает категорию из корзины
+func (r *CategoryRepository) Restore(ctx context.Context, userID, ledgerID, id uint) (*model.Category, error) {
+	result := r.db.WithContext(ctx).Unscoped().Model(&model.Category{}).
+		Scopes(inLedger(userID, ledgerID)).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.New("category not found in trash")
+	}
+	return r.GetByID(ctx, userID, ledgerID, id)
+}
+
+// PurgeDeleted окончательно удаляет категории, попавшие в корзину раньше before,
+// и снимает их с транзакций, правил, получателей, регулярных платежей и целей
+func (r *CategoryRepository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	var purged int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		expired := tx.Unscoped().Model(&model.Category{}).Select("id").Where("deleted_at < ?", before)
+
+		err := tx.Unscoped().Model(&model.Transaction{}).Where("category_id IN (?)", expired).Update("category_id", nil).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(&model.Rule{}).Where("set_category_id IN (?)", expired).Update("set_category_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&model.Payee{}).Where("default_category_id IN (?)", expired).Update("default_category_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&model.RecurringItem{}).Where("category_id IN (?)", expired).Update("category_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&model.Alert{}).Where("category_id IN (?)", expired).Update("category_id", nil).Error; err != nil {
+			return err
+		}
+		// Цель без категории продолжает копить сбережения всего бюджета
+		if err := tx.Model(&model.Goal{}).Where("category_id IN (?)", expired).Update("category_id", nil).Error; err != nil {
+			return err
+		}
+
+		result := tx.Unscoped().Where("deleted_at < ?", before).Delete(&model.Category{})
+		purged = result.RowsAffected
+		return result.Error
+	})
+	return purged, err
 }
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"time"
+)
+
+// dbTimeLayouts форматы, в которых SQLite возвращает время текстом
+var dbTimeLayouts = []string{
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02T15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999 -0700 MST",
+}
+
+// dbTime время из агрегатов (MAX, MIN): Postgres возвращает time.Time, а SQLite
+// теряет тип столбца и возвращает строку
+type dbTime struct {
+	time.Time
+}
+
+func (t *dbTime) Scan(value any) error {
+	switch v := value.(type) {
+	case nil:
+		t.Time = time.Time{}
+		return nil
+	case time.Time:
+		t.Time = v
+		return nil
+	case []byte:
+		return t.parse(string(v))
+	case string:
+		return t.parse(v)
+	default:
+		return fmt.Errorf("cannot scan %T into time", value)
+	}
+}
+
+func (t dbTime) Value() (driver.Value, error) {
+	return t.Time, nil
+}
+
+func (t *dbTime) parse(s string) error {
+	for _, layout := range dbTimeLayouts {
+		if parsed, err := time.Parse(layout, s); err == nil {
+			t.Time = parsed
+			return nil
+		}
+	}
+	return fmt.Errorf("cannot parse time %q", s)
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"finance-backend/internal/model"
+)
+
+type DuplicateRepository struct {
+	db *gorm.DB
+}
+
+func NewDuplicateRepository(db *gorm.DB) *DuplicateRepository {
+	return &DuplicateRepository{db: db}
+}
+
+// duplicateColumns поля, нужные для поиска дубликатов
+var duplicateColumns = []string{"id", "ledger_id", "amount", "type", "description", "payee_id", "date"}
+
+// GetScanRows возвращает транзакции бюджета за период, упорядоченные так,
+// чтобы возможные дубликаты шли рядом
+func (r *DuplicateRepository) GetScanRows(ctx context.Context, userID, ledgerID uint, from, to *time.Time) ([]model.Transaction, error) {
+	query := r.db.WithContext(ctx).Scopes(inLedger(userID, ledgerID)).Select(duplicateColumns)
+	if from != nil {
+		query = query.Where("date >= ?", from)
+	}
+	if to != nil {
+		query = query.Where("date <= ?", to)
+	}
+
+	var transactions []model.Transaction
+	err := query.Order("type, amount, date, id").Find(&transactions).Error
+	return transactions, err
+}
+
+// GetNear возвращает транзакции бюджета с той же суммой и типом в окне дат вокруг транзакции
+func (r *DuplicateRepository) GetNear(ctx context.Context, userID uint, transaction *model.Transaction, window time.Duration) ([]model.Transaction, error) {
+	var transactions []model.Transaction
+	err := r.db.WithContext(ctx).Scopes(inLedger(userID, transaction.LedgerID)).
+		Select(duplicateColumns).
+		Where("id <> ? AND type = ? AND amount = ? AND date BETWEEN ? AND ?",
+			transaction.ID, transaction.Type, transaction.Amount,
+			transaction.Date.Add(-window), transaction.Date.Add(window)).
+		Order("date, id").
+		Find(&transactions).Error
+	return transactions, err
+}
+
+// GetDismissed возвращает пары, отмеченные в бюджете как не дубликаты
+func (r *DuplicateRepository) GetDismissed(ctx context.Context, ledgerID uint) (map[[2]uint]bool, error) {
+	var dismissals []model.DuplicateDismissal
+	if err := r.db.WithContext(ctx).Where("ledger_id = ?", ledgerID).Find(&dismissals).Error; err != nil {
+		return nil, err
+	}
+
+	pairs := make(map[[2]uint]bool, len(dismissals))
+	for _, d := range dismissals {
+		pairs[[2]uint{d.TransactionID, d.OtherID}] = true
+	}
+	return pairs, nil
+}
+
+// Dismiss запоминает, что пара не является дубликатом
+func (r *DuplicateRepository) Dismiss(ctx context.Context, dismissal *model.DuplicateDismissal) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(dismissal).Error
+}
+
+// Merge сохраняет оставляемую транзакцию и переносит на нее теги и вложения
+// удаляемой, после чего перемещает удаляемую в корзину вместе с ее долями:
+// ошибочное объединение можно отменить восстановлением
+func (r *DuplicateRepository) Merge(ctx context.Context, keep *model.Transaction, removeID uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Save(keep).Error; err != nil {
+			return err
+		}
+
+		err := tx.Exec("INSERT INTO transaction_tags (transaction_id, tag_id) "+
+			"SELECT ?, tag_id FROM transaction_tags WHERE transaction_id = ? "+
+			"AND tag_id NOT IN (SELECT tag_id FROM transaction_tags WHERE transaction_id = ?)",
+			keep.ID, removeID, keep.ID).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(&model.Attachment{}).Where("transaction_id = ?", removeID).Update("transaction_id", keep.ID).Error; err != nil {
+			return err
+		}
+
+		return tx.Delete(&model.Transaction{}, removeID).Error
+	})
+}
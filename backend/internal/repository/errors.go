@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// ErrDatabase помечает сбои базы в отличие от ожидаемых ошибок вроде
+// «category not found»: обработчики отвечают на них 500 и пишут причину в лог
+var ErrDatabase = errors.New("database error")
+
+// ErrorsPlugin помечает ошибки всех запросов GORM как ErrDatabase. Отсутствие
+// записи не помечается: репозитории превращают его в ошибку «not found»
+type ErrorsPlugin struct{}
+
+func (ErrorsPlugin) Name() string {
+	return "finance:errors"
+}
+
+func (ErrorsPlugin) Initialize(db *gorm.DB) error {
+	tag := func(tx *gorm.DB) {
+		if tx.Error != nil && !errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+			tx.Error = dbError(tx.Error)
+		}
+	}
+
+	cb := db.Callback()
+	return errors.Join(
+		cb.Create().After("*").Register("finance:errors", tag),
+		cb.Query().After("*").Register("finance:errors", tag),
+		cb.Update().After("*").Register("finance:errors", tag),
+		cb.Delete().After("*").Register("finance:errors", tag),
+		cb.Row().After("*").Register("finance:errors", tag),
+		cb.Raw().After("*").Register("finance:errors", tag),
+	)
+}
+
+// notFound возвращает ошибку с сообщением msg, если записи нет, а иначе —
+// ошибку базы
+func notFound(err error, msg string) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.New(msg)
+	}
+	return dbError(err)
+}
+
+// dbError оборачивает ошибку базы, сохраняя ее для errors.Is и errors.As
+func dbError(err error) error {
+	if errors.Is(err, ErrDatabase) {
+		return err
+	}
+	return fmt.Errorf("%w: %w", ErrDatabase, err)
+}
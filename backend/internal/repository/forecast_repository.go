@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"finance-backend/internal/model"
+)
+
+type ForecastRepository struct {
+	db *gorm.DB
+}
+
+func NewForecastRepository(db *gorm.DB) *ForecastRepository {
+	return &ForecastRepository{db: db}
+}
+
+// forecastColumns поля, нужные для прогноза
+var forecastColumns = []string{"id", "category_id", "payee_id", "amount", "type", "description", "date"}
+
+// GetBalance возвращает остаток бюджета на момент at: доходы за вычетом расходов
+func (r *ForecastRepository) GetBalance(ctx context.Context, userID, ledgerID uint, at time.Time) (float64, error) {
+	var balance float64
+	err := r.db.WithContext(ctx).Model(&model.Transaction{}).
+		Scopes(inLedger(userID, ledgerID)).
+		Where("date <= ?", at).
+		Select("COALESCE(SUM(CASE WHEN type = 'income' THEN amount ELSE -amount END), 0)").
+		Scan(&balance).Error
+	return balance, err
+}
+
+// GetBetween возвращает транзакции бюджета в полуинтервале (from, to]
+func (r *ForecastRepository) GetBetween(ctx context.Context, userID, ledgerID uint, from, to time.Time) ([]model.Transaction, error) {
+	var transactions []model.Transaction
+	err := r.db.WithContext(ctx).Scopes(inLedger(userID, ledgerID)).
+		Select(forecastColumns).
+		Where("date > ? AND date <= ?", from, to).
+		Order("date, id").
+		Find(&transactions).Error
+	return transactions, err
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"finance-backend/internal/model"
+)
+
+type GoalRepository struct {
+	db *gorm.DB
+}
+
+func NewGoalRepository(db *gorm.DB) *GoalRepository {
+	return &GoalRepository{db: db}
+}
+
+// Create создает цель
+func (r *GoalRepository) Create(ctx context.Context, goal *model.Goal) error {
+	return r.db.WithContext(ctx).Create(goal).Error
+}
+
+// Update сохраняет цель
+func (r *GoalRepository) Update(ctx context.Context, goal *model.Goal) error {
+	return r.db.WithContext(ctx).Save(goal).Error
+}
+
+// GetByLedger возвращает цели бюджета
+func (r *GoalRepository) GetByLedger(ctx context.Context, userID, ledgerID uint) ([]model.Goal, error) {
+	var goals []model.Goal
+	err := r.db.WithContext(ctx).Scopes(inLedger(userID, ledgerID)).Order("deadline IS NULL, deadline, id").Find(&goals).Error
+	return goals, err
+}
+
+// GetByID возвращает цель бюджета по ID
+func (r *GoalRepository) GetByID(ctx context.Context, userID, ledgerID, id uint) (*model.Goal, error) {
+	var goal model.Goal
+	err := r.db.WithContext(ctx).Scopes(inLedger(userID, ledgerID)).Where("id = ?", id).First(&goal).Error
+	if err != nil {
+		return nil, notFound(err, "goal not found")
+	}
+	return &goal, nil
+}
+
+// Delete удаляет цель
+func (r *GoalRepository) Delete(ctx context.Context, userID, ledgerID, id uint) error {
+	result := r.db.WithContext(ctx).Scopes(inLedger(userID, ledgerID)).Delete(&model.Goal{}, id)
+	if result.RowsAffected == 0 {
+		return errors.New("goal not found")
+	}
+	return result.Error
+}
+
+// GetContributions суммирует взносы в цель по транзакциям начиная с from
+func (r *GoalRepository) GetContributions(ctx context.Context, goal *model.Goal, from time.Time) (float64, error) {
+	query := r.db.WithContext(ctx).Model(&model.Transaction{}).Where("ledger_id = ? AND date >= ?", goal.LedgerID, from)
+	if goal.CategoryID != nil {
+		query = query.Where("category_id = ?", *goal.CategoryID).Select("COALESCE(SUM(amount), 0)")
+	} else {
+		query = query.Select("COALESCE(SUM(CASE WHEN type = 'income' THEN amount ELSE -amount END), 0)")
+	}
+
+	var total float64
+	err := query.Scan(&total).Error
+	return total, err
+}
@@ -0,0 +1,220 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"finance-backend/internal/model"
+)
+
+type LedgerRepository struct {
+	db *gorm.DB
+}
+
+func NewLedgerRepository(db *gorm.DB) *LedgerRepository {
+	return &LedgerRepository{db: db}
+}
+
+// inLedger ограничивает выборку бюджетом, участником которого является пользователь
+func inLedger(userID, ledgerID uint) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("ledger_id = ? AND EXISTS (SELECT 1 FROM ledger_members WHERE ledger_members.ledger_id = ? AND ledger_members.user_id = ?)",
+			ledgerID, ledgerID, userID)
+	}
+}
+
+// Create создает бюджет и делает создателя его владельцем
+func (r *LedgerRepository) Create(ctx context.Context, ledger *model.Ledger) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return createLedger(tx, ledger)
+	})
+}
+
+// createLedger создает бюджет вместе с записью о владельце
+func createLedger(tx *gorm.DB, ledger *model.Ledger) error {
+	if err := tx.Create(ledger).Error; err != nil {
+		return err
+	}
+	return tx.Create(&model.LedgerMember{
+		LedgerID: ledger.ID,
+		UserID:   ledger.OwnerID,
+		Role:     model.RoleOwner,
+	}).Error
+}
+
+// Update сохраняет изменения бюджета
+func (r *LedgerRepository) Update(ctx context.Context, ledger *model.Ledger) error {
+	return r.db.WithContext(ctx).Save(ledger).Error
+}
+
+// GetByID возвращает бюджет, если пользователь состоит в нем
+func (r *LedgerRepository) GetByID(ctx context.Context, userID uint, id uint) (*model.Ledger, error) {
+	var ledger model.Ledger
+	err := r.db.WithContext(ctx).
+		Where("id = ? AND EXISTS (SELECT 1 FROM ledger_members WHERE ledger_members.ledger_id = ledgers.id AND ledger_members.user_id = ?)", id, userID).
+		First(&ledger).Error
+	if err != nil {
+		return nil, notFound(err, "ledger not found")
+	}
+	return &ledger, nil
+}
+
+// GetPersonal возвращает личный бюджет пользователя
+func (r *LedgerRepository) GetPersonal(ctx context.Context, userID uint) (*model.Ledger, error) {
+	var ledger model.Ledger
+	err := r.db.WithContext(ctx).Where("owner_id = ? AND personal = ?", userID, true).First(&ledger).Error
+	if err != nil {
+		return nil, notFound(err, "ledger not found")
+	}
+	return &ledger, nil
+}
+
+// GetMemberships возвращает участие пользователя во всех бюджетах
+func (r *LedgerRepository) GetMemberships(ctx context.Context, userID uint) ([]model.LedgerMember, error) {
+	var members []model.LedgerMember
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Preload("Ledger").Order("ledger_id").Find(&members).Error
+	return members, err
+}
+
+// GetMember возвращает участника бюджета
+func (r *LedgerRepository) GetMember(ctx context.Context, ledgerID, userID uint) (*model.LedgerMember, error) {
+	var member model.LedgerMember
+	err := r.db.WithContext(ctx).Where("ledger_id = ? AND user_id = ?", ledgerID, userID).First(&member).Error
+	if err != nil {
+		return nil, notFound(err, "member not found")
+	}
+	return &member, nil
+}
+
+// GetMembers возвращает всех участников бюджета
+func (r *LedgerRepository) GetMembers(ctx context.Context, ledgerID uint) ([]model.LedgerMember, error) {
+	var members []model.LedgerMember
+	err := r.db.WithContext(ctx).Where("ledger_id = ?", ledgerID).Preload("User").Order("id").Find(&members).Error
+	return members, err
+}
+
+// AddMember добавляет участника в бюджет
+func (r *LedgerRepository) AddMember(ctx context.Context, member *model.LedgerMember) error {
+	return r.db.WithContext(ctx).Create(member).Error
+}
+
+// UpdateMember сохраняет изменения участника
+func (r *LedgerRepository) UpdateMember(ctx context.Context, member *model.LedgerMember) error {
+	return r.db.WithContext(ctx).Save(member).Error
+}
+
+// RemoveMember исключает участника из бюджета
+func (r *LedgerRepository) RemoveMember(ctx context.Context, ledgerID, userID uint) error {
+	result := r.db.WithContext(ctx).Where("ledger_id = ? AND user_id = ?", ledgerID, userID).Delete(&model.LedgerMember{})
+	if result.Error != nil {
+		return dbError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("member not found")
+	}
+	return nil
+}
+
+// Delete удаляет бюджет вместе с его данными
+func (r *LedgerRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return deleteLedgers(tx, []uint{id})
+	})
+}
+
+// deleteLedgers удаляет бюджеты и все принадлежащие им данные
+func deleteLedgers(tx *gorm.DB, ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	// Unscoped: вместе с бюджетом удаляется и содержимое корзины
+	ledgerTransactions := tx.Unscoped().Model(&model.Transaction{}).Select("id").Where("ledger_id IN ?", ids)
+	err := tx.Where("transaction_id IN (?)", ledgerTransactions).Delete(&model.TransactionSplit{}).Error
+	if err != nil {
+		return err
+	}
+	if err := tx.Exec("DELETE FROM transaction_tags WHERE transaction_id IN (?)", ledgerTransactions).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("ledger_id IN ?", ids).Delete(&model.Settlement{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("ledger_id IN ?", ids).Delete(&model.DuplicateDismissal{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("ledger_id IN ?", ids).Delete(&model.Goal{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("ledger_id IN ?", ids).Delete(&model.RecurringItem{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("ledger_id IN ?", ids).Delete(&model.Alert{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Unscoped().Where("ledger_id IN ?", ids).Delete(&model.Transaction{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Unscoped().Where("ledger_id IN ?", ids).Delete(&model.Category{}).Error; err != nil {
+		return err
+	}
+	ledgerPayees := tx.Model(&model.Payee{}).Select("id").Where("ledger_id IN ?", ids)
+	if err := tx.Where("payee_id IN (?)", ledgerPayees).Delete(&model.PayeeAlias{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("ledger_id IN ?", ids).Delete(&model.Payee{}).Error; err != nil {
+		return err
+	}
+	// Правила, привязанные к бюджету, без него не имеют смысла
+	ledgerRules := tx.Model(&model.Rule{}).Select("id").Where("ledger_id IN ?", ids)
+	if err := tx.Exec("DELETE FROM rule_tags WHERE rule_id IN (?)", ledgerRules).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("ledger_id IN ?", ids).Delete(&model.Rule{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("ledger_id IN ?", ids).Delete(&model.LedgerInvitation{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("ledger_id IN ?", ids).Delete(&model.LedgerMember{}).Error; err != nil {
+		return err
+	}
+	return tx.Delete(&model.Ledger{}, ids).Error
+}
+
+// CreateInvitation сохраняет приглашение в бюджет
+func (r *LedgerRepository) CreateInvitation(ctx context.Context, invitation *model.LedgerInvitation) error {
+	return r.db.WithContext(ctx).Create(invitation).Error
+}
+
+// UpdateInvitation сохраняет изменения приглашения
+func (r *LedgerRepository) UpdateInvitation(ctx context.Context, invitation *model.LedgerInvitation) error {
+	return r.db.WithContext(ctx).Save(invitation).Error
+}
+
+// GetInvitation возвращает приглашение по ID
+func (r *LedgerRepository) GetInvitation(ctx context.Context, id uint) (*model.LedgerInvitation, error) {
+	var invitation model.LedgerInvitation
+	err := r.db.WithContext(ctx).Preload("Ledger").First(&invitation, id).Error
+	if err != nil {
+		return nil, notFound(err, "invitation not found")
+	}
+	return &invitation, nil
+}
+
+// GetLedgerInvitations возвращает приглашения в бюджет
+func (r *LedgerRepository) GetLedgerInvitations(ctx context.Context, ledgerID uint) ([]model.LedgerInvitation, error) {
+	var invitations []model.LedgerInvitation
+	err := r.db.WithContext(ctx).Where("ledger_id = ?", ledgerID).Preload("Ledger").Order("created_at DESC").Find(&invitations).Error
+	return invitations, err
+}
+
+// GetPendingInvitations возвращает действующие приглашения для email
+func (r *LedgerRepository) GetPendingInvitations(ctx context.Context, email string, now time.Time) ([]model.LedgerInvitation, error) {
+	var invitations []model.LedgerInvitation
+	err := r.db.WithContext(ctx).Where("email = ? AND status = ? AND expires_at > ?", email, model.InvitationPending, now).
+		Preload("Ledger").Order("created_at DESC").Find(&invitations).Error
+	return invitations, err
+}
@@ -0,0 +1,95 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"finance-backend/internal/model"
+)
+
+type CategoryRepository struct {
+	store *Store
+}
+
+func NewCategoryRepository(store *Store) *CategoryRepository {
+	return &CategoryRepository{store: store}
+}
+
+// Create создает новую категорию
+func (r *CategoryRepository) Create(ctx context.Context, category *model.Category) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if category.Color == "" {
+		category.Color = "#6B7280"
+	}
+	category.ID = s.nextID("categories")
+	touch(&category.CreatedAt, &category.UpdatedAt)
+	s.categories[category.ID] = cloneCategory(category)
+	return nil
+}
+
+// GetByLedger возвращает все категории бюджета
+func (r *CategoryRepository) GetByLedger(ctx context.Context, userID, ledgerID uint) ([]model.Category, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var categories []model.Category
+	if !s.isMember(userID, ledgerID) {
+		return categories, nil
+	}
+	for _, id := range sortedKeys(s.categories) {
+		c := s.categories[id]
+		if c.LedgerID == ledgerID && !deleted(c.DeletedAt) {
+			categories = append(categories, *cloneCategory(c))
+		}
+	}
+	return categories, nil
+}
+
+// GetByID возвращает категорию по ID с проверкой доступа к бюджету
+func (r *CategoryRepository) GetByID(ctx context.Context, userID, ledgerID uint, id uint) (*model.Category, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.visibleCategory(userID, ledgerID, id)
+	if !ok {
+		return nil, errors.New("category not found")
+	}
+	return cloneCategory(c), nil
+}
+
+// Delete перемещает категорию в корзину; транзакции сохраняют ссылку на нее
+func (r *CategoryRepository) Delete(ctx context.Context, userID, ledgerID uint, id uint) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.visibleCategory(userID, ledgerID, id)
+	if !ok {
+		return errors.New("category not found")
+	}
+	c.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	return nil
+}
+
+// visibleCategory категория бюджета, доступная пользователю и не в корзине
+func (s *Store) visibleCategory(userID, ledgerID, id uint) (*model.Category, bool) {
+	c, ok := s.categories[id]
+	if !ok || c.LedgerID != ledgerID || deleted(c.DeletedAt) || !s.isMember(userID, ledgerID) {
+		return nil, false
+	}
+	return c, true
+}
+
+func cloneCategory(c *model.Category) *model.Category {
+	clone := *c
+	clone.Transactions = nil
+	return &clone
+}
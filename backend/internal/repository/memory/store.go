@@ -0,0 +1,118 @@
+// Package memory реализует хранилища сервисов в памяти, повторяя поведение
+// GORM-репозиториев: доступ только участникам бюджета, мягкое удаление и те же
+// ошибки «not found». Предназначено для тестов сервисов и обработчиков
+package memory
+
+import (
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"finance-backend/internal/model"
+	"finance-backend/internal/service"
+)
+
+var (
+	_ service.TransactionRepository = (*TransactionRepository)(nil)
+	_ service.CategoryRepository    = (*CategoryRepository)(nil)
+	_ service.TagRepository         = (*TagRepository)(nil)
+	_ service.UserRepository        = (*UserRepository)(nil)
+)
+
+// Store общие данные репозиториев: транзакции видят категории и теги так же,
+// как связанные таблицы в базе
+type Store struct {
+	mu sync.Mutex
+
+	lastID map[string]uint
+
+	users        map[uint]*model.User
+	categories   map[uint]*model.Category
+	tags         map[uint]*model.Tag
+	transactions map[uint]*model.Transaction
+
+	transactionTags map[uint]map[uint]bool   // транзакция -> теги
+	members         map[uint]map[uint]string // бюджет -> участник -> роль
+}
+
+func NewStore() *Store {
+	return &Store{
+		lastID:          make(map[string]uint),
+		users:           make(map[uint]*model.User),
+		categories:      make(map[uint]*model.Category),
+		tags:            make(map[uint]*model.Tag),
+		transactions:    make(map[uint]*model.Transaction),
+		transactionTags: make(map[uint]map[uint]bool),
+		members:         make(map[uint]map[uint]string),
+	}
+}
+
+// AddLedgerMember дает пользователю доступ к бюджету с ролью role. Бюджеты в
+// памяти не хранятся, достаточно списка участников
+func (s *Store) AddLedgerMember(ledgerID, userID uint, role string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.addMember(ledgerID, userID, role)
+}
+
+func (s *Store) addMember(ledgerID, userID uint, role string) {
+	if s.members[ledgerID] == nil {
+		s.members[ledgerID] = make(map[uint]string)
+	}
+	s.members[ledgerID][userID] = role
+}
+
+// LedgerRole возвращает роль пользователя в бюджете, "" — не участник
+func (s *Store) LedgerRole(ledgerID, userID uint) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.members[ledgerID][userID]
+}
+
+// RemoveLedgerMember отзывает доступ к бюджету
+func (s *Store) RemoveLedgerMember(ledgerID, userID uint) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.members[ledgerID], userID)
+}
+
+// nextID выдает ID по порядку, отдельно для каждой таблицы
+func (s *Store) nextID(table string) uint {
+	s.lastID[table]++
+	return s.lastID[table]
+}
+
+// isMember аналог области inLedger
+func (s *Store) isMember(userID, ledgerID uint) bool {
+	return s.members[ledgerID][userID] != ""
+}
+
+// touch проставляет время создания и изменения, как GORM при сохранении
+func touch(createdAt, updatedAt *time.Time) {
+	now := time.Now()
+	if createdAt.IsZero() {
+		*createdAt = now
+	}
+	*updatedAt = now
+}
+
+func deleted(at gorm.DeletedAt) bool {
+	return at.Valid
+}
+
+// uniqueIDs убирает повторы, сохраняя порядок
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	result := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			result = append(result, id)
+		}
+	}
+	return result
+}
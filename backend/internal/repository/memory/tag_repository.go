@@ -0,0 +1,96 @@
+package memory
+
+import (
+	"context"
+	"errors"
+
+	"finance-backend/internal/model"
+)
+
+type TagRepository struct {
+	store *Store
+}
+
+func NewTagRepository(store *Store) *TagRepository {
+	return &TagRepository{store: store}
+}
+
+// Create создает тег; имя уникально в пределах пользователя
+func (r *TagRepository) Create(ctx context.Context, tag *model.Tag) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, t := range s.tags {
+		if t.UserID == tag.UserID && t.Name == tag.Name {
+			return errors.New("duplicate tag name")
+		}
+	}
+
+	if tag.Color == "" {
+		tag.Color = "#6B7280"
+	}
+	tag.ID = s.nextID("tags")
+	touch(&tag.CreatedAt, &tag.UpdatedAt)
+	clone := *tag
+	s.tags[tag.ID] = &clone
+	return nil
+}
+
+// GetByIDs возвращает теги пользователя; ошибка, если какой-то тег не найден
+func (r *TagRepository) GetByIDs(ctx context.Context, userID uint, ids []uint) ([]model.Tag, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var tags []model.Tag
+	for _, id := range uniqueIDs(ids) {
+		t, ok := s.tags[id]
+		if !ok || t.UserID != userID {
+			return nil, errors.New("tag not found")
+		}
+		tags = append(tags, *t)
+	}
+	return tags, nil
+}
+
+// SetTransactionTags заменяет теги пользователя на транзакции, не трогая теги других участников
+func (r *TagRepository) SetTransactionTags(ctx context.Context, userID, transactionID uint, tagIDs []uint) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.setTransactionTags(userID, transactionID, tagIDs)
+}
+
+// setTransactionTags заменяет теги пользователя на транзакции; при неизвестном
+// теге ничего не меняет. Вызывается под s.mu
+func (s *Store) setTransactionTags(userID, transactionID uint, tagIDs []uint) error {
+	tagIDs = uniqueIDs(tagIDs)
+	for _, tagID := range tagIDs {
+		if _, ok := s.tags[tagID]; !ok {
+			return errors.New("tag not found")
+		}
+	}
+
+	for tagID := range s.transactionTags[transactionID] {
+		if t, ok := s.tags[tagID]; ok && t.UserID == userID {
+			delete(s.transactionTags[transactionID], tagID)
+		}
+	}
+	for _, tagID := range tagIDs {
+		s.linkTag(transactionID, tagID)
+	}
+	return nil
+}
+
+func (s *Store) linkTag(transactionID, tagID uint) {
+	if s.transactionTags[transactionID] == nil {
+		s.transactionTags[transactionID] = make(map[uint]bool)
+	}
+	s.transactionTags[transactionID][tagID] = true
+}
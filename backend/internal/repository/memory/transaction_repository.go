@@ -0,0 +1,214 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+
+	"finance-backend/internal/dto"
+	"finance-backend/internal/model"
+)
+
+type TransactionRepository struct {
+	store *Store
+}
+
+func NewTransactionRepository(store *Store) *TransactionRepository {
+	return &TransactionRepository{store: store}
+}
+
+// Create создает новую транзакцию; теги из transaction.Tags привязываются к ней,
+// как при сохранении связи many2many
+func (r *TransactionRepository) Create(ctx context.Context, transaction *model.Transaction) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	transaction.ID = s.nextID("transactions")
+	touch(&transaction.CreatedAt, &transaction.UpdatedAt)
+	s.transactions[transaction.ID] = cloneTransaction(transaction)
+	for _, tag := range transaction.Tags {
+		s.linkTag(transaction.ID, tag.ID)
+	}
+	return nil
+}
+
+// GetByID возвращает транзакцию по ID с проверкой доступа к бюджету, без связанных записей
+func (r *TransactionRepository) GetByID(ctx context.Context, userID, ledgerID uint, id uint) (*model.Transaction, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.visibleTransaction(userID, ledgerID, id)
+	if !ok {
+		return nil, errors.New("transaction not found")
+	}
+	return cloneTransaction(t), nil
+}
+
+// GetByLedger возвращает транзакции бюджета; теги подгружаются только пользовательские.
+// Получатели и доли в памяти не хранятся, поэтому Payee и Splits всегда пустые
+func (r *TransactionRepository) GetByLedger(ctx context.Context, userID, ledgerID uint, filter dto.TransactionFilter) ([]model.Transaction, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var transactions []model.Transaction
+	if !s.isMember(userID, ledgerID) {
+		return transactions, nil
+	}
+
+	tagIDs := uniqueIDs(filter.TagIDs)
+	for _, id := range sortedKeys(s.transactions) {
+		t := s.transactions[id]
+		if t.LedgerID != ledgerID || deleted(t.DeletedAt) || !inPeriod(t.Date, filter.From, filter.To) {
+			continue
+		}
+		if len(tagIDs) > 0 && !s.matchTags(t.ID, tagIDs, filter.MatchAllTags) {
+			continue
+		}
+		transactions = append(transactions, s.withAssociations(userID, t))
+	}
+
+	slices.SortStableFunc(transactions, func(a, b model.Transaction) int {
+		return b.Date.Compare(a.Date)
+	})
+	return transactions, nil
+}
+
+// GetFinancialSummary возвращает финансовую сводку
+func (r *TransactionRepository) GetFinancialSummary(ctx context.Context, userID, ledgerID uint, from, to *time.Time) (*dto.FinancialSummary, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var summary dto.FinancialSummary
+	if !s.isMember(userID, ledgerID) {
+		return &summary, nil
+	}
+	for _, t := range s.transactions {
+		if t.LedgerID != ledgerID || deleted(t.DeletedAt) || !inPeriod(t.Date, from, to) {
+			continue
+		}
+		switch t.Type {
+		case "income":
+			summary.TotalIncome += t.Amount
+		case "expense":
+			summary.TotalExpense += t.Amount
+		}
+	}
+
+	summary.Balance = summary.TotalIncome - summary.TotalExpense
+	return &summary, nil
+}
+
+// Update обновляет поля транзакции без связанных записей
+func (r *TransactionRepository) Update(ctx context.Context, transaction *model.Transaction) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if transaction.ID == 0 {
+		transaction.ID = s.nextID("transactions")
+	}
+	touch(&transaction.CreatedAt, &transaction.UpdatedAt)
+	s.transactions[transaction.ID] = cloneTransaction(transaction)
+	return nil
+}
+
+// UpdateWithTags обновляет транзакцию и ее теги; при ошибке не меняет ничего
+func (r *TransactionRepository) UpdateWithTags(ctx context.Context, userID uint, transaction *model.Transaction, tagIDs []uint) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, tagID := range tagIDs {
+		if _, ok := s.tags[tagID]; !ok {
+			return errors.New("tag not found")
+		}
+	}
+	touch(&transaction.CreatedAt, &transaction.UpdatedAt)
+	s.transactions[transaction.ID] = cloneTransaction(transaction)
+	return s.setTransactionTags(userID, transaction.ID, tagIDs)
+}
+
+// Delete перемещает транзакцию в корзину
+func (r *TransactionRepository) Delete(ctx context.Context, userID, ledgerID uint, id uint) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.visibleTransaction(userID, ledgerID, id)
+	if !ok {
+		return errors.New("transaction not found")
+	}
+	t.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	return nil
+}
+
+// visibleTransaction транзакция бюджета, доступная пользователю и не в корзине
+func (s *Store) visibleTransaction(userID, ledgerID, id uint) (*model.Transaction, bool) {
+	t, ok := s.transactions[id]
+	if !ok || t.LedgerID != ledgerID || deleted(t.DeletedAt) || !s.isMember(userID, ledgerID) {
+		return nil, false
+	}
+	return t, true
+}
+
+// matchTags проверяет, что на транзакции есть все теги (matchAll) или хотя бы один
+func (s *Store) matchTags(transactionID uint, tagIDs []uint, matchAll bool) bool {
+	linked := s.transactionTags[transactionID]
+	if matchAll {
+		return !slices.ContainsFunc(tagIDs, func(id uint) bool { return !linked[id] })
+	}
+	return slices.ContainsFunc(tagIDs, func(id uint) bool { return linked[id] })
+}
+
+// withAssociations копия транзакции с категорией (если она не в корзине)
+// и тегами пользователя, как при Preload
+func (s *Store) withAssociations(userID uint, t *model.Transaction) model.Transaction {
+	result := *cloneTransaction(t)
+	if t.CategoryID != nil {
+		if c, ok := s.categories[*t.CategoryID]; ok && !deleted(c.DeletedAt) {
+			result.Category = cloneCategory(c)
+		}
+	}
+	for _, tagID := range sortedKeys(s.transactionTags[t.ID]) {
+		if tag, ok := s.tags[tagID]; ok && tag.UserID == userID {
+			result.Tags = append(result.Tags, *tag)
+		}
+	}
+	return result
+}
+
+func inPeriod(date time.Time, from, to *time.Time) bool {
+	if from != nil && date.Before(*from) {
+		return false
+	}
+	if to != nil && date.After(*to) {
+		return false
+	}
+	return true
+}
+
+// cloneTransaction копирует транзакцию без связанных записей
+func cloneTransaction(t *model.Transaction) *model.Transaction {
+	c := *t
+	c.User = model.User{}
+	c.Category = nil
+	c.Payee = nil
+	c.Splits = nil
+	c.Tags = nil
+	c.PossibleDuplicates = nil
+	return &c
+}
+
+// sortedKeys ключи по возрастанию — порядок вставки, как у автоинкрементных ID
+func sortedKeys[K cmp.Ordered, V any](m map[K]V) []K {
+	return slices.Sorted(maps.Keys(m))
+}
@@ -0,0 +1,111 @@
+package memory
+
+import (
+	"context"
+	"errors"
+
+	"finance-backend/internal/model"
+)
+
+type UserRepository struct {
+	store *Store
+}
+
+func NewUserRepository(store *Store) *UserRepository {
+	return &UserRepository{store: store}
+}
+
+// CreateWithPersonalLedger создает нового пользователя с личным бюджетом; email
+// уникален, как в индексе таблицы. Личный бюджет получает ID пользователя
+func (r *UserRepository) CreateWithPersonalLedger(ctx context.Context, user *model.User, ledger *model.Ledger) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, u := range s.users {
+		if u.Email == user.Email {
+			return errors.New("duplicate email")
+		}
+	}
+
+	user.ID = s.nextID("users")
+	touch(&user.CreatedAt, &user.UpdatedAt)
+	s.users[user.ID] = cloneUser(user)
+
+	ledger.ID = user.ID
+	ledger.OwnerID = user.ID
+	ledger.Personal = true
+	touch(&ledger.CreatedAt, &ledger.UpdatedAt)
+	s.addMember(ledger.ID, user.ID, model.RoleOwner)
+	return nil
+}
+
+// GetByEmail возвращает пользователя по email
+func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*model.User, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, u := range s.users {
+		if u.Email == email && !deleted(u.DeletedAt) {
+			return cloneUser(u), nil
+		}
+	}
+	return nil, errors.New("user not found")
+}
+
+// GetByID возвращает пользователя по ID
+func (r *UserRepository) GetByID(ctx context.Context, id uint) (*model.User, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.users[id]
+	if !ok || deleted(u.DeletedAt) {
+		return nil, errors.New("user not found")
+	}
+	return cloneUser(u), nil
+}
+
+// Update сохраняет изменения пользователя
+func (r *UserRepository) Update(ctx context.Context, user *model.User) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, u := range s.users {
+		if u.ID != user.ID && u.Email == user.Email {
+			return errors.New("duplicate email")
+		}
+	}
+
+	if user.ID == 0 {
+		user.ID = s.nextID("users")
+	}
+	touch(&user.CreatedAt, &user.UpdatedAt)
+	s.users[user.ID] = cloneUser(user)
+	return nil
+}
+
+// EmailExists проверяет существование email
+func (r *UserRepository) EmailExists(ctx context.Context, email string) bool {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, u := range s.users {
+		if u.Email == email && !deleted(u.DeletedAt) {
+			return true
+		}
+	}
+	return false
+}
+
+// cloneUser копирует пользователя без связанных записей, чтобы вызывающий код
+// не менял хранилище в обход репозитория
+func cloneUser(u *model.User) *model.User {
+	c := *u
+	c.Categories = nil
+	c.Transactions = nil
+	return &c
+}
@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"finance-backend/internal/model"
+)
+
+type PayeeRepository struct {
+	db *gorm.DB
+}
+
+func NewPayeeRepository(db *gorm.DB) *PayeeRepository {
+	return &PayeeRepository{db: db}
+}
+
+// PayeeTotals суммы транзакций получателя
+type PayeeTotals struct {
+	PayeeID      uint
+	Name         string
+	TotalIncome  float64
+	TotalExpense float64
+	Count        int64
+	LastDate     time.Time
+}
+
+// Create создает получателя вместе с псевдонимами
+func (r *PayeeRepository) Create(ctx context.Context, payee *model.Payee) error {
+	return r.db.WithContext(ctx).Create(payee).Error
+}
+
+// Update сохраняет получателя и заменяет его псевдонимы
+func (r *PayeeRepository) Update(ctx context.Context, payee *model.Payee) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Save(payee).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("payee_id = ?", payee.ID).Delete(&model.PayeeAlias{}).Error; err != nil {
+			return err
+		}
+		for i := range payee.Aliases {
+			payee.Aliases[i].ID = 0
+			payee.Aliases[i].PayeeID = payee.ID
+		}
+		if len(payee.Aliases) == 0 {
+			return nil
+		}
+		return tx.Create(&payee.Aliases).Error
+	})
+}
+
+// GetByLedger возвращает получателей бюджета с псевдонимами
+func (r *PayeeRepository) GetByLedger(ctx context.Context, userID, ledgerID uint) ([]model.Payee, error) {
+	var payees []model.Payee
+	err := r.db.WithContext(ctx).Scopes(inLedger(userID, ledgerID)).Preload("Aliases").Order("name").Find(&payees).Error
+	return payees, err
+}
+
+// GetByID возвращает получателя бюджета по ID
+func (r *PayeeRepository) GetByID(ctx context.Context, userID, ledgerID, id uint) (*model.Payee, error) {
+	var payee model.Payee
+	err := r.db.WithContext(ctx).Scopes(inLedger(userID, ledgerID)).Preload("Aliases").Where("id = ?", id).First(&payee).Error
+	if err != nil {
+		return nil, notFound(err, "payee not found")
+	}
+	return &payee, nil
+}
+
+// NameExists проверяет, есть ли в бюджете получатель с таким именем
+func (r *PayeeRepository) NameExists(ctx context.Context, ledgerID uint, name string, exceptID uint) bool {
+	var count int64
+	r.db.WithContext(ctx).Model(&model.Payee{}).Where("ledger_id = ? AND name = ? AND id <> ?", ledgerID, name, exceptID).Count(&count)
+	return count > 0
+}
+
+// Delete удаляет получателя; транзакции остаются без получателя
+func (r *PayeeRepository) Delete(ctx context.Context, userID, ledgerID, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&model.Payee{}).Scopes(inLedger(userID, ledgerID)).Where("id = ?", id).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return errors.New("payee not found")
+		}
+		if err := tx.Unscoped().Model(&model.Transaction{}).Where("payee_id = ?", id).Update("payee_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&model.RecurringItem{}).Where("payee_id = ?", id).Update("payee_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&model.Alert{}).Where("payee_id = ?", id).Update("payee_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("payee_id = ?", id).Delete(&model.PayeeAlias{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.Payee{}, id).Error
+	})
+}
+
+// GetTop возвращает получателей бюджета с наибольшими расходами за период
+func (r *PayeeRepository) GetTop(ctx context.Context, userID, ledgerID uint, from, to *time.Time, limit int) ([]PayeeTotals, error) {
+	query := r.db.WithContext(ctx).Model(&model.Payee{}).
+		Select("payees.id AS payee_id, payees.name, "+
+			"COALESCE(SUM(CASE WHEN transactions.type = 'income' THEN transactions.amount ELSE 0 END), 0) AS total_income, "+
+			"COALESCE(SUM(CASE WHEN transactions.type = 'expense' THEN transactions.amount ELSE 0 END), 0) AS total_expense, "+
+			"COUNT(transactions.id) AS count, MAX(transactions.date) AS last_date").
+		Joins("JOIN transactions ON transactions.payee_id = payees.id AND transactions.deleted_at IS NULL").
+		Where("payees.ledger_id = ? AND EXISTS (SELECT 1 FROM ledger_members WHERE ledger_members.ledger_id = ? AND ledger_members.user_id = ?)",
+			ledgerID, ledgerID, userID)
+
+	if from != nil {
+		query = query.Where("transactions.date >= ?", from)
+	}
+	if to != nil {
+		query = query.Where("transactions.date <= ?", to)
+	}
+
+	var rows []struct {
+		PayeeID      uint
+		Name         string
+		TotalIncome  float64
+		TotalExpense float64
+		Count        int64
+		LastDate     dbTime
+	}
+	err := query.
+		Group("payees.id, payees.name").
+		Having("SUM(CASE WHEN transactions.type = 'expense' THEN transactions.amount ELSE 0 END) > 0").
+		Order("total_expense DESC").
+		Limit(limit).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	totals := make([]PayeeTotals, len(rows))
+	for i, row := range rows {
+		totals[i] = PayeeTotals{
+			PayeeID:      row.PayeeID,
+			Name:         row.Name,
+			TotalIncome:  row.TotalIncome,
+			TotalExpense: row.TotalExpense,
+			Count:        row.Count,
+			LastDate:     row.LastDate.Time,
+		}
+	}
+	return totals, nil
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"finance-backend/internal/model"
+)
+
+type RecurringRepository struct {
+	db *gorm.DB
+}
+
+func NewRecurringRepository(db *gorm.DB) *RecurringRepository {
+	return &RecurringRepository{db: db}
+}
+
+// Create создает регулярный платеж
+func (r *RecurringRepository) Create(ctx context.Context, item *model.RecurringItem) error {
+	return r.db.WithContext(ctx).Create(item).Error
+}
+
+// Update сохраняет регулярный платеж
+func (r *RecurringRepository) Update(ctx context.Context, item *model.RecurringItem) error {
+	return r.db.WithContext(ctx).Save(item).Error
+}
+
+// GetByLedger возвращает регулярные платежи бюджета
+func (r *RecurringRepository) GetByLedger(ctx context.Context, userID, ledgerID uint) ([]model.RecurringItem, error) {
+	var items []model.RecurringItem
+	err := r.db.WithContext(ctx).Scopes(inLedger(userID, ledgerID)).Order("next_date, id").Find(&items).Error
+	return items, err
+}
+
+// GetByID возвращает регулярный платеж бюджета по ID
+func (r *RecurringRepository) GetByID(ctx context.Context, userID, ledgerID, id uint) (*model.RecurringItem, error) {
+	var item model.RecurringItem
+	err := r.db.WithContext(ctx).Scopes(inLedger(userID, ledgerID)).Where("id = ?", id).First(&item).Error
+	if err != nil {
+		return nil, notFound(err, "recurring item not found")
+	}
+	return &item, nil
+}
+
+// Delete удаляет регулярный платеж
+func (r *RecurringRepository) Delete(ctx context.Context, userID, ledgerID, id uint) error {
+	result := r.db.WithContext(ctx).Scopes(inLedger(userID, ledgerID)).Delete(&model.RecurringItem{}, id)
+	if result.RowsAffected == 0 {
+		return errors.New("recurring item not found")
+	}
+	return result.Error
+}
+
+// GetExpensesSince возвращает расходы бюджета начиная с from вместе с получателями
+func (r *RecurringRepository) GetExpensesSince(ctx context.Context, userID, ledgerID uint, from time.Time) ([]model.Transaction, error) {
+	var transactions []model.Transaction
+	err := r.db.WithContext(ctx).Scopes(inLedger(userID, ledgerID)).
+		Select("id", "category_id", "payee_id", "amount", "type", "description", "date").
+		Where("type = ? AND date >= ?", "expense", from).
+		Preload("Payee").
+		Order("date, id").
+		Find(&transactions).Error
+	return transactions, err
+}
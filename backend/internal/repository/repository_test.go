@@ -0,0 +1,423 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"finance-backend/internal/config"
+	"finance-backend/internal/database"
+	"finance-backend/internal/dto"
+	"finance-backend/internal/migrations"
+	"finance-backend/internal/model"
+	"finance-backend/internal/repository"
+)
+
+// Тесты репозиториев выполняются на SQLite всегда, а на PostgreSQL — если
+// заданы TEST_POSTGRES_HOST и TEST_POSTGRES_DB. База PostgreSQL очищается перед
+// каждым тестом, поэтому умолчания для нее нет: нужна отдельная тестовая база
+func forEachDriver(t *testing.T, test func(t *testing.T, db *gorm.DB)) {
+	t.Run("sqlite", func(t *testing.T) {
+		test(t, openDB(t, config.DBConfig{Driver: "sqlite", Path: t.TempDir() + "/finance.db"}))
+	})
+	t.Run("postgres", func(t *testing.T) {
+		cfg, ok := postgresConfig()
+		if !ok {
+			t.Skip("TEST_POSTGRES_HOST or TEST_POSTGRES_DB is not set")
+		}
+		test(t, openDB(t, cfg))
+	})
+}
+
+func postgresConfig() (config.DBConfig, bool) {
+	host, name := os.Getenv("TEST_POSTGRES_HOST"), os.Getenv("TEST_POSTGRES_DB")
+	if host == "" || name == "" {
+		return config.DBConfig{}, false
+	}
+	port, err := strconv.Atoi(getenv("TEST_POSTGRES_PORT", "5432"))
+	if err != nil {
+		port = 5432
+	}
+	return config.DBConfig{
+		Driver:   "postgres",
+		Host:     host,
+		Port:     port,
+		User:     getenv("TEST_POSTGRES_USER", "postgres"),
+		Password: os.Getenv("TEST_POSTGRES_PASSWORD"),
+		Name:     name,
+		SSLMode:  "disable",
+		// Часовой пояс сессии не UTC, как в docker-compose
+		TimeZone: "Europe/Moscow",
+	}, true
+}
+
+func getenv(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+func openDB(t *testing.T, cfg config.DBConfig) *gorm.DB {
+	t.Helper()
+	db, err := database.Open(cfg)
+	if err != nil {
+		t.Fatalf("open %s: %v", cfg.Driver, err)
+	}
+	db.Logger = logger.Default.LogMode(logger.Silent)
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+
+	if cfg.Driver == "postgres" {
+		if err := db.Exec("DROP SCHEMA public CASCADE; CREATE SCHEMA public").Error; err != nil {
+			t.Fatalf("reset schema: %v", err)
+		}
+	}
+	migrator, err := migrations.New(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := migrator.Up(); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return db
+}
+
+// newUser создает пользователя с личным бюджетом и возвращает их ID
+func newUser(t *testing.T, db *gorm.DB, email string) (uint, uint) {
+	t.Helper()
+	ctx := context.Background()
+	user := &model.User{Email: email, Password: "hash", FirstName: "Test", LastName: "User"}
+	if err := repository.NewUserRepository(db).Create(ctx, user); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	ledger := &model.Ledger{Name: "Личный", OwnerID: user.ID, Personal: true}
+	if err := repository.NewLedgerRepository(db).Create(ctx, ledger); err != nil {
+		t.Fatalf("create ledger: %v", err)
+	}
+	return user.ID, ledger.ID
+}
+
+func newTransaction(t *testing.T, db *gorm.DB, userID, ledgerID uint, description string, date time.Time) *model.Transaction {
+	t.Helper()
+	transaction := &model.Transaction{
+		UserID: userID, LedgerID: ledgerID, Amount: 100, Type: "expense",
+		Description: description, Date: date,
+	}
+	if err := repository.NewTransactionRepository(db).Create(context.Background(), transaction); err != nil {
+		t.Fatalf("create transaction: %v", err)
+	}
+	return transaction
+}
+
+func newTag(t *testing.T, db *gorm.DB, userID uint, name string) *model.Tag {
+	t.Helper()
+	tag := &model.Tag{UserID: userID, Name: name}
+	if err := repository.NewTagRepository(db).Create(context.Background(), tag); err != nil {
+		t.Fatalf("create tag: %v", err)
+	}
+	return tag
+}
+
+func mustParse(t *testing.T, value string) time.Time {
+	t.Helper()
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed
+}
+
+func tagIDs(transaction model.Transaction) []uint {
+	ids := make([]uint, 0, len(transaction.Tags))
+	for _, tag := range transaction.Tags {
+		ids = append(ids, tag.ID)
+	}
+	return ids
+}
+
+func TestDateFilterWithOffset(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, db *gorm.DB) {
+		ctx := context.Background()
+		repo := repository.NewTransactionRepository(db)
+		userID, ledgerID := newUser(t, db, "utc@example.com")
+
+		// 2026-03-09T22:00:00Z, записанное клиентом с московским смещением
+		date := mustParse(t, "2026-03-10T01:00:00+03:00")
+		newTransaction(t, db, userID, ledgerID, "Кофе", date)
+
+		tests := []struct {
+			name     string
+			from, to string
+			want     int
+		}{
+			{"to end of day in UTC", "", "2026-03-09T23:59:59Z", 1},
+			{"to before the moment", "", "2026-03-09T21:59:59Z", 0},
+			{"from the moment", "2026-03-09T22:00:00Z", "", 1},
+			{"from after the moment", "2026-03-09T22:00:01Z", "", 0},
+			{"bounds with another offset", "2026-03-09T12:00:00-10:00", "2026-03-09T12:00:00-10:00", 1},
+		}
+		for _, tt := range tests {
+			var filter dto.TransactionFilter
+			if tt.from != "" {
+				from := mustParse(t, tt.from)
+				filter.From = &from
+			}
+			if tt.to != "" {
+				to := mustParse(t, tt.to)
+				filter.To = &to
+			}
+
+			list, err := repo.GetByLedger(ctx, userID, ledgerID, filter)
+			if err != nil {
+				t.Fatalf("%s: %v", tt.name, err)
+			}
+			if len(list) != tt.want {
+				t.Errorf("%s: got %d transactions, want %d", tt.name, len(list), tt.want)
+			}
+		}
+
+		to := mustParse(t, "2026-03-09T23:59:59Z")
+		summary, err := repo.GetFinancialSummary(ctx, userID, ledgerID, nil, &to)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if summary.TotalExpense != 100 {
+			t.Errorf("TotalExpense = %v, want 100", summary.TotalExpense)
+		}
+
+		got, err := repo.GetByLedger(ctx, userID, ledgerID, dto.TransactionFilter{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !got[0].Date.Equal(date) {
+			t.Errorf("date read back as %v, want %v", got[0].Date, date)
+		}
+	})
+}
+
+func TestTransactionLifecycle(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, db *gorm.DB) {
+		ctx := context.Background()
+		repo := repository.NewTransactionRepository(db)
+		userID, ledgerID := newUser(t, db, "owner@example.com")
+		strangerID, _ := newUser(t, db, "stranger@example.com")
+		transaction := newTransaction(t, db, userID, ledgerID, "Продукты", time.Now())
+
+		if _, err := repo.GetByID(ctx, userID, ledgerID, transaction.ID); err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if _, err := repo.GetByID(ctx, strangerID, ledgerID, transaction.ID); err == nil || errors.Is(err, repository.ErrDatabase) {
+			t.Errorf("GetByID by non-member: %v, want not found", err)
+		}
+		if err := repo.Delete(ctx, strangerID, ledgerID, transaction.ID); err == nil {
+			t.Error("Delete by non-member succeeded")
+		}
+
+		if err := repo.Delete(ctx, userID, ledgerID, transaction.ID); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if _, err := repo.GetByID(ctx, userID, ledgerID, transaction.ID); err == nil {
+			t.Error("deleted transaction is still visible")
+		}
+		trashed, err := repo.GetTrashed(ctx, userID, ledgerID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(trashed) != 1 || trashed[0].ID != transaction.ID {
+			t.Fatalf("trash = %v, want the deleted transaction", trashed)
+		}
+
+		if _, err := repo.Restore(ctx, strangerID, ledgerID, transaction.ID); err == nil {
+			t.Error("Restore by non-member succeeded")
+		}
+		restored, err := repo.Restore(ctx, userID, ledgerID, transaction.ID)
+		if err != nil {
+			t.Fatalf("Restore: %v", err)
+		}
+		if restored.Description != "Продукты" {
+			t.Errorf("restored description = %q", restored.Description)
+		}
+		if _, err := repo.Restore(ctx, userID, ledgerID, transaction.ID); err == nil {
+			t.Error("second Restore succeeded")
+		}
+	})
+}
+
+func TestUpdateWithTagsIsAtomic(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, db *gorm.DB) {
+		ctx := context.Background()
+		repo := repository.NewTransactionRepository(db)
+		userID, ledgerID := newUser(t, db, "tags@example.com")
+		tag := newTag(t, db, userID, "отпуск")
+		other := newTag(t, db, userID, "подарок")
+		transaction := newTransaction(t, db, userID, ledgerID, "Билеты", time.Now())
+
+		transaction.Description = "Билеты на поезд"
+		if err := repo.UpdateWithTags(ctx, userID, transaction, []uint{tag.ID}); err != nil {
+			t.Fatalf("UpdateWithTags: %v", err)
+		}
+
+		// Несуществующий тег нарушает внешний ключ: не должно сохраниться ничего
+		transaction.Description = "Не сохранится"
+		err := repo.UpdateWithTags(ctx, userID, transaction, []uint{other.ID, 999999})
+		if !errors.Is(err, repository.ErrDatabase) {
+			t.Fatalf("UpdateWithTags with a missing tag: %v, want ErrDatabase", err)
+		}
+
+		got, err := repo.GetByIDs(ctx, userID, ledgerID, []uint{transaction.ID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got[0].Description != "Билеты на поезд" {
+			t.Errorf("description = %q, want the value before the failed update", got[0].Description)
+		}
+		if ids := tagIDs(got[0]); len(ids) != 1 || ids[0] != tag.ID {
+			t.Errorf("tags = %v, want [%d]", ids, tag.ID)
+		}
+	})
+}
+
+func TestUpdateManyIsAtomic(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, db *gorm.DB) {
+		ctx := context.Background()
+		repo := repository.NewTransactionRepository(db)
+		userID, ledgerID := newUser(t, db, "rules@example.com")
+		first := newTransaction(t, db, userID, ledgerID, "Такси", time.Now())
+		second := newTransaction(t, db, userID, ledgerID, "Метро", time.Now())
+
+		first.Amount, second.Amount = 1, 2
+		err := repo.UpdateMany(ctx, userID, []*model.Transaction{first, second},
+			map[uint][]uint{second.ID: {999999}})
+		if err == nil {
+			t.Fatal("UpdateMany with a missing tag succeeded")
+		}
+
+		got, err := repo.GetByIDs(ctx, userID, ledgerID, []uint{first.ID, second.ID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, transaction := range got {
+			if transaction.Amount != 100 {
+				t.Errorf("transaction %d amount = %v, want 100 after rollback", transaction.ID, transaction.Amount)
+			}
+		}
+
+		if err := repo.UpdateMany(ctx, userID, []*model.Transaction{first, second}, nil); err != nil {
+			t.Fatalf("UpdateMany: %v", err)
+		}
+		got, err = repo.GetByIDs(ctx, userID, ledgerID, []uint{first.ID, second.ID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 2 || got[0].Amount+got[1].Amount != 3 {
+			t.Errorf("amounts after UpdateMany = %v", got)
+		}
+	})
+}
+
+func TestMergeMovesDuplicateToTrash(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, db *gorm.DB) {
+		ctx := context.Background()
+		repo := repository.NewTransactionRepository(db)
+		userID, ledgerID := newUser(t, db, "merge@example.com")
+		tag := newTag(t, db, userID, "кафе")
+		keep := newTransaction(t, db, userID, ledgerID, "Обед", time.Now())
+		remove := newTransaction(t, db, userID, ledgerID, "Обед", time.Now())
+		if err := repo.UpdateWithTags(ctx, userID, remove, []uint{tag.ID}); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := repository.NewDuplicateRepository(db).Merge(ctx, keep, remove.ID); err != nil {
+			t.Fatalf("Merge: %v", err)
+		}
+
+		got, err := repo.GetByIDs(ctx, userID, ledgerID, []uint{keep.ID, remove.ID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 1 || got[0].ID != keep.ID {
+			t.Fatalf("visible after merge: %v, want only the kept transaction", got)
+		}
+		if ids := tagIDs(got[0]); len(ids) != 1 || ids[0] != tag.ID {
+			t.Errorf("kept transaction tags = %v, want [%d]", ids, tag.ID)
+		}
+
+		trashed, err := repo.GetTrashed(ctx, userID, ledgerID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(trashed) != 1 || trashed[0].ID != remove.ID {
+			t.Fatalf("trash = %v, want the merged-away transaction", trashed)
+		}
+		if _, err := repo.Restore(ctx, userID, ledgerID, remove.ID); err != nil {
+			t.Errorf("Restore after merge: %v", err)
+		}
+	})
+}
+
+func TestErrorsAreTaggedAsDatabase(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, db *gorm.DB) {
+		ctx := context.Background()
+		repo := repository.NewTransactionRepository(db)
+		userID, ledgerID := newUser(t, db, "closed@example.com")
+
+		if _, err := repo.GetByID(ctx, userID, ledgerID, 999999); err == nil || errors.Is(err, repository.ErrDatabase) {
+			t.Errorf("missing transaction: %v, want not found without ErrDatabase", err)
+		}
+
+		sqlDB, err := db.DB()
+		if err != nil {
+			t.Fatal(err)
+		}
+		sqlDB.Close()
+		if _, err := repo.GetByID(ctx, userID, ledgerID, 1); !errors.Is(err, repository.ErrDatabase) {
+			t.Errorf("closed database: %v, want ErrDatabase", err)
+		}
+	})
+}
+
+func TestRemoveMember(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, db *gorm.DB) {
+		ctx := context.Background()
+		repo := repository.NewLedgerRepository(db)
+		ownerID, ledgerID := newUser(t, db, "owner@example.com")
+		memberID, _ := newUser(t, db, "member@example.com")
+		if err := repo.AddMember(ctx, &model.LedgerMember{LedgerID: ledgerID, UserID: memberID, Role: model.RoleEditor}); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := repo.RemoveMember(ctx, ledgerID, memberID); err != nil {
+			t.Fatalf("RemoveMember: %v", err)
+		}
+		if _, err := repo.GetMember(ctx, ledgerID, memberID); err == nil {
+			t.Error("removed member is still in the ledger")
+		}
+		if _, err := repo.GetMember(ctx, ledgerID, ownerID); err != nil {
+			t.Errorf("owner was removed too: %v", err)
+		}
+
+		err := repo.RemoveMember(ctx, ledgerID, memberID)
+		if err == nil || err.Error() != "member not found" {
+			t.Errorf("second RemoveMember: %v, want member not found", err)
+		}
+
+		sqlDB, err := db.DB()
+		if err != nil {
+			t.Fatal(err)
+		}
+		sqlDB.Close()
+		if err := repo.RemoveMember(ctx, ledgerID, ownerID); !errors.Is(err, repository.ErrDatabase) {
+			t.Errorf("RemoveMember on a closed database: %v, want ErrDatabase", err)
+		}
+	})
+}
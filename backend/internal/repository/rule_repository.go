@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"finance-backend/internal/model"
+)
+
+type RuleRepository struct {
+	db *gorm.DB
+}
+
+func NewRuleRepository(db *gorm.DB) *RuleRepository {
+	return &RuleRepository{db: db}
+}
+
+// Create создает правило вместе с его тегами
+func (r *RuleRepository) Create(ctx context.Context, rule *model.Rule) error {
+	return r.db.WithContext(ctx).Create(rule).Error
+}
+
+// Update сохраняет правило и заменяет его теги
+func (r *RuleRepository) Update(ctx context.Context, rule *model.Rule) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Save(rule).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM rule_tags WHERE rule_id = ?", rule.ID).Error; err != nil {
+			return err
+		}
+		for _, tag := range rule.Tags {
+			if err := tx.Exec("INSERT INTO rule_tags (rule_id, tag_id) VALUES (?, ?)", rule.ID, tag.ID).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetByUserID возвращает правила пользователя в порядке применения
+func (r *RuleRepository) GetByUserID(ctx context.Context, userID uint) ([]model.Rule, error) {
+	var rules []model.Rule
+	err := r.db.WithContext(ctx).Preload("Tags").Where("user_id = ?", userID).Order("priority, id").Find(&rules).Error
+	return rules, err
+}
+
+// GetActive возвращает включенные правила пользователя, применимые к бюджету
+func (r *RuleRepository) GetActive(ctx context.Context, userID, ledgerID uint) ([]model.Rule, error) {
+	var rules []model.Rule
+	err := r.db.WithContext(ctx).Preload("Tags").
+		Where("user_id = ? AND enabled = ? AND (ledger_id IS NULL OR ledger_id = ?)", userID, true, ledgerID).
+		Order("priority, id").
+		Find(&rules).Error
+	return rules, err
+}
+
+// GetByID возвращает правило пользователя по ID
+func (r *RuleRepository) GetByID(ctx context.Context, userID, id uint) (*model.Rule, error) {
+	var rule model.Rule
+	err := r.db.WithContext(ctx).Preload("Tags").Where("id = ? AND user_id = ?", id, userID).First(&rule).Error
+	if err != nil {
+		return nil, notFound(err, "rule not found")
+	}
+	return &rule, nil
+}
+
+// Delete удаляет правило
+func (r *RuleRepository) Delete(ctx context.Context, userID, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&model.Rule{}).Where("id = ? AND user_id = ?", id, userID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return errors.New("rule not found")
+		}
+		if err := tx.Exec("DELETE FROM rule_tags WHERE rule_id = ?", id).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.Rule{}, id).Error
+	})
+}
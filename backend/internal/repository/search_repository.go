@@ -0,0 +1,263 @@
+package repository
+
+import (
+	"context"
+	"html"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+// Маркеры совпадений из области частного использования Unicode: ts_headline
+// ставит их вместо тегов, а markHighlights заменяет на <mark> после экранирования текста
+const (
+	markStart = "\uE000"
+	markStop  = "\uE001"
+)
+
+// searchHeadlineOptions оформление совпадений в ts_headline
+const searchHeadlineOptions = "StartSel=" + markStart + ", StopSel=" + markStop + ", MaxWords=30, MinWords=10, MaxFragments=2"
+
+type SearchRepository struct {
+	db *gorm.DB
+}
+
+func NewSearchRepository(db *gorm.DB) *SearchRepository {
+	return &SearchRepository{db: db}
+}
+
+// SearchHit найденная транзакция с релевантностью и подсвеченными фрагментами
+type SearchHit struct {
+	ID                   uint
+	Rank                 float64
+	DescriptionHighlight string
+	NotesHighlight       string
+}
+
+// Search ищет транзакции бюджета по тексту запроса в синтаксисе веб-поиска
+// ("фразы", -исключения, or) и возвращает их по убыванию релевантности
+func (r *SearchRepository) Search(ctx context.Context, userID, ledgerID uint, text string, from, to *time.Time, limit, offset int) ([]SearchHit, int64, error) {
+	if r.db.WithContext(ctx).Dialector.Name() == "sqlite" {
+		return r.searchLike(ctx, userID, ledgerID, text, from, to, limit, offset)
+	}
+
+	query := r.db.WithContext(ctx).Table("transactions").
+		Joins("CROSS JOIN (SELECT websearch_to_tsquery('russian', ?) || websearch_to_tsquery('english', ?) AS query) AS q", text, text).
+		Scopes(inLedger(userID, ledgerID)).
+		Where("transactions.search_vector @@ q.query AND transactions.deleted_at IS NULL")
+	if from != nil {
+		query = query.Where("transactions.date >= ?", from)
+	}
+	if to != nil {
+		query = query.Where("transactions.date <= ?", to)
+	}
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var hits []SearchHit
+	err := query.
+		Select("transactions.id, ts_rank_cd(transactions.search_vector, q.query) AS rank, "+
+			"ts_headline('russian', transactions.description, q.query, ?) AS description_highlight, "+
+			"ts_headline('russian', transactions.notes, q.query, ?) AS notes_highlight",
+			searchHeadlineOptions, searchHeadlineOptions).
+		Order("rank DESC, transactions.date DESC, transactions.id DESC").
+		Limit(limit).
+		Offset(offset).
+		Scan(&hits).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	for i := range hits {
+		hits[i].DescriptionHighlight = markHighlights(hits[i].DescriptionHighlight)
+		hits[i].NotesHighlight = markHighlights(hits[i].NotesHighlight)
+	}
+	return hits, total, nil
+}
+
+// markHighlights экранирует фрагмент ts_headline как HTML и заменяет маркеры
+// совпадений тегами <mark>: описание и заметки вводит пользователь
+func markHighlights(s string) string {
+	return strings.NewReplacer(markStart, "<mark>", markStop, "</mark>").Replace(html.EscapeString(s))
+}
+
+// searchFields поля, по которым ищет searchLike, с весами как у поискового
+// вектора Postgres. Получатель и категория берутся подзапросами, чтобы не
+// конфликтовать с ledger_id в inLedger
+var searchFields = []struct {
+	expr   string
+	weight string
+}{
+	{"transactions.description", "1.0"},
+	{"(SELECT payees.name FROM payees WHERE payees.id = transactions.payee_id)", "1.0"},
+	{"transactions.notes", "0.4"},
+	{"(SELECT categories.name FROM categories WHERE categories.id = transactions.category_id)", "0.2"},
+}
+
+// searchLike поиск для SQLite, где нет полнотекстового поиска Postgres:
+// каждое слово или "фраза" запроса должны встречаться хотя бы в одном поле,
+// слова с минусом — ни в одном. Регистр не учитывается, морфология — нет
+func (r *SearchRepository) searchLike(ctx context.Context, userID, ledgerID uint, text string, from, to *time.Time, limit, offset int) ([]SearchHit, int64, error) {
+	include, exclude := parseSearchTerms(text)
+	if len(include) == 0 {
+		return nil, 0, nil
+	}
+
+	query := r.db.WithContext(ctx).Table("transactions").
+		Scopes(inLedger(userID, ledgerID)).
+		Where("transactions.deleted_at IS NULL")
+	for _, term := range include {
+		condition, args := matchAnyField(term)
+		query = query.Where(condition, args...)
+	}
+	for _, term := range exclude {
+		condition, args := matchAnyField(term)
+		query = query.Not(condition, args...)
+	}
+	if from != nil {
+		query = query.Where("transactions.date >= ?", from)
+	}
+	if to != nil {
+		query = query.Where("transactions.date <= ?", to)
+	}
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// Релевантность: сумма весов полей, в которых встретилось каждое слово
+	var rank []string
+	var rankArgs []any
+	for _, term := range include {
+		for _, field := range searchFields {
+			rank = append(rank, "CASE WHEN unicode_lower(COALESCE("+field.expr+", '')) LIKE ? ESCAPE '\\' THEN "+field.weight+" ELSE 0 END")
+			rankArgs = append(rankArgs, likePattern(term))
+		}
+	}
+
+	var rows []struct {
+		ID          uint
+		Rank        float64
+		Description string
+		Notes       string
+	}
+	err := query.
+		Select("transactions.id, transactions.description, transactions.notes, ("+strings.Join(rank, " + ")+") AS rank", rankArgs...).
+		Order("rank DESC, transactions.date DESC, transactions.id DESC").
+		Limit(limit).
+		Offset(offset).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	hits := make([]SearchHit, len(rows))
+	for i, row := range rows {
+		hits[i] = SearchHit{
+			ID:                   row.ID,
+			Rank:                 row.Rank,
+			DescriptionHighlight: highlightTerms(row.Description, include),
+			NotesHighlight:       highlightTerms(row.Notes, include),
+		}
+	}
+	return hits, total, nil
+}
+
+// matchAnyField условие «слово встречается хотя бы в одном поле поиска»
+func matchAnyField(term string) (string, []any) {
+	conditions := make([]string, len(searchFields))
+	args := make([]any, len(searchFields))
+	for i, field := range searchFields {
+		conditions[i] = "unicode_lower(COALESCE(" + field.expr + ", '')) LIKE ? ESCAPE '\\'"
+		args[i] = likePattern(term)
+	}
+	return "(" + strings.Join(conditions, " OR ") + ")", args
+}
+
+// likePattern шаблон LIKE для подстроки с экранированными спецсимволами
+func likePattern(term string) string {
+	return "%" + strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(term) + "%"
+}
+
+// parseSearchTerms разбирает запрос в синтаксисе веб-поиска: слова, "фразы"
+// и -исключения. Оператор or не поддерживается и пропускается
+func parseSearchTerms(text string) (include, exclude []string) {
+	text = strings.ToLower(text)
+	for len(text) > 0 {
+		text = strings.TrimLeft(text, " \t\n")
+		if text == "" {
+			break
+		}
+
+		negate := false
+		if text[0] == '-' {
+			negate = true
+			text = text[1:]
+		}
+
+		var term string
+		if strings.HasPrefix(text, `"`) {
+			end := strings.Index(text[1:], `"`)
+			if end < 0 {
+				term, text = text[1:], ""
+			} else {
+				term, text = text[1:end+1], text[end+2:]
+			}
+		} else {
+			end := strings.IndexAny(text, " \t\n")
+			if end < 0 {
+				end = len(text)
+			}
+			term, text = text[:end], text[end:]
+			if !negate && term == "or" {
+				continue
+			}
+		}
+
+		if term = strings.TrimSpace(term); term == "" {
+			continue
+		}
+		if negate {
+			exclude = append(exclude, term)
+		} else {
+			include = append(include, term)
+		}
+	}
+	return include, exclude
+}
+
+// highlightTerms выделяет вхождения слов запроса так же, как ts_headline;
+// текст экранируется как HTML, чтобы в результат попадали только теги <mark>
+func highlightTerms(text string, terms []string) string {
+	if text == "" {
+		return ""
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(text); {
+		matched := ""
+		for _, term := range terms {
+			if n := len(term); i+n <= len(text) && strings.EqualFold(text[i:i+n], term) && len(term) > len(matched) {
+				matched = text[i : i+n]
+			}
+		}
+		if matched != "" {
+			b.WriteString("<mark>" + html.EscapeString(matched) + "</mark>")
+			i += len(matched)
+			continue
+		}
+
+		_, size := utf8.DecodeRuneInString(text[i:])
+		b.WriteString(html.EscapeString(text[i : i+size]))
+		i += size
+	}
+	return b.String()
+}
@@ -0,0 +1,31 @@
+package repository
+
+import "testing"
+
+func TestHighlightTermsEscapesHTML(t *testing.T) {
+	tests := []struct {
+		text  string
+		terms []string
+		want  string
+	}{
+		{"Кофе в Starbucks", []string{"кофе"}, "<mark>Кофе</mark> в Starbucks"},
+		{`<img src=x onerror=alert(1)> coffee`, []string{"coffee"},
+			"&lt;img src=x onerror=alert(1)&gt; <mark>coffee</mark>"},
+		{"<script>x</script>", []string{"<script>"}, "<mark>&lt;script&gt;</mark>x&lt;/script&gt;"},
+		{`Tom & "Jerry"`, []string{"jerry"}, `Tom &amp; &#34;<mark>Jerry</mark>&#34;`},
+		{"", []string{"x"}, ""},
+	}
+	for _, tt := range tests {
+		if got := highlightTerms(tt.text, tt.terms); got != tt.want {
+			t.Errorf("highlightTerms(%q, %q) = %q, want %q", tt.text, tt.terms, got, tt.want)
+		}
+	}
+}
+
+func TestMarkHighlightsEscapesHTML(t *testing.T) {
+	headline := "<img onerror=alert(1)> " + markStart + "coffee" + markStop + " & tea"
+	want := "&lt;img onerror=alert(1)&gt; <mark>coffee</mark> &amp; tea"
+	if got := markHighlights(headline); got != want {
+		t.Errorf("markHighlights = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"finance-backend/internal/model"
+)
+
+type SplitRepository struct {
+	db *gorm.DB
+}
+
+func NewSplitRepository(db *gorm.DB) *SplitRepository {
+	return &SplitRepository{db: db}
+}
+
+// SplitDebt долг участника перед плательщиком по одной доле расхода
+type SplitDebt struct {
+	PaidByID uint
+	UserID   uint
+	Amount   float64
+}
+
+// GetLedgerDebts возвращает доли всех общих расходов бюджета
+func (r *SplitRepository) GetLedgerDebts(ctx context.Context, userID, ledgerID uint) ([]SplitDebt, error) {
+	var debts []SplitDebt
+	err := r.db.WithContext(ctx).Model(&model.TransactionSplit{}).
+		Select("transactions.paid_by_id, transaction_splits.user_id, transaction_splits.amount").
+		Joins("JOIN transactions ON transactions.id = transaction_splits.transaction_id").
+		Where("transactions.paid_by_id IS NOT NULL AND transactions.deleted_at IS NULL").
+		Where("transactions.ledger_id = ? AND EXISTS (SELECT 1 FROM ledger_members WHERE ledger_members.ledger_id = ? AND ledger_members.user_id = ?)",
+			ledgerID, ledgerID, userID).
+		Scan(&debts).Error
+	return debts, err
+}
+
+// CreateSettlement сохраняет запись о возврате долга
+func (r *SplitRepository) CreateSettlement(ctx context.Context, settlement *model.Settlement) error {
+	return r.db.WithContext(ctx).Create(settlement).Error
+}
+
+// GetSettlements возвращает возвраты долгов в бюджете
+func (r *SplitRepository) GetSettlements(ctx context.Context, userID, ledgerID uint) ([]model.Settlement, error) {
+	var settlements []model.Settlement
+	err := r.db.WithContext(ctx).Scopes(inLedger(userID, ledgerID)).Order("date DESC, id DESC").Find(&settlements).Error
+	return settlements, err
+}
@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"finance-backend/internal/model"
+)
+
+type TagRepository struct {
+	db *gorm.DB
+}
+
+func NewTagRepository(db *gorm.DB) *TagRepository {
+	return &TagRepository{db: db}
+}
+
+// TagTotals суммы транзакций с тегом
+type TagTotals struct {
+	TagID        uint
+	Name         string
+	Color        string
+	TotalIncome  float64
+	TotalExpense float64
+	Count        int64
+}
+
+// Create создает тег
+func (r *TagRepository) Create(ctx context.Context, tag *model.Tag) error {
+	return r.db.WithContext(ctx).Create(tag).Error
+}
+
+// Update сохраняет изменения тега
+func (r *TagRepository) Update(ctx context.Context, tag *model.Tag) error {
+	return r.db.WithContext(ctx).Save(tag).Error
+}
+
+// GetByUserID возвращает все теги пользователя
+func (r *TagRepository) GetByUserID(ctx context.Context, userID uint) ([]model.Tag, error) {
+	var tags []model.Tag
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("name").Find(&tags).Error
+	return tags, err
+}
+
+// GetByID возвращает тег пользователя по ID
+func (r *TagRepository) GetByID(ctx context.Context, userID uint, id uint) (*model.Tag, error) {
+	var tag model.Tag
+	err := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).First(&tag).Error
+	if err != nil {
+		return nil, notFound(err, "tag not found")
+	}
+	return &tag, nil
+}
+
+// GetByIDs возвращает теги пользователя; ошибка, если какой-то тег не найден
+func (r *TagRepository) GetByIDs(ctx context.Context, userID uint, ids []uint) ([]model.Tag, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	var tags []model.Tag
+	if err := r.db.WithContext(ctx).Where("user_id = ? AND id IN ?", userID, ids).Find(&tags).Error; err != nil {
+		return nil, err
+	}
+	if len(tags) != len(uniqueIDs(ids)) {
+		return nil, errors.New("tag not found")
+	}
+	return tags, nil
+}
+
+// NameExists проверяет, есть ли у пользователя тег с таким именем
+func (r *TagRepository) NameExists(ctx context.Context, userID uint, name string, exceptID uint) bool {
+	var count int64
+	r.db.WithContext(ctx).Model(&model.Tag{}).Where("user_id = ? AND name = ? AND id <> ?", userID, name, exceptID).Count(&count)
+	return count > 0
+}
+
+// Delete удаляет тег и снимает его со всех транзакций и правил
+func (r *TagRepository) Delete(ctx context.Context, userID uint, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&model.Tag{}).Where("id = ? AND user_id = ?", id, userID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return errors.New("tag not found")
+		}
+		if err := tx.Exec("DELETE FROM transaction_tags WHERE tag_id = ?", id).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM rule_tags WHERE tag_id = ?", id).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.Tag{}, id).Error
+	})
+}
+
+// SetTransactionTags заменяет теги пользователя на транзакции, не трогая теги других участников
+func (r *TagRepository) SetTransactionTags(ctx context.Context, userID, transactionID uint, tagIDs []uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return setTransactionTags(tx, userID, transactionID, tagIDs)
+	})
+}
+
+// setTransactionTags заменяет теги пользователя на транзакции внутри транзакции базы tx
+func setTransactionTags(tx *gorm.DB, userID, transactionID uint, tagIDs []uint) error {
+	err := tx.Exec("DELETE FROM transaction_tags WHERE transaction_id = ? AND tag_id IN (SELECT id FROM tags WHERE user_id = ?)",
+		transactionID, userID).Error
+	if err != nil {
+		return err
+	}
+	for _, tagID := range uniqueIDs(tagIDs) {
+		err := tx.Exec("INSERT INTO transaction_tags (transaction_id, tag_id) VALUES (?, ?)", transactionID, tagID).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetTotals возвращает суммы доходов и расходов по тегам пользователя в бюджете
+func (r *TagRepository) GetTotals(ctx context.Context, userID, ledgerID uint, from, to *time.Time) ([]TagTotals, error) {
+	query := r.db.WithContext(ctx).Model(&model.Tag{}).
+		Select("tags.id AS tag_id, tags.name, tags.color, "+
+			"COALESCE(SUM(CASE WHEN transactions.type = 'income' THEN transactions.amount ELSE 0 END), 0) AS total_income, "+
+			"COALESCE(SUM(CASE WHEN transactions.type = 'expense' THEN transactions.amount ELSE 0 END), 0) AS total_expense, "+
+			"COUNT(transactions.id) AS count").
+		Joins("JOIN transaction_tags ON transaction_tags.tag_id = tags.id").
+		Joins("JOIN transactions ON transactions.id = transaction_tags.transaction_id AND transactions.deleted_at IS NULL").
+		Where("tags.user_id = ?", userID).
+		Where("transactions.ledger_id = ? AND EXISTS (SELECT 1 FROM ledger_members WHERE ledger_members.ledger_id = ? AND ledger_members.user_id = ?)",
+			ledgerID, ledgerID, userID)
+
+	if from != nil {
+		query = query.Where("transactions.date >= ?", from)
+	}
+	if to != nil {
+		query = query.Where("transactions.date <= ?", to)
+	}
+
+	var totals []TagTotals
+	err := query.Group("tags.id, tags.name, tags.color").Order("total_expense DESC").Scan(&totals).Error
+	return totals, err
+}
+
+// uniqueIDs убирает повторы, сохраняя порядок
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	result := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			result = append(result, id)
+		}
+	}
+	return result
+}
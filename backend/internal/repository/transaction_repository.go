@@ -1,12 +1,14 @@
 package repository
 
 import (
+	"context"
 	"errors"
 	"finance-backend/internal/dto"
 	"finance-backend/internal/model"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type TransactionRepository struct {
@@ -18,43 +20,89 @@ func NewTransactionRepository(db *gorm.DB) *TransactionRepository {
 }
 
 // Create создает новую транзакцию
-func (r *TransactionRepository) Create(transaction *model.Transaction) error {
-	return r.db.Create(transaction).Error
+func (r *TransactionRepository) Create(ctx context.Context, transaction *model.Transaction) error {
+	return r.db.WithContext(ctx).Create(transaction).Error
 }
 
-// GetByID возвращает транзакцию по ID с проверкой пользователя
-func (r *TransactionRepository) GetByID(userID uint, id uint) (*model.Transaction, error) {
+// GetByID возвращает транзакцию по ID с проверкой доступа к бюджету
+func (r *TransactionRepository) GetByID(ctx context.Context, userID, ledgerID uint, id uint) (*model.Transaction, error) {
+	return r.getByID(r.db.WithContext(ctx), userID, ledgerID, id)
+}
+
+func (r *TransactionRepository) getByID(db *gorm.DB, userID, ledgerID uint, id uint) (*model.Transaction, error) {
 	var transaction model.Transaction
-	err := r.db.Where("id = ? AND user_id = ?", id, userID).First(&transaction).Error
+	err := db.Scopes(inLedger(userID, ledgerID)).Where("id = ?", id).First(&transaction).Error
 	if err != nil {
-		return nil, errors.New("transaction not found")
+		return nil, notFound(err, "transaction not found")
 	}
 	return &transaction, nil
 }
 
-// GetByUserID возвращает все транзакции пользователя
-func (r *TransactionRepository) GetByUserID(userID uint, from, to *time.Time) ([]model.Transaction, error) {
+// GetByLedger возвращает транзакции бюджета; теги подгружаются только пользовательские
+func (r *TransactionRepository) GetByLedger(ctx context.Context, userID, ledgerID uint, filter dto.TransactionFilter) ([]model.Transaction, error) {
 	var transactions []model.Transaction
 
-	query := r.db.Where("user_id = ?", userID)
+	query := r.db.WithContext(ctx).Scopes(inLedger(userID, ledgerID))
 
-	if from != nil {
-		query = query.Where("date >= ?", from)
+	if filter.From != nil {
+		query = query.Where("date >= ?", filter.From)
 	}
-	if to != nil {
-		query = query.Where("date <= ?", to)
+	if filter.To != nil {
+		query = query.Where("date <= ?", filter.To)
+	}
+	if tagIDs := uniqueIDs(filter.TagIDs); len(tagIDs) > 0 {
+		if filter.MatchAllTags {
+			query = query.Where("(SELECT COUNT(DISTINCT tag_id) FROM transaction_tags WHERE transaction_tags.transaction_id = transactions.id AND tag_id IN ?) = ?",
+				tagIDs, len(tagIDs))
+		} else {
+			query = query.Where("EXISTS (SELECT 1 FROM transaction_tags WHERE transaction_tags.transaction_id = transactions.id AND tag_id IN ?)", tagIDs)
+		}
+	}
+
+	err := query.
+		Preload("Category").
+		Preload("Payee").
+		Preload("Splits").
+		Preload("Tags", "user_id = ?", userID).
+		Order("date DESC").
+		Find(&transactions).Error
+	return transactions, err
+}
+
+// GetByIDs возвращает транзакции бюджета с указанными ID
+func (r *TransactionRepository) GetByIDs(ctx context.Context, userID, ledgerID uint, ids []uint) ([]model.Transaction, error) {
+	var transactions []model.Transaction
+	if len(ids) == 0 {
+		return transactions, nil
 	}
 
-	err := query.Preload("Category").Order("date DESC").Find(&transactions).Error
+	err := r.db.WithContext(ctx).Scopes(inLedger(userID, ledgerID)).
+		Where("id IN ?", ids).
+		Preload("Category").
+		Preload("Payee").
+		Preload("Splits").
+		Preload("Tags", "user_id = ?", userID).
+		Find(&transactions).Error
+	return transactions, err
+}
+
+// GetCategorized возвращает категоризированные транзакции пользователя в бюджете —
+// обучающую выборку для подсказок категорий
+func (r *TransactionRepository) GetCategorized(ctx context.Context, userID, ledgerID uint) ([]model.Transaction, error) {
+	var transactions []model.Transaction
+	err := r.db.WithContext(ctx).Scopes(inLedger(userID, ledgerID)).
+		Select("id", "user_id", "ledger_id", "category_id", "amount", "type", "description").
+		Where("user_id = ? AND category_id IS NOT NULL", userID).
+		Find(&transactions).Error
 	return transactions, err
 }
 
 // GetFinancialSummary возвращает финансовую сводку
-func (r *TransactionRepository) GetFinancialSummary(userID uint, from, to *time.Time) (*dto.FinancialSummary, error) {
+func (r *TransactionRepository) GetFinancialSummary(ctx context.Context, userID, ledgerID uint, from, to *time.Time) (*dto.FinancialSummary, error) {
 	var summary dto.FinancialSummary
 
 	// Доходы
-	incomeQuery := r.db.Model(&model.Transaction{}).Where("user_id = ? AND type = ?", userID, "income")
+	incomeQuery := r.db.WithContext(ctx).Model(&model.Transaction{}).Scopes(inLedger(userID, ledgerID)).Where("type = ?", "income")
 	if from != nil {
 		incomeQuery = incomeQuery.Where("date >= ?", from)
 	}
@@ -68,7 +116,7 @@ func (r *TransactionRepository) GetFinancialSummary(userID uint, from, to *time.
 	}
 
 	// Расходы
-	expenseQuery := r.db.Model(&model.Transaction{}).Where("user_id = ? AND type = ?", userID, "expense")
+	expenseQuery := r.db.WithContext(ctx).Model(&model.Transaction{}).Scopes(inLedger(userID, ledgerID)).Where("type = ?", "expense")
 	if from != nil {
 		expenseQuery = expenseQuery.Where("date >= ?", from)
 	}
@@ -85,16 +133,126 @@ func (r *TransactionRepository) GetFinancialSummary(userID uint, from, to *time.
 	return &summary, nil
 }
 
-// Update обновляет транзакцию
-func (r *TransactionRepository) Update(transaction *model.Transaction) error {
-	return r.db.Save(transaction).Error
+// Update обновляет поля транзакции без связанных записей
+func (r *TransactionRepository) Update(ctx context.Context, transaction *model.Transaction) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Save(transaction).Error
+}
+
+// UpdateWithTags обновляет поля транзакции и заменяет на ней теги пользователя
+// одной транзакцией базы: при ошибке не сохраняется ни то, ни другое
+func (r *TransactionRepository) UpdateWithTags(ctx context.Context, userID uint, transaction *model.Transaction, tagIDs []uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Save(transaction).Error; err != nil {
+			return err
+		}
+		return setTransactionTags(tx, userID, transaction.ID, tagIDs)
+	})
 }
 
-// Delete удаляет транзакцию
-func (r *TransactionRepository) Delete(userID uint, id uint) error {
-	result := r.db.Where("user_id = ?", userID).Delete(&model.Transaction{}, id)
+// UpdateMany сохраняет несколько транзакций одной транзакцией базы: изменения
+// применяются все или ни одно. tagIDs задает новые теги пользователя по ID
+// транзакции; у транзакций, которых нет в tagIDs, теги не меняются
+func (r *TransactionRepository) UpdateMany(ctx context.Context, userID uint, transactions []*model.Transaction, tagIDs map[uint][]uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, t := range transactions {
+			if err := tx.Omit(clause.Associations).Save(t).Error; err != nil {
+				return err
+			}
+			if ids, ok := tagIDs[t.ID]; ok {
+				if err := setTransactionTags(tx, userID, t.ID, ids); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// Delete перемещает транзакцию в корзину
+func (r *TransactionRepository) Delete(ctx context.Context, userID, ledgerID uint, id uint) error {
+	result := r.db.WithContext(ctx).Scopes(inLedger(userID, ledgerID)).Delete(&model.Transaction{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
 	if result.RowsAffected == 0 {
 		return errors.New("transaction not found")
 	}
-	return result.Error
+	return nil
+}
+
+// GetTrashed возвращает транзакции бюджета в корзине, последние удаленные первыми
+func (r *TransactionRepository) GetTrashed(ctx context.Context, userID, ledgerID uint) ([]model.Transaction, error) {
+	var transactions []model.Transaction
+	err := r.db.WithContext(ctx).Unscoped().
+		Scopes(inLedger(userID, ledgerID)).
+		Where("deleted_at IS NOT NULL").
+		Preload("Category", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Preload("Payee").
+		Preload("Tags", "user_id = ?", userID).
+		Order("deleted_at DESC").
+		Find(&transactions).Error
+	return transactions, err
+}
+
+// Restore возвращает транзакцию из корзины
+func (r *TransactionRepository) Restore(ctx context.Context, userID, ledgerID, id uint) (*model.Transaction, error) {
+	result := r.db.WithContext(ctx).Unscoped().Model(&model.Transaction{}).
+		Scopes(inLedger(userID, ledgerID)).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return nil, dbError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.New("transaction not found in trash")
+	}
+
+	restored, err := r.GetByIDs(ctx, userID, ledgerID, []uint{id})
+	if err != nil {
+		return nil, dbError(err)
+	}
+	if len(restored) == 0 {
+		return nil, errors.New("transaction not found")
+	}
+	return &restored[0], nil
+}
+
+// PurgeDeleted окончательно удаляет транзакции, попавшие в корзину раньше before
+func (r *TransactionRepository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	var ids []uint
+	err := r.db.WithContext(ctx).Unscoped().Model(&model.Transaction{}).Where("deleted_at < ?", before).Pluck("id", &ids).Error
+	if err != nil || len(ids) == 0 {
+		return 0, err
+	}
+
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, id := range ids {
+			if err := deleteTransaction(tx, id); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(ids)), nil
+}
+
+// deleteTransaction окончательно удаляет транзакцию вместе с долями, тегами,
+// отметками о дубликатах и предупреждениями. Вложения удаляет задача очистки сирот
+func deleteTransaction(tx *gorm.DB, id uint) error {
+	if err := tx.Where("transaction_id = ?", id).Delete(&model.TransactionSplit{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Exec("DELETE FROM transaction_tags WHERE transaction_id = ?", id).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("transaction_id = ? OR other_id = ?", id, id).Delete(&model.DuplicateDismissal{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("transaction_id = ?", id).Delete(&model.Alert{}).Error; err != nil {
+		return err
+	}
+	return tx.Unscoped().Delete(&model.Transaction{}, id).Error
 }
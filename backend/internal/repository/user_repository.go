@@ -1,7 +1,8 @@
 package repository
 
 import (
-	"errors"
+	"context"
+	"time"
 
 	"gorm.io/gorm"
 
@@ -17,33 +18,198 @@ func NewUserRepository(db *gorm.DB) *UserRepository {
 }
 
 // Create создает нового пользователя
-func (r *UserRepository) Create(user *model.User) error {
-	return r.db.Create(user).Error
+func (r *UserRepository) Create(ctx context.Context, user *model.User) error {
+	return r.db.WithContext(ctx).Create(user).Error
+}
+
+// CreateWithPersonalLedger создает пользователя вместе с личным бюджетом: без
+// бюджета пользователь не может работать, а повторная регистрация невозможна
+func (r *UserRepository) CreateWithPersonalLedger(ctx context.Context, user *model.User, ledger *model.Ledger) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		ledger.OwnerID = user.ID
+		ledger.Personal = true
+		return createLedger(tx, ledger)
+	})
 }
 
 // GetByEmail возвращает пользователя по email
-func (r *UserRepository) GetByEmail(email string) (*model.User, error) {
+func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*model.User, error) {
 	var user model.User
-	err := r.db.Where("email = ?", email).First(&user).Error
+	err := r.db.WithContext(ctx).Where("email = ?", email).First(&user).Error
 	if err != nil {
-		return nil, errors.New("user not found")
+		return nil, notFound(err, "user not found")
 	}
 	return &user, nil
 }
 
 // GetByID возвращает пользователя по ID
-func (r *UserRepository) GetByID(id uint) (*model.User, error) {
+func (r *UserRepository) GetByID(ctx context.Context, id uint) (*model.User, error) {
 	var user model.User
-	err := r.db.First(&user, id).Error
+	err := r.db.WithContext(ctx).First(&user, id).Error
 	if err != nil {
-		return nil, errors.New("user not found")
+		return nil, notFound(err, "user not found")
 	}
 	return &user, nil
 }
 
+// Update сохраняет изменения пользователя
+func (r *UserRepository) Update(ctx context.Context, user *model.User) error {
+	return r.db.WithContext(ctx).Save(user).Error
+}
+
 // EmailExists проверяет существование email
-func (r *UserRepository) EmailExists(email string) bool {
+func (r *UserRepository) EmailExists(ctx context.Context, email string) bool {
 	var count int64
-	r.db.Model(&model.User{}).Where("email = ?", email).Count(&count)
+	r.db.WithContext(ctx).Model(&model.User{}).Where("email = ?", email).Count(&count)
 	return count > 0
 }
+
+// GetDueForDeletion возвращает пользователей, срок удаления которых наступил
+func (r *UserRepository) GetDueForDeletion(ctx context.Context, now time.Time) ([]model.User, error) {
+	var users []model.User
+	err := r.db.WithContext(ctx).Where("deletion_scheduled_at <= ?", now).Find(&users).Error
+	return users, err
+}
+
+// GetSoftDeleted возвращает пользователей, мягко удаленных до появления
+// отложенного удаления аккаунта
+func (r *UserRepository) GetSoftDeleted(ctx context.Context) ([]model.User, error) {
+	var users []model.User
+	err := r.db.WithContext(ctx).Unscoped().Where("deleted_at IS NOT NULL").Find(&users).Error
+	return users, err
+}
+
+// RestoreForDeletion восстанавливает мягко удаленного пользователя с удалением,
+// запланированным на deleteAt. false — пользователя уже восстановили
+func (r *UserRepository) RestoreForDeletion(ctx context.Context, id uint, deleteAt time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Unscoped().Model(&model.User{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Updates(map[string]any{"deleted_at": nil, "deletion_scheduled_at": deleteAt})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// HardDelete безвозвратно удаляет пользователя вместе со всеми его данными и
+// возвращает удаленные вложения: их файлы удаляются из хранилища после коммита
+func (r *UserRepository) HardDelete(ctx context.Context, id uint) ([]model.Attachment, error) {
+	var attachments []model.Attachment
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Бюджеты пользователя удаляются целиком, включая данные других участников
+		var ledgerIDs []uint
+		if err := tx.Model(&model.Ledger{}).Where("owner_id = ?", id).Pluck("id", &ledgerIDs).Error; err != nil {
+			return err
+		}
+		if len(ledgerIDs) > 0 {
+			ledgerTransactions := tx.Unscoped().Model(&model.Transaction{}).Select("id").Where("ledger_id IN ?", ledgerIDs)
+			if err := tx.Where("transaction_id IN (?)", ledgerTransactions).Find(&attachments).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("transaction_id IN (?)", ledgerTransactions).Delete(&model.Attachment{}).Error; err != nil {
+				return err
+			}
+		}
+		if err := deleteLedgers(tx, ledgerIDs); err != nil {
+			return err
+		}
+
+		// Журнал изменений удаленных бюджетов больше никому не доступен. В чужих
+		// бюджетах история сохраняется, но без IP удаленного пользователя
+		if len(ledgerIDs) > 0 {
+			if err := tx.Where("ledger_id IN ?", ledgerIDs).Delete(&model.AuditLog{}).Error; err != nil {
+				return err
+			}
+		}
+		err := tx.Where("user_id = ? AND ledger_id NOT IN (SELECT id FROM ledgers)", id).Delete(&model.AuditLog{}).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(&model.AuditLog{}).Where("user_id = ?", id).Update("ip", "").Error; err != nil {
+			return err
+		}
+
+		// Записи в чужих бюджетах остаются у бюджета и переходят его владельцу
+		for _, table := range []string{"transactions", "categories", "payees", "goals", "recurring_items"} {
+			err := tx.Exec("UPDATE "+table+" SET user_id = "+
+				"(SELECT ledgers.owner_id FROM ledgers WHERE ledgers.id = "+table+".ledger_id) "+
+				"WHERE user_id = ?", id).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		err = tx.Exec("UPDATE duplicate_dismissals SET user_id = "+
+			"(SELECT ledgers.owner_id FROM ledgers WHERE ledgers.id = duplicate_dismissals.ledger_id) "+
+			"WHERE user_id = ?", id).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Exec("UPDATE settlements SET created_by_id = "+
+			"(SELECT ledgers.owner_id FROM ledgers WHERE ledgers.id = settlements.ledger_id) "+
+			"WHERE created_by_id = ?", id).Error
+		if err != nil {
+			return err
+		}
+		// Вложения в чужих бюджетах остаются у транзакций и учитываются в квоте
+		// владельца бюджета
+		err = tx.Exec("UPDATE attachments SET user_id = "+
+			"(SELECT ledgers.owner_id FROM transactions JOIN ledgers ON ledgers.id = transactions.ledger_id "+
+			"WHERE transactions.id = attachments.transaction_id) "+
+			"WHERE user_id = ? AND EXISTS (SELECT 1 FROM transactions WHERE transactions.id = attachments.transaction_id)", id).Error
+		if err != nil {
+			return err
+		}
+
+		// Долги с удаленным пользователем погасить некому: его доли, расходы,
+		// которые он оплатил, и возвраты с его участием выходят из расчета. Долги
+		// остальных участников друг другу не меняются
+		err = tx.Exec("DELETE FROM transaction_splits WHERE user_id = ? "+
+			"OR transaction_id IN (SELECT id FROM transactions WHERE paid_by_id = ?)", id, id).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Exec("UPDATE transactions SET paid_by_id = NULL WHERE paid_by_id = ?", id).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("from_user_id = ? OR to_user_id = ?", id, id).Delete(&model.Settlement{}).Error; err != nil {
+			return err
+		}
+
+		err = tx.Model(&model.Alert{}).Where("acknowledged_by_id = ?", id).Update("acknowledged_by_id", nil).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Exec("DELETE FROM rule_tags WHERE rule_id IN (SELECT id FROM rules WHERE user_id = ?)", id).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", id).Delete(&model.Rule{}).Error; err != nil {
+			return err
+		}
+
+		err = tx.Exec("DELETE FROM transaction_tags WHERE tag_id IN (SELECT id FROM tags WHERE user_id = ?)", id).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", id).Delete(&model.Tag{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("user_id = ?", id).Delete(&model.LedgerMember{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("invited_by_id = ?", id).Delete(&model.LedgerInvitation{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&model.User{}, id).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return attachments, nil
+}
@@ -0,0 +1,221 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"finance-backend/internal/model"
+	"finance-backend/internal/repository"
+)
+
+// userColumns столбцы, ссылающиеся на пользователя
+var userColumns = []struct{ table, column string }{
+	{"ledgers", "owner_id"},
+	{"ledger_members", "user_id"},
+	{"ledger_invitations", "invited_by_id"},
+	{"categories", "user_id"},
+	{"tags", "user_id"},
+	{"payees", "user_id"},
+	{"transactions", "user_id"},
+	{"transactions", "paid_by_id"},
+	{"transaction_splits", "user_id"},
+	{"settlements", "from_user_id"},
+	{"settlements", "to_user_id"},
+	{"settlements", "created_by_id"},
+	{"attachments", "user_id"},
+	{"rules", "user_id"},
+	{"duplicate_dismissals", "user_id"},
+	{"audit_logs", "user_id"},
+	{"goals", "user_id"},
+	{"recurring_items", "user_id"},
+	{"alerts", "acknowledged_by_id"},
+	{"users", "id"},
+}
+
+func mustCreate(t *testing.T, db *gorm.DB, value any) {
+	t.Helper()
+	if err := db.Create(value).Error; err != nil {
+		t.Fatalf("create %T: %v", value, err)
+	}
+}
+
+func TestHardDeleteLeavesNoReferences(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, db *gorm.DB) {
+		ctx := context.Background()
+		users := repository.NewUserRepository(db)
+		ledgers := repository.NewLedgerRepository(db)
+		now := time.Now()
+
+		goneID, personalID := newUser(t, db, "gone@example.com")
+		ownerID, sharedID := newUser(t, db, "owner@example.com")
+		friendID, _ := newUser(t, db, "friend@example.com")
+		for _, id := range []uint{goneID, friendID} {
+			if err := ledgers.AddMember(ctx, &model.LedgerMember{LedgerID: sharedID, UserID: id, Role: model.RoleEditor}); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		// Личный бюджет удаляется вместе с вложениями
+		private := newTransaction(t, db, goneID, personalID, "Личное", now)
+		mustCreate(t, db, &model.Attachment{TransactionID: private.ID, UserID: goneID, FileName: "a.pdf",
+			ContentType: "application/pdf", Size: 10, StorageKey: "personal/a", ThumbnailKey: "personal/a.thumb"})
+
+		// Общий бюджет остается: расход, который оплатил удаленный, и расход
+		// владельца, в котором удаленный участвует наравне с другом
+		paidByGone := &model.Transaction{UserID: goneID, LedgerID: sharedID, Amount: 90, Type: "expense",
+			Description: "Ужин", Date: now, PaidByID: &goneID, Splits: []model.TransactionSplit{
+				{UserID: goneID, Amount: 30}, {UserID: ownerID, Amount: 30}, {UserID: friendID, Amount: 30}}}
+		mustCreate(t, db, paidByGone)
+		paidByOwner := &model.Transaction{UserID: ownerID, LedgerID: sharedID, Amount: 60, Type: "expense",
+			Description: "Такси", Date: now, PaidByID: &ownerID, Splits: []model.TransactionSplit{
+				{UserID: goneID, Amount: 20}, {UserID: ownerID, Amount: 20}, {UserID: friendID, Amount: 20}}}
+		mustCreate(t, db, paidByOwner)
+		mustCreate(t, db, &model.Attachment{TransactionID: paidByGone.ID, UserID: goneID, FileName: "b.jpg",
+			ContentType: "image/jpeg", Size: 10, StorageKey: "shared/b"})
+		mustCreate(t, db, &model.Settlement{LedgerID: sharedID, FromUserID: goneID, ToUserID: ownerID, Amount: 5,
+			Date: now, CreatedByID: goneID})
+		mustCreate(t, db, &model.Settlement{LedgerID: sharedID, FromUserID: friendID, ToUserID: ownerID, Amount: 7,
+			Date: now, CreatedByID: goneID})
+		mustCreate(t, db, &model.DuplicateDismissal{LedgerID: sharedID, TransactionID: paidByGone.ID,
+			OtherID: paidByOwner.ID, UserID: goneID})
+		mustCreate(t, db, &model.Category{UserID: goneID, LedgerID: sharedID, Name: "Еда", Type: "expense"})
+		newTag(t, db, goneID, "отпуск")
+
+		attachments, err := users.HardDelete(ctx, goneID)
+		if err != nil {
+			t.Fatalf("HardDelete: %v", err)
+		}
+		if len(attachments) != 1 || attachments[0].StorageKey != "personal/a" {
+			t.Errorf("deleted attachments = %+v, want the personal one", attachments)
+		}
+
+		for _, c := range userColumns {
+			var count int64
+			if err := db.Table(c.table).Where(c.column+" = ?", goneID).Count(&count).Error; err != nil {
+				t.Fatalf("%s.%s: %v", c.table, c.column, err)
+			}
+			if count != 0 {
+				t.Errorf("%d rows of %s.%s still reference the deleted user", count, c.table, c.column)
+			}
+		}
+
+		var shared model.Attachment
+		if err := db.Where("storage_key = ?", "shared/b").First(&shared).Error; err != nil {
+			t.Fatalf("attachment in the shared ledger: %v", err)
+		}
+		if shared.UserID != ownerID {
+			t.Errorf("shared attachment user = %d, want the ledger owner %d", shared.UserID, ownerID)
+		}
+
+		// Долг друга владельцу за такси и его возврат остаются
+		debts, err := repository.NewSplitRepository(db).GetLedgerDebts(ctx, ownerID, sharedID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(debts) != 2 {
+			t.Errorf("debts = %+v, want the owner's taxi split only", debts)
+		}
+		for _, d := range debts {
+			if d.PaidByID != ownerID || d.Amount != 20 {
+				t.Errorf("unexpected debt %+v", d)
+			}
+		}
+		settlements, err := repository.NewSplitRepository(db).GetSettlements(ctx, ownerID, sharedID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(settlements) != 1 || settlements[0].FromUserID != friendID || settlements[0].CreatedByID != ownerID {
+			t.Errorf("settlements = %+v, want the friend's one created by the owner", settlements)
+		}
+	})
+}
+
+func TestRestoreForDeletion(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, db *gorm.DB) {
+		ctx := context.Background()
+		repo := repository.NewUserRepository(db)
+		userID, _ := newUser(t, db, "legacy@example.com")
+		if err := db.Delete(&model.User{}, userID).Error; err != nil {
+			t.Fatal(err)
+		}
+
+		// Мягко удаленные не удаляются окончательно без льготного периода
+		due, err := repo.GetDueForDeletion(ctx, time.Now().Add(365*24*time.Hour))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(due) != 0 {
+			t.Fatalf("due for deletion: %+v, want none", due)
+		}
+
+		legacy, err := repo.GetSoftDeleted(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(legacy) != 1 || legacy[0].ID != userID {
+			t.Fatalf("soft deleted = %+v, want the legacy user", legacy)
+		}
+
+		deleteAt := time.Now().Add(30 * 24 * time.Hour)
+		restored, err := repo.RestoreForDeletion(ctx, userID, deleteAt)
+		if err != nil || !restored {
+			t.Fatalf("RestoreForDeletion = %v, %v", restored, err)
+		}
+		if restored, err := repo.RestoreForDeletion(ctx, userID, deleteAt); err != nil || restored {
+			t.Errorf("second RestoreForDeletion = %v, %v, want false", restored, err)
+		}
+
+		user, err := repo.GetByID(ctx, userID)
+		if err != nil {
+			t.Fatalf("restored user: %v", err)
+		}
+		if user.DeletionScheduledAt == nil || user.DeletionScheduledAt.Sub(deleteAt).Abs() > time.Millisecond {
+			t.Errorf("deletion scheduled at %v, want %v", user.DeletionScheduledAt, deleteAt)
+		}
+		due, err = repo.GetDueForDeletion(ctx, deleteAt.Add(time.Minute))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(due) != 1 {
+			t.Errorf("due after the grace period: %+v, want the legacy user", due)
+		}
+	})
+}
+
+func TestCreateWithPersonalLedger(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, db *gorm.DB) {
+		ctx := context.Background()
+		repo := repository.NewUserRepository(db)
+
+		user := &model.User{Email: "new@example.com", Password: "hash", FirstName: "New", LastName: "User"}
+		ledger := &model.Ledger{Name: "Personal"}
+		if err := repo.CreateWithPersonalLedger(ctx, user, ledger); err != nil {
+			t.Fatal(err)
+		}
+		personal, err := repository.NewLedgerRepository(db).GetPersonal(ctx, user.ID)
+		if err != nil || personal.ID != ledger.ID {
+			t.Fatalf("GetPersonal = %+v, %v, want ledger %d", personal, err, ledger.ID)
+		}
+		member, err := repository.NewLedgerRepository(db).GetMember(ctx, ledger.ID, user.ID)
+		if err != nil || member.Role != model.RoleOwner {
+			t.Errorf("owner membership = %+v, %v", member, err)
+		}
+
+		// Второй личный бюджет запрещен индексом; вместе с ним откатывается и пользователь
+		var nextID uint
+		if err := db.Model(&model.User{}).Select("MAX(id) + 1").Scan(&nextID).Error; err != nil {
+			t.Fatal(err)
+		}
+		mustCreate(t, db, &model.Ledger{Name: "Personal", OwnerID: nextID, Personal: true})
+		failed := &model.User{Email: "retry@example.com", Password: "hash", FirstName: "Retry", LastName: "User"}
+		if err := repo.CreateWithPersonalLedger(ctx, failed, &model.Ledger{Name: "Personal"}); err == nil {
+			t.Fatalf("created user %d with a second personal ledger", failed.ID)
+		}
+		if repo.EmailExists(ctx, "retry@example.com") {
+			t.Error("user stayed after the personal ledger failed")
+		}
+	})
+}
@@ -0,0 +1,274 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"finance-backend/internal/dto"
+	"finance-backend/internal/mailer"
+	"finance-backend/internal/model"
+	"finance-backend/internal/repository"
+)
+
+type AccountService struct {
+	userRepo        *repository.UserRepository
+	ledgerRepo      *repository.LedgerRepository
+	categoryRepo    *repository.CategoryRepository
+	transactionRepo *repository.TransactionRepository
+	tagRepo         *repository.TagRepository
+	authService     *AuthService
+	attachments     *AttachmentService
+	mailer          mailer.Mailer
+	gracePeriod     time.Duration
+}
+
+func NewAccountService(
+	ur *repository.UserRepository,
+	lr *repository.LedgerRepository,
+	cr *repository.CategoryRepository,
+	tr *repository.TransactionRepository,
+	tgr *repository.TagRepository,
+	authService *AuthService,
+	as *AttachmentService,
+	m mailer.Mailer,
+	gracePeriod time.Duration,
+) *AccountService {
+	return &AccountService{
+		userRepo:        ur,
+		ledgerRepo:      lr,
+		categoryRepo:    cr,
+		transactionRepo: tr,
+		tagRepo:         tgr,
+		authService:     authService,
+		attachments:     as,
+		mailer:          m,
+		gracePeriod:     gracePeriod,
+	}
+}
+
+// Export собирает полный архив категорий и транзакций всех бюджетов пользователя
+func (s *AccountService) Export(ctx context.Context, userID uint) (*dto.AccountExport, error) {
+	ctx, span := tracer.Start(ctx, "AccountService.Export")
+	defer span.End()
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	memberships, err := s.ledgerRepo.GetMemberships(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := s.tagRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	export := &dto.AccountExport{
+		ExportedAt: time.Now(),
+		User: dto.AuthUser{
+			ID:            user.ID,
+			Email:         user.Email,
+			FirstName:     user.FirstName,
+			LastName:      user.LastName,
+			EmailVerified: user.EmailVerified,
+		},
+		Tags:    make([]dto.TagResponse, 0, len(tags)),
+		Ledgers: make([]dto.ExportLedger, 0, len(memberships)),
+	}
+	export.Tags = append(export.Tags, newTagResponses(tags)...)
+
+	for _, m := range memberships {
+		ledger, err := s.exportLedger(ctx, userID, m.LedgerID)
+		if err != nil {
+			return nil, err
+		}
+		ledger.Role = m.Role
+		if m.Ledger != nil {
+			ledger.Name = m.Ledger.Name
+			ledger.Personal = m.Ledger.Personal
+		}
+		export.Ledgers = append(export.Ledgers, *ledger)
+	}
+
+	return export, nil
+}
+
+// exportLedger выгружает категории и транзакции одного бюджета
+func (s *AccountService) exportLedger(ctx context.Context, userID, ledgerID uint) (*dto.ExportLedger, error) {
+	categories, err := s.categoryRepo.GetByLedger(ctx, userID, ledgerID)
+	if err != nil {
+		return nil, err
+	}
+
+	transactions, err := s.transactionRepo.GetByLedger(ctx, userID, ledgerID, dto.TransactionFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	ledger := &dto.ExportLedger{
+		ID:           ledgerID,
+		Categories:   make([]dto.ExportCategory, 0, len(categories)),
+		Transactions: make([]dto.ExportTransaction, 0, len(transactions)),
+	}
+
+	for _, c := range categories {
+		ledger.Categories = append(ledger.Categories, dto.ExportCategory{
+			ID:        c.ID,
+			Name:      c.Name,
+			Type:      c.Type,
+			Color:     c.Color,
+			CreatedAt: c.CreatedAt,
+		})
+	}
+
+	for _, t := range transactions {
+		categoryName := ""
+		if t.Category != nil {
+			categoryName = t.Category.Name
+		}
+
+		payeeName := ""
+		if t.Payee != nil {
+			payeeName = t.Payee.Name
+		}
+
+		var splits []dto.SplitResponse
+		for _, sp := range t.Splits {
+			splits = append(splits, dto.SplitResponse{UserID: sp.UserID, Amount: sp.Amount})
+		}
+
+		var tagNames []string
+		for _, tag := range t.Tags {
+			tagNames = append(tagNames, tag.Name)
+		}
+
+		ledger.Transactions = append(ledger.Transactions, dto.ExportTransaction{
+			ID:           t.ID,
+			CategoryID:   t.CategoryID,
+			CategoryName: categoryName,
+			PayeeName:    payeeName,
+			Amount:       t.Amount,
+			Type:         t.Type,
+			Description:  t.Description,
+			Notes:        t.Notes,
+			Date:         t.Date,
+			CreatedAt:    t.CreatedAt,
+			PaidByID:     t.PaidByID,
+			Splits:       splits,
+			Tags:         tagNames,
+		})
+	}
+
+	return ledger, nil
+}
+
+// ScheduleDeletion помечает аккаунт на удаление по истечении льготного периода
+func (s *AccountService) ScheduleDeletion(ctx context.Context, userID uint, req dto.DeleteAccountRequest) (time.Time, error) {
+	ctx, span := tracer.Start(ctx, "AccountService.ScheduleDeletion")
+	defer span.End()
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if !s.authService.CheckPassword(req.Password, user.Password) {
+		return time.Time{}, errors.New("invalid password")
+	}
+	if user.DeletionScheduledAt != nil {
+		return *user.DeletionScheduledAt, nil
+	}
+
+	deleteAt := time.Now().Add(s.gracePeriod)
+	user.DeletionScheduledAt = &deleteAt
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return time.Time{}, fmt.Errorf("failed to update user: %w", err)
+	}
+
+	s.notifyDeletion(ctx, user, deleteAt)
+	return deleteAt, nil
+}
+
+// notifyDeletion сообщает пользователю дату удаления аккаунта
+func (s *AccountService) notifyDeletion(ctx context.Context, user *model.User, deleteAt time.Time) {
+	body := fmt.Sprintf("Hello, %s!\n\n"+
+		"Your account and all of its data will be permanently deleted on %s.\n"+
+		"Until then you can download a full export of your data or cancel the deletion in your profile.\n",
+		user.FirstName, deleteAt.Format("2006-01-02"))
+	if err := s.mailer.Send(user.Email, "Your account is scheduled for deletion", body); err != nil {
+		slog.WarnContext(ctx, "failed to notify user about account deletion", "user_id", user.ID, "error", err)
+	}
+}
+
+// CancelDeletion отменяет запланированное удаление аккаунта
+func (s *AccountService) CancelDeletion(ctx context.Context, userID uint) error {
+	ctx, span := tracer.Start(ctx, "AccountService.CancelDeletion")
+	defer span.End()
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if user.DeletionScheduledAt == nil {
+		return errors.New("account is not scheduled for deletion")
+	}
+
+	user.DeletionScheduledAt = nil
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+	return nil
+}
+
+// PurgeDeleted безвозвратно удаляет аккаунты, льготный период которых истек
+func (s *AccountService) PurgeDeleted(ctx context.Context) error {
+	ctx, span := tracer.Start(ctx, "AccountService.PurgeDeleted")
+	defer span.End()
+
+	if err := s.scheduleSoftDeleted(ctx); err != nil {
+		return err
+	}
+
+	users, err := s.userRepo.GetDueForDeletion(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+
+	for _, u := range users {
+		attachments, err := s.userRepo.HardDelete(ctx, u.ID)
+		if err != nil {
+			return fmt.Errorf("delete user %d: %w", u.ID, err)
+		}
+		s.attachments.DeleteFiles(ctx, attachments)
+		slog.InfoContext(ctx, "account permanently deleted", "user_id", u.ID)
+	}
+	return nil
+}
+
+// scheduleSoftDeleted переводит пользователей, мягко удаленных прежней версией,
+// на отложенное удаление: до него они, как и все, могут выгрузить данные
+func (s *AccountService) scheduleSoftDeleted(ctx context.Context) error {
+	users, err := s.userRepo.GetSoftDeleted(ctx)
+	if err != nil {
+		return err
+	}
+
+	deleteAt := time.Now().Add(s.gracePeriod)
+	for i := range users {
+		restored, err := s.userRepo.RestoreForDeletion(ctx, users[i].ID, deleteAt)
+		if err != nil {
+			return fmt.Errorf("schedule deletion of user %d: %w", users[i].ID, err)
+		}
+		if restored {
+			s.notifyDeletion(ctx, &users[i], deleteAt)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,262 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"sort"
+	"time"
+
+	"finance-backend/internal/dto"
+	"finance-backend/internal/model"
+	"finance-backend/internal/repository"
+)
+
+const (
+	// anomalyLookback какие недавно добавленные транзакции проверяются при каждом запуске;
+	// с запасом, чтобы не пропустить транзакции после простоя
+	anomalyLookback = 24 * time.Hour
+	// anomalyHistoryMonths за сколько месяцев строится обычное распределение трат
+	anomalyHistoryMonths = 12
+	// outlierSigmas во сколько стандартных отклонений сумма должна превышать среднее
+	outlierSigmas = 3.0
+	// outlierMinSamples минимум прошлых трат категории для сравнения
+	outlierMinSamples = 5
+	// newPayeeQuantile какая доля трат бюджета меньше «крупной» суммы
+	newPayeeQuantile = 0.9
+	// newPayeeMinSamples минимум трат бюджета для оценки крупной суммы
+	newPayeeMinSamples = 10
+	// paceFactor во сколько раз траты с начала месяца должны опережать обычный темп
+	paceFactor = 1.5
+	// paceMinShare минимальная доля обычной месячной суммы, чтобы не шуметь в начале месяца
+	paceMinShare = 0.25
+	// paceMinMonths минимум прошлых месяцев для оценки обычного темпа
+	paceMinMonths = 3
+)
+
+type AnomalyService struct {
+	alertRepo     *repository.AlertRepository
+	ledgerService *LedgerService
+}
+
+func NewAnomalyService(ar *repository.AlertRepository, ls *LedgerService) *AnomalyService {
+	return &AnomalyService{
+		alertRepo:     ar,
+		ledgerService: ls,
+	}
+}
+
+// DetectAnomalies проверяет недавно добавленные транзакции всех бюджетов и
+// записывает предупреждения о необычных тратах. Запускается фоновой задачей
+func (s *AnomalyService) DetectAnomalies(ctx context.Context) error {
+	ctx, span := tracer.Start(ctx, "AnomalyService.DetectAnomalies")
+	defer span.End()
+
+	now := time.Now()
+	ledgers, err := s.alertRepo.GetActiveLedgers(ctx, now.Add(-anomalyLookback))
+	if err != nil {
+		return err
+	}
+
+	var created int64
+	for _, ledgerID := range ledgers {
+		history, err := s.alertRepo.GetExpensesSince(ctx, ledgerID, now.AddDate(0, -anomalyHistoryMonths, 0))
+		if err != nil {
+			return fmt.Errorf("ledger %d: %w", ledgerID, err)
+		}
+
+		alerts := detectAnomalies(ledgerID, history, now)
+		n, err := s.alertRepo.Create(ctx, alerts)
+		if err != nil {
+			return fmt.Errorf("ledger %d: %w", ledgerID, err)
+		}
+		created += n
+	}
+
+	if created > 0 {
+		slog.InfoContext(ctx, "anomaly detection finished", "new_alerts", created)
+	}
+	return nil
+}
+
+// GetAlerts возвращает предупреждения бюджета
+func (s *AnomalyService) GetAlerts(ctx context.Context, userID, ledgerID uint, query dto.AlertQuery) ([]model.Alert, error) {
+	ctx, span := tracer.Start(ctx, "AnomalyService.GetAlerts")
+	defer span.End()
+
+	return s.alertRepo.GetByLedger(ctx, userID, ledgerID, query.All)
+}
+
+// AcknowledgeAlert отмечает предупреждение как просмотренное для всех участников бюджета
+func (s *AnomalyService) AcknowledgeAlert(ctx context.Context, userID, ledgerID, id uint) (*model.Alert, error) {
+	ctx, span := tracer.Start(ctx, "AnomalyService.AcknowledgeAlert")
+	defer span.End()
+
+	if err := s.ledgerService.CheckWriteAccess(ctx, userID, ledgerID); err != nil {
+		return nil, err
+	}
+	return s.alertRepo.Acknowledge(ctx, userID, ledgerID, id)
+}
+
+// detectAnomalies ищет необычные траты среди добавленных за anomalyLookback.
+// history — расходы бюджета за anomalyHistoryMonths, упорядоченные по дате
+func detectAnomalies(ledgerID uint, history []model.Transaction, now time.Time) []model.Alert {
+	since := now.Add(-anomalyLookback)
+
+	byCategory := make(map[uint][]*model.Transaction)
+	seenKeys := make(map[string]int)
+	amounts := make([]float64, 0, len(history))
+	for i := range history {
+		t := &history[i]
+		if t.CategoryID != nil {
+			byCategory[*t.CategoryID] = append(byCategory[*t.CategoryID], t)
+		}
+		if key := subscriptionKey(t); key != "" {
+			seenKeys[key]++
+		}
+		amounts = append(amounts, t.Amount)
+	}
+	sort.Float64s(amounts)
+
+	var alerts []model.Alert
+	recentCategories := make(map[uint]bool)
+	for i := range history {
+		t := &history[i]
+		if t.CreatedAt.Before(since) {
+			continue
+		}
+
+		if t.CategoryID != nil {
+			recentCategories[*t.CategoryID] = true
+			if alert, ok := amountOutlier(ledgerID, t, byCategory[*t.CategoryID]); ok {
+				alerts = append(alerts, alert)
+			}
+		}
+
+		// Получатель встречается впервые за период истории, и сумма крупная для бюджета
+		key := subscriptionKey(t)
+		if key != "" && seenKeys[key] == 1 && len(amounts) >= newPayeeMinSamples {
+			large := quantile(amounts, newPayeeQuantile)
+			if t.Amount >= large {
+				id := t.ID
+				alerts = append(alerts, model.Alert{
+					LedgerID:      ledgerID,
+					Key:           fmt.Sprintf("%s:%d", model.AlertNewPayee, t.ID),
+					Kind:          model.AlertNewPayee,
+					TransactionID: &id,
+					CategoryID:    t.CategoryID,
+					PayeeID:       t.PayeeID,
+					Amount:        t.Amount,
+					Expected:      roundMoney(large),
+					Message:       fmt.Sprintf("First payment of %.2f to %q", t.Amount, t.Description),
+				})
+			}
+		}
+	}
+
+	for categoryID := range recentCategories {
+		if alert, ok := categoryPace(ledgerID, categoryID, byCategory[categoryID], now); ok {
+			alerts = append(alerts, alert)
+		}
+	}
+	return alerts
+}
+
+// amountOutlier сравнивает трату с остальными тратами категории
+func amountOutlier(ledgerID uint, t *model.Transaction, category []*model.Transaction) (model.Alert, bool) {
+	others := make([]float64, 0, len(category))
+	for _, other := range category {
+		if other.ID != t.ID {
+			others = append(others, other.Amount)
+		}
+	}
+	if len(others) < outlierMinSamples {
+		return model.Alert{}, false
+	}
+
+	var sum float64
+	for _, v := range others {
+		sum += v
+	}
+	mean := sum / float64(len(others))
+	var squares float64
+	for _, v := range others {
+		squares += (v - mean) * (v - mean)
+	}
+	stdDev := math.Sqrt(squares / float64(len(others)-1))
+
+	// Требуем и заметного превышения медианы, чтобы не реагировать на почти одинаковые траты
+	threshold := mean + outlierSigmas*stdDev
+	typical := median(others)
+	if t.Amount <= threshold || t.Amount <= 2*typical {
+		return model.Alert{}, false
+	}
+
+	id := t.ID
+	return model.Alert{
+		LedgerID:      ledgerID,
+		Key:           fmt.Sprintf("%s:%d", model.AlertAmountOutlier, t.ID),
+		Kind:          model.AlertAmountOutlier,
+		TransactionID: &id,
+		CategoryID:    t.CategoryID,
+		PayeeID:       t.PayeeID,
+		Amount:        t.Amount,
+		Expected:      roundMoney(typical),
+		Message:       fmt.Sprintf("%.2f is far above the usual %.2f for this category", t.Amount, typical),
+	}, true
+}
+
+// categoryPace сравнивает траты категории с начала месяца с обычным месячным темпом
+func categoryPace(ledgerID, categoryID uint, category []*model.Transaction, now time.Time) (model.Alert, bool) {
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	if len(category) == 0 {
+		return model.Alert{}, false
+	}
+
+	// Прошлые полные месяцы, начиная с первого месяца с тратами
+	first := category[0].Date
+	firstMonth := time.Date(first.Year(), first.Month(), 1, 0, 0, 0, 0, now.Location())
+	months := 0
+	for m := firstMonth; m.Before(monthStart); m = m.AddDate(0, 1, 0) {
+		months++
+	}
+	if months < paceMinMonths {
+		return model.Alert{}, false
+	}
+
+	var past, monthToDate float64
+	for _, t := range category {
+		if t.Date.Before(monthStart) {
+			past += t.Amount
+		} else if !t.Date.After(now) {
+			monthToDate += t.Amount
+		}
+	}
+	typical := past / float64(months)
+
+	elapsed := now.Sub(monthStart).Hours() / 24
+	daysInMonth := monthStart.AddDate(0, 1, 0).Sub(monthStart).Hours() / 24
+	expected := typical * elapsed / daysInMonth
+	if typical <= 0 || monthToDate <= paceFactor*expected || monthToDate <= paceMinShare*typical {
+		return model.Alert{}, false
+	}
+
+	id := categoryID
+	return model.Alert{
+		LedgerID:   ledgerID,
+		Key:        fmt.Sprintf("%s:%d:%s", model.AlertCategoryPace, categoryID, monthStart.Format("2006-01")),
+		Kind:       model.AlertCategoryPace,
+		CategoryID: &id,
+		Amount:     roundMoney(monthToDate),
+		Expected:   roundMoney(expected),
+		Message: fmt.Sprintf("Spent %.2f this month, usually %.2f by this day (%.2f per month)",
+			monthToDate, expected, typical),
+	}, true
+}
+
+// quantile возвращает квантиль q отсортированной выборки
+func quantile(sorted []float64, q float64) float64 {
+	index := int(math.Ceil(q*float64(len(sorted)))) - 1
+	return sorted[max(index, 0)]
+}
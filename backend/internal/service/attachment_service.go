@@ -0,0 +1,319 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"finance-backend/internal/dto"
+	"finance-backend/internal/model"
+	"finance-backend/internal/repository"
+	"finance-backend/internal/storage"
+	"finance-backend/internal/thumbnail"
+)
+
+const thumbnailSize = 256
+
+var (
+	ErrFileTooLarge      = errors.New("file is too large")
+	ErrQuotaExceeded     = errors.New("attachment storage quota exceeded")
+	ErrUnsupportedFile   = errors.New("unsupported file type")
+	errThumbnailNotFound = errors.New("thumbnail not found")
+)
+
+// allowedContentTypes типы файлов, которые можно прикладывать к транзакциям
+var allowedContentTypes = map[string]bool{
+	"image/jpeg":      true,
+	"image/png":       true,
+	"image/gif":       true,
+	"image/webp":      true,
+	"application/pdf": true,
+}
+
+// thumbnailTypes изображения, для которых умеем строить миниатюры
+var thumbnailTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+}
+
+type AttachmentService struct {
+	attachmentRepo  *repository.AttachmentRepository
+	transactionRepo *repository.TransactionRepository
+	ledgerService   *LedgerService
+	storage         storage.Storage
+	maxFileSize     int64
+	userQuota       int64
+}
+
+func NewAttachmentService(
+	ar *repository.AttachmentRepository,
+	tr *repository.TransactionRepository,
+	ls *LedgerService,
+	st storage.Storage,
+	maxFileSize int64,
+	userQuota int64,
+) *AttachmentService {
+	return &AttachmentService{
+		attachmentRepo:  ar,
+		transactionRepo: tr,
+		ledgerService:   ls,
+		storage:         st,
+		maxFileSize:     maxFileSize,
+		userQuota:       userQuota,
+	}
+}
+
+// MaxFileSize возвращает максимальный размер одного файла
+func (s *AttachmentService) MaxFileSize() int64 {
+	return s.maxFileSize
+}
+
+// Upload прикладывает файл к транзакции
+func (s *AttachmentService) Upload(ctx context.Context, userID, ledgerID, transactionID uint, fileName string, r io.Reader) (*dto.AttachmentResponse, error) {
+	ctx, span := tracer.Start(ctx, "AttachmentService.Upload")
+	defer span.End()
+
+	if err := s.ledgerService.CheckWriteAccess(ctx, userID, ledgerID); err != nil {
+		return nil, err
+	}
+	if _, err := s.transactionRepo.GetByID(ctx, userID, ledgerID, transactionID); err != nil {
+		return nil, err
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r, s.maxFileSize+1))
+	if err != nil {
+		return nil, errors.New("failed to read file")
+	}
+	if int64(len(data)) > s.maxFileSize {
+		return nil, ErrFileTooLarge
+	}
+	if len(data) == 0 {
+		return nil, errors.New("file is empty")
+	}
+
+	// Тип определяем по содержимому, а не по имени или заголовку клиента
+	contentType := strings.Split(http.DetectContentType(data), ";")[0]
+	if !allowedContentTypes[contentType] {
+		return nil, ErrUnsupportedFile
+	}
+
+	// Предварительная проверка избавляет от записи файла, который заведомо не
+	// поместится; окончательная выполняется вместе с сохранением записи
+	used, err := s.attachmentRepo.TotalSizeByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if used+int64(len(data)) > s.userQuota {
+		return nil, ErrQuotaExceeded
+	}
+
+	key, err := newStorageKey(ledgerID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.storage.Put(ctx, key, bytes.NewReader(data), int64(len(data)), contentType); err != nil {
+		return nil, fmt.Errorf("failed to store file: %w", err)
+	}
+
+	attachment := &model.Attachment{
+		TransactionID: transactionID,
+		UserID:        userID,
+		FileName:      sanitizeFileName(fileName),
+		ContentType:   contentType,
+		Size:          int64(len(data)),
+		StorageKey:    key,
+	}
+
+	// Миниатюра не обязательна: битое изображение все равно сохраняем как файл
+	if thumbnailTypes[contentType] {
+		if thumb, err := thumbnail.Generate(data, thumbnailSize); err == nil {
+			thumbKey := key + "_thumb.jpg"
+			if err := s.storage.Put(ctx, thumbKey, bytes.NewReader(thumb), int64(len(thumb)), "image/jpeg"); err == nil {
+				attachment.ThumbnailKey = thumbKey
+			} else {
+				slog.WarnContext(ctx, "failed to store thumbnail", "key", key, "error", err)
+			}
+		}
+	}
+
+	created, err := s.attachmentRepo.CreateWithinQuota(ctx, attachment, s.userQuota)
+	if err != nil {
+		s.deleteBlobs(ctx, attachment)
+		return nil, fmt.Errorf("failed to save attachment: %w", err)
+	}
+	if !created {
+		s.deleteBlobs(ctx, attachment)
+		return nil, ErrQuotaExceeded
+	}
+
+	response := newAttachmentResponse(attachment)
+	return &response, nil
+}
+
+// GetTransactionAttachments возвращает вложения транзакции
+func (s *AttachmentService) GetTransactionAttachments(ctx context.Context, userID, ledgerID, transactionID uint) ([]dto.AttachmentResponse, error) {
+	ctx, span := tracer.Start(ctx, "AttachmentService.GetTransactionAttachments")
+	defer span.End()
+
+	if _, err := s.transactionRepo.GetByID(ctx, userID, ledgerID, transactionID); err != nil {
+		return nil, err
+	}
+
+	attachments, err := s.attachmentRepo.GetByTransaction(ctx, userID, ledgerID, transactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dto.AttachmentResponse, 0, len(attachments))
+	for i := range attachments {
+		response = append(response, newAttachmentResponse(&attachments[i]))
+	}
+	return response, nil
+}
+
+// Open возвращает вложение и поток с его содержимым
+func (s *AttachmentService) Open(ctx context.Context, userID, ledgerID, id uint, thumb bool) (*model.Attachment, io.ReadCloser, error) {
+	ctx, span := tracer.Start(ctx, "AttachmentService.Open")
+	defer span.End()
+
+	attachment, err := s.attachmentRepo.GetByID(ctx, userID, ledgerID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	key := attachment.StorageKey
+	if thumb {
+		if attachment.ThumbnailKey == "" {
+			return nil, nil, errThumbnailNotFound
+		}
+		key = attachment.ThumbnailKey
+	}
+
+	body, err := s.storage.Get(ctx, key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, nil, errors.New("attachment not found")
+		}
+		return nil, nil, err
+	}
+	return attachment, body, nil
+}
+
+// Delete удаляет вложение вместе с файлами
+func (s *AttachmentService) Delete(ctx context.Context, userID, ledgerID, id uint) error {
+	ctx, span := tracer.Start(ctx, "AttachmentService.Delete")
+	defer span.End()
+
+	if err := s.ledgerService.CheckWriteAccess(ctx, userID, ledgerID); err != nil {
+		return err
+	}
+
+	attachment, err := s.attachmentRepo.GetByID(ctx, userID, ledgerID, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.attachmentRepo.Delete(ctx, attachment.ID); err != nil {
+		return err
+	}
+	s.deleteBlobs(ctx, attachment)
+	return nil
+}
+
+// CleanupOrphans удаляет файлы вложений, транзакции которых уже удалены
+func (s *AttachmentService) CleanupOrphans(ctx context.Context) error {
+	ctx, span := tracer.Start(ctx, "AttachmentService.CleanupOrphans")
+	defer span.End()
+
+	for {
+		orphans, err := s.attachmentRepo.GetOrphans(ctx, 100)
+		if err != nil {
+			return err
+		}
+		if len(orphans) == 0 {
+			return nil
+		}
+
+		for i := range orphans {
+			if err := s.storage.Delete(ctx, orphans[i].StorageKey); err != nil {
+				return err
+			}
+			if orphans[i].ThumbnailKey != "" {
+				if err := s.storage.Delete(ctx, orphans[i].ThumbnailKey); err != nil {
+					return err
+				}
+			}
+			if err := s.attachmentRepo.Delete(ctx, orphans[i].ID); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// DeleteFiles удаляет из хранилища файлы вложений, записи которых уже удалены
+func (s *AttachmentService) DeleteFiles(ctx context.Context, attachments []model.Attachment) {
+	ctx, span := tracer.Start(ctx, "AttachmentService.DeleteFiles")
+	defer span.End()
+
+	for i := range attachments {
+		s.deleteBlobs(ctx, &attachments[i])
+	}
+}
+
+// deleteBlobs удаляет файлы вложения из хранилища; ошибки только логируются,
+// оставшиеся файлы не видны пользователю
+func (s *AttachmentService) deleteBlobs(ctx context.Context, attachment *model.Attachment) {
+	for _, key := range []string{attachment.StorageKey, attachment.ThumbnailKey} {
+		if key == "" {
+			continue
+		}
+		if err := s.storage.Delete(ctx, key); err != nil {
+			slog.WarnContext(ctx, "failed to delete file from storage", "key", key, "error", err)
+		}
+	}
+}
+
+func newAttachmentResponse(a *model.Attachment) dto.AttachmentResponse {
+	return dto.AttachmentResponse{
+		ID:            a.ID,
+		TransactionID: a.TransactionID,
+		FileName:      a.FileName,
+		ContentType:   a.ContentType,
+		Size:          a.Size,
+		HasThumbnail:  a.ThumbnailKey != "",
+		CreatedAt:     a.CreatedAt,
+	}
+}
+
+// newStorageKey генерирует непредсказуемый ключ объекта в хранилище
+func newStorageKey(ledgerID uint) (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("attachments/%d/%s", ledgerID, hex.EncodeToString(buf)), nil
+}
+
+// sanitizeFileName оставляет только имя файла без пути и управляющих символов
+func sanitizeFileName(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || r == '"' {
+			return -1
+		}
+		return r
+	}, name)
+	if name == "" || name == "." || name == "/" {
+		return "file"
+	}
+	return name
+}
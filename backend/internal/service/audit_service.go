@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"reflect"
+	"time"
+
+	"finance-backend/internal/dto"
+	"finance-backend/internal/model"
+	"finance-backend/internal/repository"
+)
+
+const defaultAuditLimit = 50
+
+type clientIPKey struct{}
+
+// WithClientIP сохраняет в контексте IP клиента для журнала изменений
+func WithClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, clientIPKey{}, ip)
+}
+
+func clientIP(ctx context.Context) string {
+	ip, _ := ctx.Value(clientIPKey{}).(string)
+	return ip
+}
+
+type AuditService struct {
+	auditRepo *repository.AuditRepository
+}
+
+func NewAuditService(ar *repository.AuditRepository) *AuditService {
+	return &AuditService{auditRepo: ar}
+}
+
+// Record добавляет в журнал изменение сущности. before пуст при создании, after —
+// при удалении. Изменение без разницы в полях не записывается. Ошибка журнала
+// не отменяет уже сохраненное изменение, поэтому только логируется
+func (s *AuditService) Record(ctx context.Context, userID, ledgerID uint, entityType string, entityID uint, action string, before, after any) {
+	ctx, span := tracer.Start(ctx, "AuditService.Record")
+	defer span.End()
+
+	entry := &model.AuditLog{
+		UserID:     userID,
+		LedgerID:   ledgerID,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Action:     action,
+		IP:         clientIP(ctx),
+	}
+
+	var err error
+	if entry.Before, err = marshalSnapshot(before); err != nil {
+		slog.ErrorContext(ctx, "failed to record audit entry", "entity_type", entityType, "entity_id", entityID, "error", err)
+		return
+	}
+	if entry.After, err = marshalSnapshot(after); err != nil {
+		slog.ErrorContext(ctx, "failed to record audit entry", "entity_type", entityType, "entity_id", entityID, "error", err)
+		return
+	}
+	if entry.Before != "" && entry.After != "" {
+		changes := diffSnapshots(entry.Before, entry.After)
+		if len(changes) == 0 {
+			return
+		}
+		encoded, _ := json.Marshal(changes)
+		entry.Changes = string(encoded)
+	}
+
+	if err := s.auditRepo.Create(ctx, entry); err != nil {
+		slog.ErrorContext(ctx, "failed to record audit entry", "entity_type", entityType, "entity_id", entityID, "error", err)
+	}
+}
+
+// GetEntityHistory возвращает историю изменений сущности бюджета
+func (s *AuditService) GetEntityHistory(ctx context.Context, ledgerID uint, entityType string, entityID uint, query dto.AuditQuery) (*dto.AuditResponse, error) {
+	ctx, span := tracer.Start(ctx, "AuditService.GetEntityHistory")
+	defer span.End()
+
+	entries, total, err := s.auditRepo.GetEntityHistory(ctx, ledgerID, entityType, entityID, auditLimit(query), query.Offset)
+	if err != nil {
+		return nil, err
+	}
+	return newAuditResponse(entries, total), nil
+}
+
+// GetActivity возвращает ленту изменений, сделанных пользователем во всех бюджетах
+func (s *AuditService) GetActivity(ctx context.Context, userID uint, query dto.AuditQuery) (*dto.AuditResponse, error) {
+	ctx, span := tracer.Start(ctx, "AuditService.GetActivity")
+	defer span.End()
+
+	entries, total, err := s.auditRepo.GetByUser(ctx, userID, auditLimit(query), query.Offset)
+	if err != nil {
+		return nil, err
+	}
+	return newAuditResponse(entries, total), nil
+}
+
+// Снимки сущностей для журнала: только собственные поля, без связей. Теги
+// личные у каждого участника, поэтому в общую историю не попадают
+
+type transactionSnapshot struct {
+	Amount      float64   `json:"amount"`
+	Type        string    `json:"type"`
+	Description string    `json:"description"`
+	Notes       string    `json:"notes"`
+	Date        time.Time `json:"date"`
+	CategoryID  *uint     `json:"category_id"`
+	PayeeID     *uint     `json:"payee_id"`
+	PaidByID    *uint     `json:"paid_by_id"`
+}
+
+type categorySnapshot struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Color string `json:"color"`
+}
+
+type ledgerSnapshot struct {
+	Name    string `json:"name"`
+	OwnerID uint   `json:"owner_id"`
+}
+
+func auditTransaction(t *model.Transaction) *transactionSnapshot {
+	return &transactionSnapshot{
+		Amount:      t.Amount,
+		Type:        t.Type,
+		Description: t.Description,
+		Notes:       t.Notes,
+		Date:        t.Date.UTC(),
+		CategoryID:  t.CategoryID,
+		PayeeID:     t.PayeeID,
+		PaidByID:    t.PaidByID,
+	}
+}
+
+func auditCategory(c *model.Category) *categorySnapshot {
+	return &categorySnapshot{Name: c.Name, Type: c.Type, Color: c.Color}
+}
+
+func auditLedger(l *model.Ledger) *ledgerSnapshot {
+	return &ledgerSnapshot{Name: l.Name, OwnerID: l.OwnerID}
+}
+
+// marshalSnapshot кодирует снимок в JSON; отсутствующий снимок — пустая строка
+func marshalSnapshot(snapshot any) (string, error) {
+	if snapshot == nil || reflect.ValueOf(snapshot).IsNil() {
+		return "", nil
+	}
+	encoded, err := json.Marshal(snapshot)
+	return string(encoded), err
+}
+
+// diffSnapshots возвращает поля, значения которых различаются в двух снимках
+func diffSnapshots(before, after string) map[string]dto.AuditChange {
+	var old, updated map[string]any
+	if json.Unmarshal([]byte(before), &old) != nil || json.Unmarshal([]byte(after), &updated) != nil {
+		return nil
+	}
+
+	changes := make(map[string]dto.AuditChange)
+	for field, value := range updated {
+		if !reflect.DeepEqual(old[field], value) {
+			changes[field] = dto.AuditChange{Old: old[field], New: value}
+		}
+	}
+	for field, value := range old {
+		if _, ok := updated[field]; !ok {
+			changes[field] = dto.AuditChange{Old: value}
+		}
+	}
+	return changes
+}
+
+func auditLimit(query dto.AuditQuery) int {
+	if query.Limit <= 0 {
+		return defaultAuditLimit
+	}
+	return query.Limit
+}
+
+func newAuditResponse(entries []model.AuditLog, total int64) *dto.AuditResponse {
+	response := &dto.AuditResponse{Total: total, Entries: make([]dto.AuditEntryResponse, 0, len(entries))}
+	for _, e := range entries {
+		entry := dto.AuditEntryResponse{
+			ID:         e.ID,
+			UserID:     e.UserID,
+			LedgerID:   e.LedgerID,
+			EntityType: e.EntityType,
+			EntityID:   e.EntityID,
+			Action:     e.Action,
+			Before:     rawSnapshot(e.Before),
+			After:      rawSnapshot(e.After),
+			IP:         e.IP,
+			CreatedAt:  e.CreatedAt,
+		}
+		if e.Changes != "" {
+			json.Unmarshal([]byte(e.Changes), &entry.Changes)
+		}
+		response.Entries = append(response.Entries, entry)
+	}
+	return response
+}
+
+func rawSnapshot(s string) json.RawMessage {
+	if s == "" {
+		return json.RawMessage("null")
+	}
+	return json.RawMessage(s)
+}
@@ -10,12 +10,19 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+// Назначения одноразовых токенов, отправляемых по почте
+const (
+	PurposeEmailVerification = "email_verification"
+	PurposeEmailChange       = "email_change"
+)
+
 type AuthService struct {
 	jwtSecret string
+	tokenTTL  time.Duration // срок жизни токена входа
 }
 
-func NewAuthService(jwtSecret string) *AuthService {
-	return &AuthService{jwtSecret: jwtSecret}
+func NewAuthService(jwtSecret string, tokenTTL time.Duration) *AuthService {
+	return &AuthService{jwtSecret: jwtSecret, tokenTTL: tokenTTL}
 }
 
 // HashPassword хеширует пароль
@@ -35,7 +42,7 @@ func (s *AuthService) GenerateToken(user *model.User) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
 		"user_id": user.ID,
 		"email":   user.Email,
-		"exp":     time.Now().Add(time.Hour * 72).Unix(), // 3 дня
+		"exp":     time.Now().Add(s.tokenTTL).Unix(),
 	})
 
 	return token.SignedString([]byte(s.jwtSecret))
@@ -52,9 +59,54 @@ func (s *AuthService) ValidateToken(tokenString string) (uint, error) {
 	}
 
 	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		userID := uint(claims["user_id"].(float64))
-		return userID, nil
+		// Токены из писем не должны работать как токены доступа
+		if _, hasPurpose := claims["purpose"]; hasPurpose {
+			return 0, errors.New("invalid token")
+		}
+		userID, ok := claims["user_id"].(float64)
+		if !ok {
+			return 0, errors.New("invalid token")
+		}
+		return uint(userID), nil
 	}
 
 	return 0, errors.New("invalid token")
 }
+
+// GenerateEmailToken создает подписанный токен для ссылки из письма
+func (s *AuthService) GenerateEmailToken(purpose string, userID uint, email string, ttl time.Duration) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": userID,
+		"email":   email,
+		"purpose": purpose,
+		"exp":     time.Now().Add(ttl).Unix(),
+	})
+
+	return token.SignedString([]byte(s.jwtSecret))
+}
+
+// ParseEmailToken проверяет токен из письма и возвращает ID пользователя и email
+func (s *AuthService) ParseEmailToken(purpose, tokenString string) (uint, string, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("unexpected signing method")
+		}
+		return []byte(s.jwtSecret), nil
+	})
+	if err != nil {
+		return 0, "", errors.New("invalid or expired token")
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid || claims["purpose"] != purpose {
+		return 0, "", errors.New("invalid or expired token")
+	}
+
+	userID, ok := claims["user_id"].(float64)
+	if !ok {
+		return 0, "", errors.New("invalid or expired token")
+	}
+	email, _ := claims["email"].(string)
+
+	return uint(userID), email, nil
+}
@@ -1,38 +1,76 @@
 package service
 
 import (
+	"context"
+
 	"finance-backend/internal/dto"
 	"finance-backend/internal/model"
-	"finance-backend/internal/repository"
 )
 
 type CategoryService struct {
-	categoryRepo *repository.CategoryRepository
+	categoryRepo  CategoryRepository
+	ledgerService LedgerAccess
+	audit         AuditRecorder
 }
 
-func NewCategoryService(cr *repository.CategoryRepository) *CategoryService {
-	return &CategoryService{categoryRepo: cr}
+func NewCategoryService(cr CategoryRepository, ls LedgerAccess, as AuditRecorder) *CategoryService {
+	return &CategoryService{
+		categoryRepo:  cr,
+		ledgerService: ls,
+		audit:         as,
+	}
 }
 
 // CreateCategory создает новую категорию
-func (s *CategoryService) CreateCategory(userID uint, req dto.CreateCategoryRequest) (*model.Category, error) {
+func (s *CategoryService) CreateCategory(ctx context.Context, userID, ledgerID uint, req dto.CreateCategoryRequest) (*model.Category, error) {
+	ctx, span := tracer.Start(ctx, "CategoryService.CreateCategory")
+	defer span.End()
+
+	if err := s.ledgerService.CheckWriteAccess(ctx, userID, ledgerID); err != nil {
+		return nil, err
+	}
+
 	category := &model.Category{
-		UserID: userID,
-		Name:   req.Name,
-		Type:   req.Type,
-		Color:  req.Color,
+		UserID:   userID,
+		LedgerID: ledgerID,
+		Name:     req.Name,
+		Type:     req.Type,
+		Color:    req.Color,
 	}
 
-	err := s.categoryRepo.Create(category)
-	return category, err
+	if err := s.categoryRepo.Create(ctx, category); err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, userID, ledgerID, model.AuditEntityCategory, category.ID, model.AuditActionCreate,
+		nil, auditCategory(category))
+	return category, nil
 }
 
-// GetUserCategories возвращает категории пользователя
-func (s *CategoryService) GetUserCategories(userID uint) ([]model.Category, error) {
-	return s.categoryRepo.GetByUserID(userID)
+// GetLedgerCategories возвращает категории бюджета
+func (s *CategoryService) GetLedgerCategories(ctx context.Context, userID, ledgerID uint) ([]model.Category, error) {
+	ctx, span := tracer.Start(ctx, "CategoryService.GetLedgerCategories")
+	defer span.End()
+
+	return s.categoryRepo.GetByLedger(ctx, userID, ledgerID)
 }
 
 // DeleteCategory удаляет категорию
-func (s *CategoryService) DeleteCategory(userID uint, id uint) error {
-	return s.categoryRepo.Delete(userID, id)
+func (s *CategoryService) DeleteCategory(ctx context.Context, userID, ledgerID uint, id uint) error {
+	ctx, span := tracer.Start(ctx, "CategoryService.DeleteCategory")
+	defer span.End()
+
+	if err := s.ledgerService.CheckWriteAccess(ctx, userID, ledgerID); err != nil {
+		return err
+	}
+
+	category, err := s.categoryRepo.GetByID(ctx, userID, ledgerID, id)
+	if err != nil {
+		return err
+	}
+	if err := s.categoryRepo.Delete(ctx, userID, ledgerID, id); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, userID, ledgerID, model.AuditEntityCategory, id, model.AuditActionDelete,
+		auditCategory(category), nil)
+	return nil
 }
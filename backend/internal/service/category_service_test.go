@@ -0,0 +1,84 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"finance-backend/internal/dto"
+	"finance-backend/internal/model"
+	"finance-backend/internal/service"
+)
+
+func TestCategoryLifecycle(t *testing.T) {
+	e := newEnv()
+	ctx := context.Background()
+	owner := register(t, e, "owner@example.com")
+	viewer := register(t, e, "viewer@example.com")
+	stranger := register(t, e, "stranger@example.com")
+	ledgerID := owner.ID
+	e.ledgers.addMember(ledgerID, viewer.ID, model.RoleViewer)
+
+	category, err := e.cats.CreateCategory(ctx, owner.ID, ledgerID, dto.CreateCategoryRequest{Name: "Транспорт", Type: "expense"})
+	if err != nil {
+		t.Fatalf("CreateCategory: %v", err)
+	}
+	if category.Color != "#6B7280" {
+		t.Errorf("default color = %q", category.Color)
+	}
+
+	if _, err := e.cats.CreateCategory(ctx, viewer.ID, ledgerID, dto.CreateCategoryRequest{Name: "x", Type: "expense"}); !errors.Is(err, service.ErrForbidden) {
+		t.Errorf("CreateCategory by viewer: %v, want ErrForbidden", err)
+	}
+	if _, err := e.cats.CreateCategory(ctx, stranger.ID, ledgerID, dto.CreateCategoryRequest{Name: "x", Type: "expense"}); err == nil {
+		t.Error("CreateCategory by non-member succeeded")
+	}
+
+	if list, _ := e.cats.GetLedgerCategories(ctx, viewer.ID, ledgerID); len(list) != 1 {
+		t.Errorf("viewer sees %d categories, want 1", len(list))
+	}
+	if list, _ := e.cats.GetLedgerCategories(ctx, stranger.ID, ledgerID); len(list) != 0 {
+		t.Errorf("non-member sees %d categories", len(list))
+	}
+
+	if err := e.cats.DeleteCategory(ctx, viewer.ID, ledgerID, category.ID); !errors.Is(err, service.ErrForbidden) {
+		t.Errorf("DeleteCategory by viewer: %v, want ErrForbidden", err)
+	}
+	if err := e.cats.DeleteCategory(ctx, owner.ID, ledgerID, category.ID); err != nil {
+		t.Fatalf("DeleteCategory: %v", err)
+	}
+	if err := e.cats.DeleteCategory(ctx, owner.ID, ledgerID, category.ID); err == nil || err.Error() != "category not found" {
+		t.Errorf("second DeleteCategory: %v", err)
+	}
+	if list, _ := e.cats.GetLedgerCategories(ctx, owner.ID, ledgerID); len(list) != 0 {
+		t.Errorf("deleted category is still listed: %+v", list)
+	}
+
+	want := []string{
+		model.AuditEntityCategory + ":" + model.AuditActionCreate,
+		model.AuditEntityCategory + ":" + model.AuditActionDelete,
+	}
+	if len(e.audit.actions) != len(want) || e.audit.actions[0] != want[0] || e.audit.actions[1] != want[1] {
+		t.Errorf("audit = %v, want %v", e.audit.actions, want)
+	}
+}
+
+func TestDeletedCategoryRejectedForTransactions(t *testing.T) {
+	e := newEnv()
+	ctx := context.Background()
+	user := register(t, e, "anna@example.com")
+	category, err := e.cats.CreateCategory(ctx, user.ID, user.ID, dto.CreateCategoryRequest{Name: "Кафе", Type: "expense"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.cats.DeleteCategory(ctx, user.ID, user.ID, category.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = e.txs.CreateTransaction(ctx, user.ID, user.ID, dto.CreateTransactionRequest{
+		Amount: 1, Type: "expense", Description: "x", Date: "2026-03-10T10:00:00Z", CategoryID: &category.ID,
+	})
+	if err == nil || err.Error() != "category not found" {
+		t.Errorf("CreateTransaction with a deleted category: %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"finance-backend/internal/dto"
+	"finance-backend/internal/model"
+	"finance-backend/internal/repository"
+)
+
+// Хранилища, с которыми работают сервисы транзакций, категорий и пользователей.
+// Реализации: repository (GORM) и repository/memory (в памяти, для тестов)
+
+type TransactionRepository interface {
+	Create(ctx context.Context, transaction *model.Transaction) error
+	GetByID(ctx context.Context, userID, ledgerID uint, id uint) (*model.Transaction, error)
+	GetByLedger(ctx context.Context, userID, ledgerID uint, filter dto.TransactionFilter) ([]model.Transaction, error)
+	GetFinancialSummary(ctx context.Context, userID, ledgerID uint, from, to *time.Time) (*dto.FinancialSummary, error)
+	Update(ctx context.Context, transaction *model.Transaction) error
+	UpdateWithTags(ctx context.Context, userID uint, transaction *model.Transaction, tagIDs []uint) error
+	Delete(ctx context.Context, userID, ledgerID uint, id uint) error
+}
+
+type CategoryRepository interface {
+	Create(ctx context.Context, category *model.Category) error
+	GetByLedger(ctx context.Context, userID, ledgerID uint) ([]model.Category, error)
+	GetByID(ctx context.Context, userID, ledgerID uint, id uint) (*model.Category, error)
+	Delete(ctx context.Context, userID, ledgerID uint, id uint) error
+}
+
+type TagRepository interface {
+	GetByIDs(ctx context.Context, userID uint, ids []uint) ([]model.Tag, error)
+}
+
+type UserRepository interface {
+	CreateWithPersonalLedger(ctx context.Context, user *model.User, ledger *model.Ledger) error
+	GetByEmail(ctx context.Context, email string) (*model.User, error)
+	GetByID(ctx context.Context, id uint) (*model.User, error)
+	Update(ctx context.Context, user *model.User) error
+	EmailExists(ctx context.Context, email string) bool
+}
+
+// Соседние сервисы, от которых зависят те же сервисы. В тестах их можно
+// заменить заглушками, не поднимая базу
+
+// LedgerAccess проверяет право на изменение данных бюджета
+type LedgerAccess interface {
+	CheckWriteAccess(ctx context.Context, userID, ledgerID uint) error
+}
+
+// AuditRecorder записывает изменения в журнал бюджета
+type AuditRecorder interface {
+	Record(ctx context.Context, userID, ledgerID uint, entityType string, entityID uint, action string, before, after any)
+}
+
+// RuleApplier применяет правила пользователя к новой транзакции
+type RuleApplier interface {
+	ApplyRules(ctx context.Context, userID uint, transaction *model.Transaction) error
+}
+
+// CategoryLearner обучает подсказки категорий на транзакциях
+type CategoryLearner interface {
+	Learn(t *model.Transaction)
+	Forget(t *model.Transaction)
+}
+
+// PayeeResolver привязывает транзакцию к получателю
+type PayeeResolver interface {
+	ApplyPayee(ctx context.Context, userID uint, transaction *model.Transaction, payeeID *uint, rawDescription string) error
+	ValidatePayee(ctx context.Context, userID, ledgerID uint, payeeID *uint) error
+}
+
+// DuplicateFinder ищет возможные дубликаты транзакции
+type DuplicateFinder interface {
+	FindFor(ctx context.Context, userID uint, transaction *model.Transaction) ([]uint, error)
+}
+
+var (
+	_ TransactionRepository = (*repository.TransactionRepository)(nil)
+	_ CategoryRepository    = (*repository.CategoryRepository)(nil)
+	_ TagRepository         = (*repository.TagRepository)(nil)
+	_ UserRepository        = (*repository.UserRepository)(nil)
+
+	_ LedgerAccess    = (*LedgerService)(nil)
+	_ AuditRecorder   = (*AuditService)(nil)
+	_ RuleApplier     = (*RuleService)(nil)
+	_ CategoryLearner = (*SuggestionService)(nil)
+	_ PayeeResolver   = (*PayeeService)(nil)
+	_ DuplicateFinder = (*DuplicateService)(nil)
+)
@@ -0,0 +1,267 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"time"
+
+	"finance-backend/internal/dto"
+	"finance-backend/internal/model"
+	"finance-backend/internal/repository"
+)
+
+const (
+	// duplicateWindow максимальная разница дат у дубликатов: банк может провести
+	// операцию на несколько дней позже ручной записи
+	duplicateWindow = 3 * 24 * time.Hour
+	// duplicateThreshold минимальная схожесть описаний
+	duplicateThreshold = 0.6
+)
+
+type DuplicateService struct {
+	duplicateRepo   *repository.DuplicateRepository
+	transactionRepo *repository.TransactionRepository
+	ledgerService   *LedgerService
+	suggestions     *SuggestionService
+	audit           *AuditService
+}
+
+func NewDuplicateService(
+	dr *repository.DuplicateRepository,
+	tr *repository.TransactionRepository,
+	ls *LedgerService,
+	ss *SuggestionService,
+	as *AuditService,
+) *DuplicateService {
+	return &DuplicateService{
+		duplicateRepo:   dr,
+		transactionRepo: tr,
+		ledgerService:   ls,
+		suggestions:     ss,
+		audit:           as,
+	}
+}
+
+// FindDuplicates ищет в бюджете пары похожих транзакций, кроме отклоненных пользователем
+func (s *DuplicateService) FindDuplicates(ctx context.Context, userID, ledgerID uint, from, to *time.Time) ([]dto.DuplicateCandidate, error) {
+	ctx, span := tracer.Start(ctx, "DuplicateService.FindDuplicates")
+	defer span.End()
+
+	rows, err := s.duplicateRepo.GetScanRows(ctx, userID, ledgerID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	dismissed, err := s.duplicateRepo.GetDismissed(ctx, ledgerID)
+	if err != nil {
+		return nil, err
+	}
+
+	type pair struct {
+		a, b       uint
+		similarity float64
+		daysApart  int
+	}
+	var pairs []pair
+
+	// Строки отсортированы по типу, сумме и дате: сравниваем каждую только с
+	// последующими в пределах окна
+	for i := range rows {
+		for j := i + 1; j < len(rows); j++ {
+			a, b := &rows[i], &rows[j]
+			if a.Type != b.Type || a.Amount != b.Amount || b.Date.Sub(a.Date) > duplicateWindow {
+				break
+			}
+			if dismissed[duplicatePair(a.ID, b.ID)] {
+				continue
+			}
+			if similarity, ok := duplicateSimilarity(a, b); ok {
+				pairs = append(pairs, pair{a.ID, b.ID, similarity, daysApart(a.Date, b.Date)})
+			}
+		}
+	}
+
+	candidates := make([]dto.DuplicateCandidate, 0, len(pairs))
+	if len(pairs) == 0 {
+		return candidates, nil
+	}
+
+	ids := make([]uint, 0, len(pairs)*2)
+	for _, p := range pairs {
+		ids = append(ids, p.a, p.b)
+	}
+	transactions, err := s.transactionRepo.GetByIDs(ctx, userID, ledgerID, ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]dto.TransactionResponse, len(transactions))
+	for i := range transactions {
+		byID[transactions[i].ID] = newTransactionResponse(&transactions[i])
+	}
+
+	for _, p := range pairs {
+		candidates = append(candidates, dto.DuplicateCandidate{
+			Transaction: byID[p.a],
+			Duplicate:   byID[p.b],
+			Similarity:  math.Round(p.similarity*100) / 100,
+			DaysApart:   p.daysApart,
+		})
+	}
+	return candidates, nil
+}
+
+// FindFor возвращает ID транзакций, дубликатом которых может быть новая транзакция
+func (s *DuplicateService) FindFor(ctx context.Context, userID uint, transaction *model.Transaction) ([]uint, error) {
+	ctx, span := tracer.Start(ctx, "DuplicateService.FindFor")
+	defer span.End()
+
+	near, err := s.duplicateRepo.GetNear(ctx, userID, transaction, duplicateWindow)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []uint
+	for i := range near {
+		if _, ok := duplicateSimilarity(transaction, &near[i]); ok {
+			ids = append(ids, near[i].ID)
+		}
+	}
+	return ids, nil
+}
+
+// Dismiss отмечает пару как не дубликат, чтобы она больше не предлагалась
+func (s *DuplicateService) Dismiss(ctx context.Context, userID, ledgerID uint, req dto.DismissDuplicateRequest) error {
+	ctx, span := tracer.Start(ctx, "DuplicateService.Dismiss")
+	defer span.End()
+
+	if err := s.ledgerService.CheckWriteAccess(ctx, userID, ledgerID); err != nil {
+		return err
+	}
+	if req.TransactionID == req.OtherID {
+		return errors.New("transactions must be different")
+	}
+	for _, id := range []uint{req.TransactionID, req.OtherID} {
+		if _, err := s.transactionRepo.GetByID(ctx, userID, ledgerID, id); err != nil {
+			return err
+		}
+	}
+
+	p := duplicatePair(req.TransactionID, req.OtherID)
+	return s.duplicateRepo.Dismiss(ctx, &model.DuplicateDismissal{
+		LedgerID:      ledgerID,
+		TransactionID: p[0],
+		OtherID:       p[1],
+		UserID:        userID,
+	})
+}
+
+// Merge объединяет дубликаты: недостающие поля, теги и вложения удаляемой
+// транзакции переходят к оставляемой
+func (s *DuplicateService) Merge(ctx context.Context, userID, ledgerID uint, req dto.MergeDuplicateRequest) (*dto.TransactionResponse, error) {
+	ctx, span := tracer.Start(ctx, "DuplicateService.Merge")
+	defer span.End()
+
+	if err := s.ledgerService.CheckWriteAccess(ctx, userID, ledgerID); err != nil {
+		return nil, err
+	}
+	if req.KeepID == req.RemoveID {
+		return nil, errors.New("transactions must be different")
+	}
+
+	keep, err := s.transactionRepo.GetByID(ctx, userID, ledgerID, req.KeepID)
+	if err != nil {
+		return nil, err
+	}
+	remove, err := s.transactionRepo.GetByID(ctx, userID, ledgerID, req.RemoveID)
+	if err != nil {
+		return nil, err
+	}
+	if keep.Type != remove.Type {
+		return nil, errors.New("cannot merge income with expense")
+	}
+	// Доли общего расхода не переносятся, поэтому оставлять нужно его
+	if remove.PaidByID != nil && keep.PaidByID == nil {
+		return nil, errors.New("keep the split expense and remove the regular transaction")
+	}
+
+	previous := *keep
+	if keep.CategoryID == nil {
+		keep.CategoryID = remove.CategoryID
+	}
+	if keep.PayeeID == nil {
+		keep.PayeeID = remove.PayeeID
+	}
+	if remove.Notes != "" && !strings.Contains(keep.Notes, remove.Notes) {
+		keep.Notes = strings.TrimSpace(keep.Notes + "\n" + remove.Notes)
+	}
+
+	if err := s.duplicateRepo.Merge(ctx, keep, remove.ID); err != nil {
+		return nil, err
+	}
+	s.suggestions.Forget(remove)
+	s.suggestions.Forget(&previous)
+	s.suggestions.Learn(keep)
+	s.audit.Record(ctx, userID, ledgerID, model.AuditEntityTransaction, keep.ID, model.AuditActionUpdate,
+		auditTransaction(&previous), auditTransaction(keep))
+	s.audit.Record(ctx, userID, ledgerID, model.AuditEntityTransaction, remove.ID, model.AuditActionDelete,
+		auditTransaction(remove), nil)
+
+	merged, err := s.transactionRepo.GetByIDs(ctx, userID, ledgerID, []uint{keep.ID})
+	if err != nil {
+		return nil, err
+	}
+	if len(merged) == 0 {
+		return nil, errors.New("transaction not found")
+	}
+	response := newTransactionResponse(&merged[0])
+	return &response, nil
+}
+
+// duplicateSimilarity сравнивает описания; общий получатель считается сильным признаком
+func duplicateSimilarity(a, b *model.Transaction) (float64, bool) {
+	similarity := stringSimilarity(normalizePayeeText(a.Description), normalizePayeeText(b.Description))
+	if a.PayeeID != nil && b.PayeeID != nil && *a.PayeeID == *b.PayeeID {
+		similarity = math.Max(similarity, 0.9)
+	}
+	return similarity, similarity >= duplicateThreshold
+}
+
+// stringSimilarity возвращает 1 - расстояние Левенштейна, деленное на длину большей строки
+func stringSimilarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 0 && len(rb) == 0 {
+		return 1
+	}
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return 1 - float64(prev[len(rb)])/float64(max(len(ra), len(rb)))
+}
+
+// duplicatePair упорядочивает пару, чтобы (a, b) и (b, a) совпадали
+func duplicatePair(a, b uint) [2]uint {
+	if a > b {
+		a, b = b, a
+	}
+	return [2]uint{a, b}
+}
+
+func daysApart(a, b time.Time) int {
+	return int(math.Round(math.Abs(b.Sub(a).Hours()) / 24))
+}
@@ -0,0 +1,263 @@
+package service
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"finance-backend/internal/dto"
+	"finance-backend/internal/model"
+	"finance-backend/internal/repository"
+)
+
+const (
+	defaultForecastMonths = 3
+	// forecastHistoryMonths за сколько последних месяцев считаются средние по категориям
+	forecastHistoryMonths = 6
+	// forecastBandSigmas ширина полосы оптимистичной и пессимистичной оценки в стандартных отклонениях
+	forecastBandSigmas = 1.0
+)
+
+type ForecastService struct {
+	forecastRepo  *repository.ForecastRepository
+	categoryRepo  *repository.CategoryRepository
+	recurringRepo *repository.RecurringRepository
+}
+
+func NewForecastService(fr *repository.ForecastRepository, cr *repository.CategoryRepository, rr *repository.RecurringRepository) *ForecastService {
+	return &ForecastService{
+		forecastRepo:  fr,
+		categoryRepo:  cr,
+		recurringRepo: rr,
+	}
+}
+
+// categoryKey категория и тип оборота; транзакции без категории группируются по типу
+type categoryKey struct {
+	categoryID uint
+	txType     string
+}
+
+// Forecast прогнозирует ежедневный остаток бюджета на months месяцев вперед:
+// известные будущие транзакции и регулярные платежи учитываются в свои даты,
+// остальные доходы и расходы — как средние по категориям за последние месяцы.
+// Разброс месячных сумм по категориям задает полосы оптимистичной и пессимистичной оценки
+func (s *ForecastService) Forecast(ctx context.Context, userID, ledgerID uint, query dto.ForecastQuery) (*dto.ForecastResponse, error) {
+	ctx, span := tracer.Start(ctx, "ForecastService.Forecast")
+	defer span.End()
+
+	months := query.Months
+	if months <= 0 {
+		months = defaultForecastMonths
+	}
+
+	now := time.Now()
+	balance, err := s.forecastRepo.GetBalance(ctx, userID, ledgerID, now)
+	if err != nil {
+		return nil, err
+	}
+	history, err := s.forecastRepo.GetBetween(ctx, userID, ledgerID, now.AddDate(0, -forecastHistoryMonths, 0), now)
+	if err != nil {
+		return nil, err
+	}
+	categories, err := s.categoryRepo.GetByLedger(ctx, userID, ledgerID)
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.recurringRepo.GetByLedger(ctx, userID, ledgerID)
+	if err != nil {
+		return nil, err
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	end := today.AddDate(0, months, 0)
+	scheduled, err := s.forecastRepo.GetBetween(ctx, userID, ledgerID, now, end)
+	if err != nil {
+		return nil, err
+	}
+
+	// Регулярные платежи идут в прогноз по своим датам, поэтому их прошлые
+	// списания не должны попадать в средние по категориям
+	tracked := make(map[string]bool, len(items))
+	for _, item := range items {
+		if item.MatchKey != "" {
+			tracked[item.MatchKey] = true
+		}
+		scheduled = append(scheduled, recurringOccurrences(&item, now, end)...)
+	}
+	sort.SliceStable(scheduled, func(i, j int) bool { return scheduled[i].Date.Before(scheduled[j].Date) })
+
+	discretionary := make([]model.Transaction, 0, len(history))
+	for i := range history {
+		if !tracked[subscriptionKey(&history[i])] {
+			discretionary = append(discretionary, history[i])
+		}
+	}
+
+	response := &dto.ForecastResponse{StartBalance: roundMoney(balance)}
+	stats, historyMonths := monthlyCategoryStats(discretionary, now)
+	response.HistoryMonths = historyMonths
+
+	names := make(map[uint]string, len(categories))
+	for _, c := range categories {
+		names[c.ID] = c.Name
+	}
+
+	// Ожидаемый дневной оборот и дисперсия дневной суммы
+	var dailyNet, dailyVariance float64
+	response.Categories = make([]dto.ForecastCategory, 0, len(stats))
+	for _, st := range stats {
+		sign := -1.0
+		if st.key.txType == "income" {
+			sign = 1
+		}
+		dailyNet += sign * st.mean / daysPerMonth
+		dailyVariance += st.stdDev * st.stdDev / daysPerMonth
+
+		category := dto.ForecastCategory{
+			Name:           names[st.key.categoryID],
+			Type:           st.key.txType,
+			MonthlyAverage: roundMoney(st.mean),
+			MonthlyStdDev:  roundMoney(st.stdDev),
+		}
+		if st.key.categoryID != 0 {
+			id := st.key.categoryID
+			category.CategoryID = &id
+			if category.Name == "" {
+				category.Name = "Deleted category"
+			}
+		} else {
+			category.Name = "Uncategorized"
+		}
+		response.Categories = append(response.Categories, category)
+	}
+
+	expected := balance
+	variance := 0.0
+	next := 0
+	boundary := now
+	for day := today; day.Before(end); day = day.AddDate(0, 0, 1) {
+		dayEnd := day.AddDate(0, 0, 1)
+
+		// Известные транзакции до конца дня
+		var dayScheduled float64
+		for next < len(scheduled) && scheduled[next].Date.Before(dayEnd) {
+			dayScheduled += signedAmount(&scheduled[next])
+			next++
+		}
+
+		// Сегодня учитывается только оставшаяся часть дня
+		fraction := dayEnd.Sub(boundary).Hours() / 24
+		boundary = dayEnd
+		expected += dailyNet*fraction + dayScheduled
+		variance += dailyVariance * fraction
+		band := forecastBandSigmas * math.Sqrt(variance)
+
+		point := dto.ForecastDay{
+			Date:        day,
+			Expected:    roundMoney(expected),
+			Optimistic:  roundMoney(expected + band),
+			Pessimistic: roundMoney(expected - band),
+			Scheduled:   roundMoney(dayScheduled),
+		}
+		response.Days = append(response.Days, point)
+
+		if len(response.Days) == 1 || point.Expected < response.LowestExpected {
+			response.LowestExpected = point.Expected
+			response.LowestExpectedDate = day
+		}
+		if point.Expected < 0 && response.FirstNegativeDate == nil {
+			response.FirstNegativeDate = &point.Date
+		}
+		if point.Pessimistic < 0 && response.FirstRiskDate == nil {
+			response.FirstRiskDate = &point.Date
+		}
+	}
+	return response, nil
+}
+
+type categoryStats struct {
+	key    categoryKey
+	mean   float64
+	stdDev float64
+}
+
+// monthlyCategoryStats делит историю на скользящие месяцы, отсчитанные назад от now,
+// и считает по каждой категории среднюю месячную сумму и ее стандартное отклонение.
+// Если история короче forecastHistoryMonths, используются только месяцы, в которые она была
+func monthlyCategoryStats(history []model.Transaction, now time.Time) ([]categoryStats, int) {
+	if len(history) == 0 {
+		return nil, 0
+	}
+
+	monthIndex := func(date time.Time) int {
+		return min(int(now.Sub(date).Hours()/24/daysPerMonth), forecastHistoryMonths-1)
+	}
+	months := monthIndex(history[0].Date) + 1
+
+	sums := make(map[categoryKey][]float64)
+	for i := range history {
+		t := &history[i]
+		key := categoryKey{txType: t.Type}
+		if t.CategoryID != nil {
+			key.categoryID = *t.CategoryID
+		}
+		if sums[key] == nil {
+			sums[key] = make([]float64, months)
+		}
+		sums[key][monthIndex(t.Date)] += t.Amount
+	}
+
+	stats := make([]categoryStats, 0, len(sums))
+	for key, monthly := range sums {
+		var total float64
+		for _, v := range monthly {
+			total += v
+		}
+		mean := total / float64(months)
+
+		var squares float64
+		for _, v := range monthly {
+			squares += (v - mean) * (v - mean)
+		}
+		stdDev := 0.0
+		if months > 1 {
+			stdDev = math.Sqrt(squares / float64(months-1))
+		}
+		stats = append(stats, categoryStats{key: key, mean: mean, stdDev: stdDev})
+	}
+
+	// Крупные статьи первыми
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].mean != stats[j].mean {
+			return stats[i].mean > stats[j].mean
+		}
+		return stats[i].key.categoryID < stats[j].key.categoryID
+	})
+	return stats, months
+}
+
+// recurringOccurrences возвращает платежи регулярной статьи в полуинтервале (from, to)
+func recurringOccurrences(item *model.RecurringItem, from, to time.Time) []model.Transaction {
+	var occurrences []model.Transaction
+	for date := item.NextDate; date.Before(to); date = advancePeriod(date, item.Period) {
+		if date.After(from) {
+			occurrences = append(occurrences, model.Transaction{
+				CategoryID: item.CategoryID,
+				Amount:     item.Amount,
+				Type:       item.Type,
+				Date:       date,
+			})
+		}
+	}
+	return occurrences
+}
+
+// signedAmount возвращает сумму транзакции со знаком: доход положительный, расход отрицательный
+func signedAmount(t *model.Transaction) float64 {
+	if t.Type == "income" {
+		return t.Amount
+	}
+	return -t.Amount
+}
@@ -0,0 +1,232 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"finance-backend/internal/dto"
+	"finance-backend/internal/model"
+	"finance-backend/internal/repository"
+)
+
+const (
+	// goalPaceWindow период, по которому считается текущий темп взносов
+	goalPaceWindow = 90 * 24 * time.Hour
+	// daysPerMonth средняя длина месяца в днях
+	daysPerMonth = 365.25 / 12
+)
+
+type GoalService struct {
+	goalRepo      *repository.GoalRepository
+	categoryRepo  *repository.CategoryRepository
+	ledgerService *LedgerService
+}
+
+func NewGoalService(gr *repository.GoalRepository, cr *repository.CategoryRepository, ls *LedgerService) *GoalService {
+	return &GoalService{
+		goalRepo:      gr,
+		categoryRepo:  cr,
+		ledgerService: ls,
+	}
+}
+
+// CreateGoal создает цель накопления в бюджете
+func (s *GoalService) CreateGoal(ctx context.Context, userID, ledgerID uint, req dto.GoalRequest) (*dto.GoalResponse, error) {
+	ctx, span := tracer.Start(ctx, "GoalService.CreateGoal")
+	defer span.End()
+
+	if err := s.ledgerService.CheckWriteAccess(ctx, userID, ledgerID); err != nil {
+		return nil, err
+	}
+
+	goal := &model.Goal{UserID: userID, LedgerID: ledgerID, StartDate: time.Now()}
+	if err := s.fillGoal(ctx, userID, ledgerID, goal, req); err != nil {
+		return nil, err
+	}
+	if err := s.goalRepo.Create(ctx, goal); err != nil {
+		return nil, fmt.Errorf("failed to create goal: %w", err)
+	}
+	return s.newGoalResponse(ctx, goal, time.Now())
+}
+
+// GetLedgerGoals возвращает цели бюджета с прогрессом
+func (s *GoalService) GetLedgerGoals(ctx context.Context, userID, ledgerID uint) ([]dto.GoalResponse, error) {
+	ctx, span := tracer.Start(ctx, "GoalService.GetLedgerGoals")
+	defer span.End()
+
+	goals, err := s.goalRepo.GetByLedger(ctx, userID, ledgerID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	response := make([]dto.GoalResponse, 0, len(goals))
+	for i := range goals {
+		goal, err := s.newGoalResponse(ctx, &goals[i], now)
+		if err != nil {
+			return nil, err
+		}
+		response = append(response, *goal)
+	}
+	return response, nil
+}
+
+// GetGoal возвращает цель с прогрессом
+func (s *GoalService) GetGoal(ctx context.Context, userID, ledgerID, id uint) (*dto.GoalResponse, error) {
+	ctx, span := tracer.Start(ctx, "GoalService.GetGoal")
+	defer span.End()
+
+	goal, err := s.goalRepo.GetByID(ctx, userID, ledgerID, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.newGoalResponse(ctx, goal, time.Now())
+}
+
+// UpdateGoal изменяет цель
+func (s *GoalService) UpdateGoal(ctx context.Context, userID, ledgerID, id uint, req dto.GoalRequest) (*dto.GoalResponse, error) {
+	ctx, span := tracer.Start(ctx, "GoalService.UpdateGoal")
+	defer span.End()
+
+	if err := s.ledgerService.CheckWriteAccess(ctx, userID, ledgerID); err != nil {
+		return nil, err
+	}
+
+	goal, err := s.goalRepo.GetByID(ctx, userID, ledgerID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.fillGoal(ctx, userID, ledgerID, goal, req); err != nil {
+		return nil, err
+	}
+	if err := s.goalRepo.Update(ctx, goal); err != nil {
+		return nil, fmt.Errorf("failed to update goal: %w", err)
+	}
+	return s.newGoalResponse(ctx, goal, time.Now())
+}
+
+// DeleteGoal удаляет цель
+func (s *GoalService) DeleteGoal(ctx context.Context, userID, ledgerID, id uint) error {
+	ctx, span := tracer.Start(ctx, "GoalService.DeleteGoal")
+	defer span.End()
+
+	if err := s.ledgerService.CheckWriteAccess(ctx, userID, ledgerID); err != nil {
+		return err
+	}
+	return s.goalRepo.Delete(ctx, userID, ledgerID, id)
+}
+
+// fillGoal проверяет запрос и переносит его в цель
+func (s *GoalService) fillGoal(ctx context.Context, userID, ledgerID uint, goal *model.Goal, req dto.GoalRequest) error {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return errors.New("name is required")
+	}
+	if req.CategoryID != nil {
+		if _, err := s.categoryRepo.GetByID(ctx, userID, ledgerID, *req.CategoryID); err != nil {
+			return err
+		}
+	}
+
+	if req.StartDate != "" {
+		start, err := time.Parse(time.RFC3339, req.StartDate)
+		if err != nil {
+			return errors.New("invalid start_date format")
+		}
+		goal.StartDate = start
+	}
+
+	goal.Deadline = nil
+	if req.Deadline != "" {
+		deadline, err := time.Parse(time.RFC3339, req.Deadline)
+		if err != nil {
+			return errors.New("invalid deadline format")
+		}
+		if !deadline.After(goal.StartDate) {
+			return errors.New("deadline must be after start date")
+		}
+		goal.Deadline = &deadline
+	}
+
+	goal.Name = name
+	goal.TargetAmount = req.TargetAmount
+	goal.CategoryID = req.CategoryID
+	return nil
+}
+
+func (s *GoalService) newGoalResponse(ctx context.Context, goal *model.Goal, now time.Time) (*dto.GoalResponse, error) {
+	saved, err := s.goalRepo.GetContributions(ctx, goal, goal.StartDate)
+	if err != nil {
+		return nil, err
+	}
+
+	// Темп считаем по последним месяцам, но не раньше начала цели
+	paceFrom := now.Add(-goalPaceWindow)
+	if goal.StartDate.After(paceFrom) {
+		paceFrom = goal.StartDate
+	}
+	recent, err := s.goalRepo.GetContributions(ctx, goal, paceFrom)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.GoalResponse{
+		ID:           goal.ID,
+		Name:         goal.Name,
+		TargetAmount: goal.TargetAmount,
+		Deadline:     goal.Deadline,
+		CategoryID:   goal.CategoryID,
+		StartDate:    goal.StartDate,
+		Progress:     goalProgress(goal, saved, recent, now.Sub(paceFrom), now),
+	}, nil
+}
+
+// goalProgress считает прогресс цели: recent — взносы за последний период
+// длиной paceSpan, по ним оценивается дата достижения
+func goalProgress(goal *model.Goal, saved, recent float64, paceSpan time.Duration, now time.Time) dto.GoalProgress {
+	remaining := math.Max(goal.TargetAmount-saved, 0)
+	progress := dto.GoalProgress{
+		Saved:     roundMoney(saved),
+		Remaining: roundMoney(remaining),
+		Percent:   math.Round(math.Min(math.Max(saved/goal.TargetAmount, 0), 1)*10000) / 100,
+		Completed: remaining == 0,
+	}
+
+	// За первые сутки темп оценить нельзя
+	if days := paceSpan.Hours() / 24; days >= 1 {
+		progress.MonthlyPace = roundMoney(recent / days * daysPerMonth)
+	}
+
+	if goal.Deadline != nil {
+		required := remaining
+		if months := goal.Deadline.Sub(now).Hours() / 24 / daysPerMonth; months > 1 {
+			required = remaining / months
+		}
+		required = roundMoney(required)
+		progress.RequiredMonthly = &required
+	}
+
+	if progress.Completed {
+		onTrack := true
+		progress.OnTrack = &onTrack
+		return progress
+	}
+	if progress.MonthlyPace > 0 {
+		days := remaining / progress.MonthlyPace * daysPerMonth
+		projected := now.Add(time.Duration(days * 24 * float64(time.Hour))).Truncate(24 * time.Hour)
+		progress.ProjectedDate = &projected
+	}
+	if goal.Deadline != nil {
+		onTrack := progress.ProjectedDate != nil && !progress.ProjectedDate.After(*goal.Deadline)
+		progress.OnTrack = &onTrack
+	}
+	return progress
+}
+
+func roundMoney(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,393 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"finance-backend/internal/dto"
+	"finance-backend/internal/mailer"
+	"finance-backend/internal/model"
+	"finance-backend/internal/repository"
+)
+
+const invitationTTL = 7 * 24 * time.Hour
+
+// ErrForbidden возвращается, когда роли пользователя недостаточно для операции
+var ErrForbidden = errors.New("insufficient permissions")
+
+type LedgerService struct {
+	ledgerRepo *repository.LedgerRepository
+	userRepo   *repository.UserRepository
+	mailer     mailer.Mailer
+	audit      *AuditService
+}
+
+func NewLedgerService(lr *repository.LedgerRepository, ur *repository.UserRepository, m mailer.Mailer, as *AuditService) *LedgerService {
+	return &LedgerService{
+		ledgerRepo: lr,
+		userRepo:   ur,
+		mailer:     m,
+		audit:      as,
+	}
+}
+
+// ResolveLedger возвращает бюджет, с которым работает пользователь: указанный
+// явно или личный по умолчанию
+func (s *LedgerService) ResolveLedger(ctx context.Context, userID uint, ledgerID *uint) (*model.LedgerMember, error) {
+	ctx, span := tracer.Start(ctx, "LedgerService.ResolveLedger")
+	defer span.End()
+
+	if ledgerID == nil {
+		ledger, err := s.ledgerRepo.GetPersonal(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		ledgerID = &ledger.ID
+	}
+
+	member, err := s.ledgerRepo.GetMember(ctx, *ledgerID, userID)
+	if errors.Is(err, repository.ErrDatabase) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, errors.New("ledger not found")
+	}
+	return member, nil
+}
+
+// CheckWriteAccess проверяет, что пользователь может изменять данные бюджета
+func (s *LedgerService) CheckWriteAccess(ctx context.Context, userID, ledgerID uint) error {
+	ctx, span := tracer.Start(ctx, "LedgerService.CheckWriteAccess")
+	defer span.End()
+
+	member, err := s.ledgerRepo.GetMember(ctx, ledgerID, userID)
+	if errors.Is(err, repository.ErrDatabase) {
+		return err
+	}
+	if err != nil {
+		return errors.New("ledger not found")
+	}
+	if member.Role == model.RoleViewer {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// GetUserLedgers возвращает бюджеты пользователя с его ролью
+func (s *LedgerService) GetUserLedgers(ctx context.Context, userID uint) ([]dto.LedgerResponse, error) {
+	ctx, span := tracer.Start(ctx, "LedgerService.GetUserLedgers")
+	defer span.End()
+
+	members, err := s.ledgerRepo.GetMemberships(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dto.LedgerResponse, 0, len(members))
+	for _, m := range members {
+		response = append(response, newLedgerResponse(m.Ledger, m.Role))
+	}
+	return response, nil
+}
+
+// CreateLedger создает общий бюджет
+func (s *LedgerService) CreateLedger(ctx context.Context, userID uint, req dto.LedgerRequest) (*dto.LedgerResponse, error) {
+	ctx, span := tracer.Start(ctx, "LedgerService.CreateLedger")
+	defer span.End()
+
+	ledger := &model.Ledger{Name: req.Name, OwnerID: userID}
+	if err := s.ledgerRepo.Create(ctx, ledger); err != nil {
+		return nil, fmt.Errorf("failed to create ledger: %w", err)
+	}
+	s.audit.Record(ctx, userID, ledger.ID, model.AuditEntityLedger, ledger.ID, model.AuditActionCreate,
+		nil, auditLedger(ledger))
+
+	response := newLedgerResponse(ledger, model.RoleOwner)
+	return &response, nil
+}
+
+// RenameLedger переименовывает бюджет
+func (s *LedgerService) RenameLedger(ctx context.Context, userID, ledgerID uint, req dto.LedgerRequest) (*dto.LedgerResponse, error) {
+	ctx, span := tracer.Start(ctx, "LedgerService.RenameLedger")
+	defer span.End()
+
+	ledger, err := s.getOwnedLedger(ctx, userID, ledgerID)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := *ledger
+	ledger.Name = req.Name
+	if err := s.ledgerRepo.Update(ctx, ledger); err != nil {
+		return nil, fmt.Errorf("failed to update ledger: %w", err)
+	}
+	s.audit.Record(ctx, userID, ledger.ID, model.AuditEntityLedger, ledger.ID, model.AuditActionUpdate,
+		auditLedger(&previous), auditLedger(ledger))
+
+	response := newLedgerResponse(ledger, model.RoleOwner)
+	return &response, nil
+}
+
+// DeleteLedger удаляет общий бюджет вместе с данными
+func (s *LedgerService) DeleteLedger(ctx context.Context, userID, ledgerID uint) error {
+	ctx, span := tracer.Start(ctx, "LedgerService.DeleteLedger")
+	defer span.End()
+
+	ledger, err := s.getOwnedLedger(ctx, userID, ledgerID)
+	if err != nil {
+		return err
+	}
+	if ledger.Personal {
+		return errors.New("personal ledger cannot be deleted")
+	}
+	if err := s.ledgerRepo.Delete(ctx, ledger.ID); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, userID, ledger.ID, model.AuditEntityLedger, ledger.ID, model.AuditActionDelete,
+		auditLedger(ledger), nil)
+	return nil
+}
+
+// GetMembers возвращает участников бюджета
+func (s *LedgerService) GetMembers(ctx context.Context, userID, ledgerID uint) ([]dto.LedgerMemberResponse, error) {
+	ctx, span := tracer.Start(ctx, "LedgerService.GetMembers")
+	defer span.End()
+
+	if _, err := s.ledgerRepo.GetByID(ctx, userID, ledgerID); err != nil {
+		return nil, err
+	}
+
+	members, err := s.ledgerRepo.GetMembers(ctx, ledgerID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dto.LedgerMemberResponse, 0, len(members))
+	for _, m := range members {
+		item := dto.LedgerMemberResponse{UserID: m.UserID, Role: m.Role}
+		if m.User != nil {
+			item.Email = m.User.Email
+			item.FirstName = m.User.FirstName
+			item.LastName = m.User.LastName
+		}
+		response = append(response, item)
+	}
+	return response, nil
+}
+
+// UpdateMemberRole меняет роль участника
+func (s *LedgerService) UpdateMemberRole(ctx context.Context, userID, ledgerID, memberID uint, req dto.UpdateMemberRoleRequest) error {
+	ctx, span := tracer.Start(ctx, "LedgerService.UpdateMemberRole")
+	defer span.End()
+
+	if _, err := s.getOwnedLedger(ctx, userID, ledgerID); err != nil {
+		return err
+	}
+
+	member, err := s.ledgerRepo.GetMember(ctx, ledgerID, memberID)
+	if err != nil {
+		return err
+	}
+	if member.Role == model.RoleOwner {
+		return errors.New("owner role cannot be changed")
+	}
+
+	member.Role = req.Role
+	return s.ledgerRepo.UpdateMember(ctx, member)
+}
+
+// RemoveMember исключает участника; участник может покинуть бюджет сам
+func (s *LedgerService) RemoveMember(ctx context.Context, userID, ledgerID, memberID uint) error {
+	ctx, span := tracer.Start(ctx, "LedgerService.RemoveMember")
+	defer span.End()
+
+	if userID != memberID {
+		if _, err := s.getOwnedLedger(ctx, userID, ledgerID); err != nil {
+			return err
+		}
+	}
+
+	member, err := s.ledgerRepo.GetMember(ctx, ledgerID, memberID)
+	if err != nil {
+		return err
+	}
+	if member.Role == model.RoleOwner {
+		return errors.New("owner cannot leave the ledger")
+	}
+
+	return s.ledgerRepo.RemoveMember(ctx, ledgerID, memberID)
+}
+
+// Invite приглашает пользователя в бюджет по email
+func (s *LedgerService) Invite(ctx context.Context, userID, ledgerID uint, req dto.InviteMemberRequest) (*dto.InvitationResponse, error) {
+	ctx, span := tracer.Start(ctx, "LedgerService.Invite")
+	defer span.End()
+
+	ledger, err := s.getOwnedLedger(ctx, userID, ledgerID)
+	if err != nil {
+		return nil, err
+	}
+
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	if invitee, err := s.userRepo.GetByEmail(ctx, email); err == nil {
+		if _, err := s.ledgerRepo.GetMember(ctx, ledgerID, invitee.ID); err == nil {
+			return nil, errors.New("user is already a member of the ledger")
+		}
+	}
+
+	invitation := &model.LedgerInvitation{
+		LedgerID:    ledger.ID,
+		Email:       email,
+		Role:        req.Role,
+		InvitedByID: userID,
+		Status:      model.InvitationPending,
+		ExpiresAt:   time.Now().Add(invitationTTL),
+	}
+	if err := s.ledgerRepo.CreateInvitation(ctx, invitation); err != nil {
+		return nil, fmt.Errorf("failed to create invitation: %w", err)
+	}
+
+	inviter, err := s.userRepo.GetByID(ctx, userID)
+	if err == nil {
+		body := fmt.Sprintf("Hello!\n\n%s %s invited you to the shared ledger \"%s\" as %s.\n"+
+			"Sign in or register with this email to accept or decline the invitation.\n"+
+			"The invitation is valid for 7 days.\n",
+			inviter.FirstName, inviter.LastName, ledger.Name, req.Role)
+		if err := s.mailer.Send(email, "Invitation to a shared ledger", body); err != nil {
+			slog.WarnContext(ctx, "failed to send invitation", "invitation_id", invitation.ID, "error", err)
+		}
+	}
+
+	invitation.Ledger = ledger
+	response := newInvitationResponse(invitation)
+	return &response, nil
+}
+
+// GetLedgerInvitations возвращает приглашения в бюджет для владельца
+func (s *LedgerService) GetLedgerInvitations(ctx context.Context, userID, ledgerID uint) ([]dto.InvitationResponse, error) {
+	ctx, span := tracer.Start(ctx, "LedgerService.GetLedgerInvitations")
+	defer span.End()
+
+	if _, err := s.getOwnedLedger(ctx, userID, ledgerID); err != nil {
+		return nil, err
+	}
+
+	invitations, err := s.ledgerRepo.GetLedgerInvitations(ctx, ledgerID)
+	if err != nil {
+		return nil, err
+	}
+	return newInvitationResponses(invitations), nil
+}
+
+// GetMyInvitations возвращает действующие приглашения пользователя
+func (s *LedgerService) GetMyInvitations(ctx context.Context, userID uint) ([]dto.InvitationResponse, error) {
+	ctx, span := tracer.Start(ctx, "LedgerService.GetMyInvitations")
+	defer span.End()
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	invitations, err := s.ledgerRepo.GetPendingInvitations(ctx, strings.ToLower(user.Email), time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return newInvitationResponses(invitations), nil
+}
+
+// RespondToInvitation принимает или отклоняет приглашение
+func (s *LedgerService) RespondToInvitation(ctx context.Context, userID, invitationID uint, accept bool) error {
+	ctx, span := tracer.Start(ctx, "LedgerService.RespondToInvitation")
+	defer span.End()
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	invitation, err := s.ledgerRepo.GetInvitation(ctx, invitationID)
+	if err != nil {
+		return err
+	}
+	if invitation.Email != strings.ToLower(user.Email) {
+		return errors.New("invitation not found")
+	}
+	if invitation.Status != model.InvitationPending || time.Now().After(invitation.ExpiresAt) {
+		return errors.New("invitation is no longer valid")
+	}
+
+	if !accept {
+		invitation.Status = model.InvitationDeclined
+		return s.ledgerRepo.UpdateInvitation(ctx, invitation)
+	}
+
+	// Приглашение привязано к адресу, поэтому владение адресом должно быть подтверждено
+	if !user.EmailVerified {
+		return errors.New("email is not verified")
+	}
+
+	if _, err := s.ledgerRepo.GetMember(ctx, invitation.LedgerID, userID); err != nil {
+		member := &model.LedgerMember{
+			LedgerID: invitation.LedgerID,
+			UserID:   userID,
+			Role:     invitation.Role,
+		}
+		if err := s.ledgerRepo.AddMember(ctx, member); err != nil {
+			return fmt.Errorf("failed to join ledger: %w", err)
+		}
+	}
+
+	invitation.Status = model.InvitationAccepted
+	return s.ledgerRepo.UpdateInvitation(ctx, invitation)
+}
+
+// getOwnedLedger возвращает бюджет, если пользователь его владелец
+func (s *LedgerService) getOwnedLedger(ctx context.Context, userID, ledgerID uint) (*model.Ledger, error) {
+	ledger, err := s.ledgerRepo.GetByID(ctx, userID, ledgerID)
+	if err != nil {
+		return nil, err
+	}
+	if ledger.OwnerID != userID {
+		return nil, ErrForbidden
+	}
+	return ledger, nil
+}
+
+func newLedgerResponse(ledger *model.Ledger, role string) dto.LedgerResponse {
+	response := dto.LedgerResponse{Role: role}
+	if ledger != nil {
+		response.ID = ledger.ID
+		response.Name = ledger.Name
+		response.OwnerID = ledger.OwnerID
+		response.Personal = ledger.Personal
+	}
+	return response
+}
+
+func newInvitationResponse(invitation *model.LedgerInvitation) dto.InvitationResponse {
+	response := dto.InvitationResponse{
+		ID:        invitation.ID,
+		LedgerID:  invitation.LedgerID,
+		Email:     invitation.Email,
+		Role:      invitation.Role,
+		Status:    invitation.Status,
+		ExpiresAt: invitation.ExpiresAt,
+	}
+	if invitation.Ledger != nil {
+		response.LedgerName = invitation.Ledger.Name
+	}
+	return response
+}
+
+func newInvitationResponses(invitations []model.LedgerInvitation) []dto.InvitationResponse {
+	response := make([]dto.InvitationResponse, 0, len(invitations))
+	for i := range invitations {
+		response = append(response, newInvitationResponse(&invitations[i]))
+	}
+	return response
+}
@@ -0,0 +1,276 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"finance-backend/internal/dto"
+	"finance-backend/internal/model"
+	"finance-backend/internal/repository"
+)
+
+const defaultTopPayees = 10
+
+type PayeeService struct {
+	payeeRepo     *repository.PayeeRepository
+	categoryRepo  *repository.CategoryRepository
+	ledgerService *LedgerService
+}
+
+func NewPayeeService(pr *repository.PayeeRepository, cr *repository.CategoryRepository, ls *LedgerService) *PayeeService {
+	return &PayeeService{
+		payeeRepo:     pr,
+		categoryRepo:  cr,
+		ledgerService: ls,
+	}
+}
+
+// CreatePayee создает получателя в бюджете
+func (s *PayeeService) CreatePayee(ctx context.Context, userID, ledgerID uint, req dto.PayeeRequest) (*dto.PayeeResponse, error) {
+	ctx, span := tracer.Start(ctx, "PayeeService.CreatePayee")
+	defer span.End()
+
+	if err := s.ledgerService.CheckWriteAccess(ctx, userID, ledgerID); err != nil {
+		return nil, err
+	}
+
+	payee := &model.Payee{UserID: userID, LedgerID: ledgerID}
+	if err := s.fillPayee(ctx, userID, ledgerID, payee, req); err != nil {
+		return nil, err
+	}
+	if err := s.payeeRepo.Create(ctx, payee); err != nil {
+		return nil, fmt.Errorf("failed to create payee: %w", err)
+	}
+
+	response := newPayeeResponse(payee)
+	return &response, nil
+}
+
+// GetLedgerPayees возвращает получателей бюджета
+func (s *PayeeService) GetLedgerPayees(ctx context.Context, userID, ledgerID uint) ([]dto.PayeeResponse, error) {
+	ctx, span := tracer.Start(ctx, "PayeeService.GetLedgerPayees")
+	defer span.End()
+
+	payees, err := s.payeeRepo.GetByLedger(ctx, userID, ledgerID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dto.PayeeResponse, 0, len(payees))
+	for i := range payees {
+		response = append(response, newPayeeResponse(&payees[i]))
+	}
+	return response, nil
+}
+
+// UpdatePayee изменяет получателя и заменяет его псевдонимы
+func (s *PayeeService) UpdatePayee(ctx context.Context, userID, ledgerID, id uint, req dto.PayeeRequest) (*dto.PayeeResponse, error) {
+	ctx, span := tracer.Start(ctx, "PayeeService.UpdatePayee")
+	defer span.End()
+
+	if err := s.ledgerService.CheckWriteAccess(ctx, userID, ledgerID); err != nil {
+		return nil, err
+	}
+
+	payee, err := s.payeeRepo.GetByID(ctx, userID, ledgerID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.fillPayee(ctx, userID, ledgerID, payee, req); err != nil {
+		return nil, err
+	}
+	if err := s.payeeRepo.Update(ctx, payee); err != nil {
+		return nil, fmt.Errorf("failed to update payee: %w", err)
+	}
+
+	response := newPayeeResponse(payee)
+	return &response, nil
+}
+
+// DeletePayee удаляет получателя
+func (s *PayeeService) DeletePayee(ctx context.Context, userID, ledgerID, id uint) error {
+	ctx, span := tracer.Start(ctx, "PayeeService.DeletePayee")
+	defer span.End()
+
+	if err := s.ledgerService.CheckWriteAccess(ctx, userID, ledgerID); err != nil {
+		return err
+	}
+	return s.payeeRepo.Delete(ctx, userID, ledgerID, id)
+}
+
+// GetTopPayees возвращает получателей с наибольшими расходами за период
+func (s *PayeeService) GetTopPayees(ctx context.Context, userID, ledgerID uint, from, to *time.Time, limit int) ([]dto.PayeeReport, error) {
+	ctx, span := tracer.Start(ctx, "PayeeService.GetTopPayees")
+	defer span.End()
+
+	if limit <= 0 {
+		limit = defaultTopPayees
+	}
+
+	totals, err := s.payeeRepo.GetTop(ctx, userID, ledgerID, from, to, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	report := make([]dto.PayeeReport, 0, len(totals))
+	for _, t := range totals {
+		report = append(report, dto.PayeeReport{
+			PayeeID:      t.PayeeID,
+			Name:         t.Name,
+			TotalExpense: t.TotalExpense,
+			TotalIncome:  t.TotalIncome,
+			Count:        t.Count,
+			LastDate:     t.LastDate,
+		})
+	}
+	return report, nil
+}
+
+// ApplyPayee назначает транзакции получателя: указанного явно или найденного
+// по псевдонимам исходного описания. Категория получателя используется, только
+// если категорию не задали запрос и правила
+func (s *PayeeService) ApplyPayee(ctx context.Context, userID uint, transaction *model.Transaction, payeeID *uint, rawDescription string) error {
+	ctx, span := tracer.Start(ctx, "PayeeService.ApplyPayee")
+	defer span.End()
+
+	var payee *model.Payee
+	if payeeID != nil {
+		var err error
+		payee, err = s.payeeRepo.GetByID(ctx, userID, transaction.LedgerID, *payeeID)
+		if err != nil {
+			return err
+		}
+	} else {
+		payees, err := s.payeeRepo.GetByLedger(ctx, userID, transaction.LedgerID)
+		if err != nil {
+			return err
+		}
+		payee = matchPayee(payees, rawDescription)
+	}
+	if payee == nil {
+		return nil
+	}
+
+	transaction.PayeeID = &payee.ID
+	if transaction.CategoryID == nil && payee.DefaultCategoryID != nil {
+		// Категория по умолчанию могла быть удалена
+		if _, err := s.categoryRepo.GetByID(ctx, userID, transaction.LedgerID, *payee.DefaultCategoryID); err == nil {
+			transaction.CategoryID = payee.DefaultCategoryID
+		}
+	}
+	return nil
+}
+
+// ValidatePayee проверяет, что получатель принадлежит бюджету
+func (s *PayeeService) ValidatePayee(ctx context.Context, userID, ledgerID uint, payeeID *uint) error {
+	ctx, span := tracer.Start(ctx, "PayeeService.ValidatePayee")
+	defer span.End()
+
+	if payeeID == nil {
+		return nil
+	}
+	_, err := s.payeeRepo.GetByID(ctx, userID, ledgerID, *payeeID)
+	return err
+}
+
+// fillPayee проверяет запрос и переносит его в получателя
+func (s *PayeeService) fillPayee(ctx context.Context, userID, ledgerID uint, payee *model.Payee, req dto.PayeeRequest) error {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return errors.New("name is required")
+	}
+	if s.payeeRepo.NameExists(ctx, ledgerID, name, payee.ID) {
+		return errors.New("payee with this name already exists")
+	}
+	if req.DefaultCategoryID != nil {
+		if _, err := s.categoryRepo.GetByID(ctx, userID, ledgerID, *req.DefaultCategoryID); err != nil {
+			return err
+		}
+	}
+
+	aliases := make([]model.PayeeAlias, 0, len(req.Aliases))
+	for _, a := range req.Aliases {
+		if a.IsRegex {
+			if _, err := regexp.Compile(a.Pattern); err != nil {
+				return errors.New("invalid alias regex: " + a.Pattern)
+			}
+		} else if normalizePayeeText(a.Pattern) == "" {
+			return errors.New("alias must contain letters: " + a.Pattern)
+		}
+		aliases = append(aliases, model.PayeeAlias{Pattern: a.Pattern, IsRegex: a.IsRegex})
+	}
+
+	payee.Name = name
+	payee.DefaultCategoryID = req.DefaultCategoryID
+	payee.Aliases = aliases
+	return nil
+}
+
+// matchPayee выбирает получателя, чей псевдоним или имя совпадает с описанием.
+// При нескольких совпадениях побеждает самый длинный, то есть самый точный шаблон
+func matchPayee(payees []model.Payee, description string) *model.Payee {
+	normalized := " " + normalizePayeeText(description) + " "
+
+	var best *model.Payee
+	bestLength := 0
+	consider := func(payee *model.Payee, length int) {
+		if length > bestLength {
+			best, bestLength = payee, length
+		}
+	}
+
+	for i := range payees {
+		payee := &payees[i]
+		if name := normalizePayeeText(payee.Name); name != "" && strings.Contains(normalized, " "+name+" ") {
+			consider(payee, len(name))
+		}
+		for _, alias := range payee.Aliases {
+			if alias.IsRegex {
+				re, err := regexp.Compile("(?i)" + alias.Pattern)
+				if err == nil && re.MatchString(description) {
+					consider(payee, len(alias.Pattern))
+				}
+				continue
+			}
+			if pattern := normalizePayeeText(alias.Pattern); pattern != "" && strings.Contains(normalized, " "+pattern+" ") {
+				consider(payee, len(pattern))
+			}
+		}
+	}
+	return best
+}
+
+// normalizePayeeText приводит описание к словам в нижнем регистре: разделители
+// вроде "*" и "/" становятся пробелами, числа (номера терминалов, карт) отбрасываются
+func normalizePayeeText(s string) string {
+	fields := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	words := fields[:0]
+	for _, f := range fields {
+		if strings.IndexFunc(f, unicode.IsLetter) < 0 {
+			continue
+		}
+		words = append(words, strings.ReplaceAll(f, "ё", "е"))
+	}
+	return strings.Join(words, " ")
+}
+
+func newPayeeResponse(payee *model.Payee) dto.PayeeResponse {
+	aliases := make([]dto.PayeeAliasResponse, 0, len(payee.Aliases))
+	for _, a := range payee.Aliases {
+		aliases = append(aliases, dto.PayeeAliasResponse{ID: a.ID, Pattern: a.Pattern, IsRegex: a.IsRegex})
+	}
+	return dto.PayeeResponse{
+		ID:                payee.ID,
+		Name:              payee.Name,
+		DefaultCategoryID: payee.DefaultCategoryID,
+		Aliases:           aliases,
+	}
+}
@@ -0,0 +1,471 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"finance-backend/internal/dto"
+	"finance-backend/internal/model"
+	"finance-backend/internal/repository"
+)
+
+type RuleService struct {
+	ruleRepo        *repository.RuleRepository
+	tagRepo         *repository.TagRepository
+	categoryRepo    *repository.CategoryRepository
+	transactionRepo *repository.TransactionRepository
+	ledgerService   *LedgerService
+	suggestions     *SuggestionService
+	audit           *AuditService
+}
+
+func NewRuleService(
+	rr *repository.RuleRepository,
+	tgr *repository.TagRepository,
+	cr *repository.CategoryRepository,
+	tr *repository.TransactionRepository,
+	ls *LedgerService,
+	ss *SuggestionService,
+	as *AuditService,
+) *RuleService {
+	return &RuleService{
+		ruleRepo:        rr,
+		tagRepo:         tgr,
+		categoryRepo:    cr,
+		transactionRepo: tr,
+		ledgerService:   ls,
+		suggestions:     ss,
+		audit:           as,
+	}
+}
+
+// compiledRule правило с заранее подготовленными условиями
+type compiledRule struct {
+	rule     *model.Rule
+	contains string
+	re       *regexp.Regexp
+}
+
+// ruleOutcome результат применения правил к одной транзакции
+type ruleOutcome struct {
+	ruleIDs     []uint
+	categoryID  *uint
+	description string
+	tags        []model.Tag
+}
+
+// CreateRule создает правило пользователя
+func (s *RuleService) CreateRule(ctx context.Context, userID uint, req dto.RuleRequest) (*dto.RuleResponse, error) {
+	ctx, span := tracer.Start(ctx, "RuleService.CreateRule")
+	defer span.End()
+
+	rule := &model.Rule{UserID: userID}
+	if err := s.fillRule(ctx, userID, rule, req); err != nil {
+		return nil, err
+	}
+	if err := s.ruleRepo.Create(ctx, rule); err != nil {
+		return nil, fmt.Errorf("failed to create rule: %w", err)
+	}
+
+	response := newRuleResponse(rule)
+	return &response, nil
+}
+
+// GetUserRules возвращает правила пользователя в порядке применения
+func (s *RuleService) GetUserRules(ctx context.Context, userID uint) ([]dto.RuleResponse, error) {
+	ctx, span := tracer.Start(ctx, "RuleService.GetUserRules")
+	defer span.End()
+
+	rules, err := s.ruleRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dto.RuleResponse, 0, len(rules))
+	for i := range rules {
+		response = append(response, newRuleResponse(&rules[i]))
+	}
+	return response, nil
+}
+
+// UpdateRule заменяет условия и действия правила
+func (s *RuleService) UpdateRule(ctx context.Context, userID, id uint, req dto.RuleRequest) (*dto.RuleResponse, error) {
+	ctx, span := tracer.Start(ctx, "RuleService.UpdateRule")
+	defer span.End()
+
+	rule, err := s.ruleRepo.GetByID(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.fillRule(ctx, userID, rule, req); err != nil {
+		return nil, err
+	}
+	if err := s.ruleRepo.Update(ctx, rule); err != nil {
+		return nil, fmt.Errorf("failed to update rule: %w", err)
+	}
+
+	response := newRuleResponse(rule)
+	return &response, nil
+}
+
+// DeleteRule удаляет правило
+func (s *RuleService) DeleteRule(ctx context.Context, userID, id uint) error {
+	ctx, span := tracer.Start(ctx, "RuleService.DeleteRule")
+	defer span.End()
+
+	return s.ruleRepo.Delete(ctx, userID, id)
+}
+
+// ApplyRules применяет правила пользователя к новой транзакции перед сохранением.
+// Явно указанная категория не переопределяется, теги правил добавляются к указанным
+func (s *RuleService) ApplyRules(ctx context.Context, userID uint, transaction *model.Transaction) error {
+	ctx, span := tracer.Start(ctx, "RuleService.ApplyRules")
+	defer span.End()
+
+	rules, err := s.ruleRepo.GetActive(ctx, userID, transaction.LedgerID)
+	if err != nil {
+		return err
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+
+	compiled := compileRules(rules)
+	categories, err := s.ledgerCategories(ctx, userID, transaction.LedgerID, compiled)
+	if err != nil {
+		return err
+	}
+
+	outcome := evaluateRules(compiled, transaction, categories)
+	if transaction.CategoryID == nil {
+		transaction.CategoryID = outcome.categoryID
+	}
+	transaction.Description = outcome.description
+	transaction.Tags = append(transaction.Tags, missingTags(transaction.Tags, outcome.tags)...)
+	return nil
+}
+
+// DryRun показывает, как правила изменили бы транзакции бюджета за период.
+// Если передано правило в запросе, проверяется только оно
+func (s *RuleService) DryRun(ctx context.Context, userID, ledgerID uint, from, to *time.Time, req dto.RuleDryRunRequest) (*dto.RuleApplyResponse, error) {
+	ctx, span := tracer.Start(ctx, "RuleService.DryRun")
+	defer span.End()
+
+	var rules []model.Rule
+	if req.Rule != nil {
+		draft := &model.Rule{UserID: userID}
+		if err := s.fillRule(ctx, userID, draft, *req.Rule); err != nil {
+			return nil, err
+		}
+		draft.Enabled = true
+		rules = []model.Rule{*draft}
+	} else {
+		var err error
+		rules, err = s.ruleRepo.GetActive(ctx, userID, ledgerID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return s.run(ctx, userID, ledgerID, from, to, rules, false)
+}
+
+// Reapply заново применяет правила к транзакциям бюджета за период и сохраняет изменения
+func (s *RuleService) Reapply(ctx context.Context, userID, ledgerID uint, from, to *time.Time) (*dto.RuleApplyResponse, error) {
+	ctx, span := tracer.Start(ctx, "RuleService.Reapply")
+	defer span.End()
+
+	if err := s.ledgerService.CheckWriteAccess(ctx, userID, ledgerID); err != nil {
+		return nil, err
+	}
+
+	rules, err := s.ruleRepo.GetActive(ctx, userID, ledgerID)
+	if err != nil {
+		return nil, err
+	}
+	return s.run(ctx, userID, ledgerID, from, to, rules, true)
+}
+
+// run прогоняет правила по истории; при apply сохраняет найденные изменения
+// одной транзакцией базы, так что при ошибке бюджет не остается обработанным
+// наполовину. В отличие от создания транзакции, категория от правила заменяет текущую
+func (s *RuleService) run(ctx context.Context, userID, ledgerID uint, from, to *time.Time, rules []model.Rule, apply bool) (*dto.RuleApplyResponse, error) {
+	response := &dto.RuleApplyResponse{Applied: apply, Changes: []dto.RuleChange{}}
+
+	transactions, err := s.transactionRepo.GetByLedger(ctx, userID, ledgerID, dto.TransactionFilter{From: from, To: to})
+	if err != nil {
+		return nil, err
+	}
+	response.Checked = len(transactions)
+	if len(rules) == 0 {
+		return response, nil
+	}
+
+	compiled := compileRules(rules)
+	categories, err := s.ledgerCategories(ctx, userID, ledgerID, compiled)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		updated  []*model.Transaction
+		previous []model.Transaction
+		tagIDs   = make(map[uint][]uint)
+	)
+	for i := range transactions {
+		t := &transactions[i]
+		outcome := evaluateRules(compiled, t, categories)
+		if len(outcome.ruleIDs) == 0 {
+			continue
+		}
+
+		change := dto.RuleChange{
+			TransactionID: t.ID,
+			Date:          t.Date,
+			Amount:        t.Amount,
+			Type:          t.Type,
+			Description:   t.Description,
+			RuleIDs:       outcome.ruleIDs,
+			OldCategoryID: t.CategoryID,
+		}
+		changed := false
+		if outcome.categoryID != nil && (t.CategoryID == nil || *t.CategoryID != *outcome.categoryID) {
+			change.NewCategoryID = outcome.categoryID
+			changed = true
+		}
+		if outcome.description != t.Description {
+			change.NewDescription = outcome.description
+			changed = true
+		}
+		added := missingTags(t.Tags, outcome.tags)
+		if len(added) > 0 {
+			change.AddedTags = newTagResponses(added)
+			changed = true
+		}
+		if !changed {
+			continue
+		}
+
+		if apply {
+			previous = append(previous, *t)
+			if change.NewCategoryID != nil {
+				t.CategoryID = change.NewCategoryID
+				t.Category = nil
+			}
+			t.Description = outcome.description
+			if len(added) > 0 {
+				for _, tag := range append(t.Tags, added...) {
+					tagIDs[t.ID] = append(tagIDs[t.ID], tag.ID)
+				}
+			}
+			updated = append(updated, t)
+		}
+
+		response.Changes = append(response.Changes, change)
+	}
+
+	if len(updated) > 0 {
+		if err := s.transactionRepo.UpdateMany(ctx, userID, updated, tagIDs); err != nil {
+			return nil, fmt.Errorf("failed to apply rules: %w", err)
+		}
+		// Подсказки и журнал обновляются только после того, как изменения сохранены
+		for i, t := range updated {
+			s.suggestions.Forget(&previous[i])
+			s.suggestions.Learn(t)
+			s.audit.Record(ctx, userID, ledgerID, model.AuditEntityTransaction, t.ID, model.AuditActionUpdate,
+				auditTransaction(&previous[i]), auditTransaction(t))
+		}
+	}
+
+	response.Changed = len(response.Changes)
+	return response, nil
+}
+
+// fillRule проверяет запрос и переносит его в правило
+func (s *RuleService) fillRule(ctx context.Context, userID uint, rule *model.Rule, req dto.RuleRequest) error {
+	if req.DescriptionRegex != "" {
+		if _, err := regexp.Compile(req.DescriptionRegex); err != nil {
+			return errors.New("invalid description regex")
+		}
+	}
+	if req.MinAmount != nil && req.MaxAmount != nil && *req.MinAmount > *req.MaxAmount {
+		return errors.New("min amount is greater than max amount")
+	}
+	if req.SetCategoryID == nil && req.SetDescription == "" && len(req.TagIDs) == 0 {
+		return errors.New("rule has no actions")
+	}
+
+	if req.LedgerID != nil {
+		if _, err := s.ledgerService.ResolveLedger(ctx, userID, req.LedgerID); err != nil {
+			return err
+		}
+	}
+	// Категории принадлежат бюджету, поэтому правило с категорией должно быть привязано к нему
+	if req.SetCategoryID != nil {
+		if req.LedgerID == nil {
+			return errors.New("ledger_id is required to set a category")
+		}
+		if _, err := s.categoryRepo.GetByID(ctx, userID, *req.LedgerID, *req.SetCategoryID); err != nil {
+			return err
+		}
+	}
+
+	tags, err := s.tagRepo.GetByIDs(ctx, userID, req.TagIDs)
+	if err != nil {
+		return err
+	}
+
+	rule.Name = req.Name
+	rule.Priority = req.Priority
+	rule.Enabled = req.Enabled == nil || *req.Enabled
+	rule.LedgerID = req.LedgerID
+	rule.DescriptionContains = req.DescriptionContains
+	rule.DescriptionRegex = req.DescriptionRegex
+	rule.MinAmount = req.MinAmount
+	rule.MaxAmount = req.MaxAmount
+	rule.Type = req.Type
+	rule.SetCategoryID = req.SetCategoryID
+	rule.SetDescription = req.SetDescription
+	rule.StopProcessing = req.StopProcessing
+	rule.Tags = tags
+	return nil
+}
+
+// ledgerCategories возвращает категории бюджета, если правила их назначают
+func (s *RuleService) ledgerCategories(ctx context.Context, userID, ledgerID uint, rules []compiledRule) (map[uint]bool, error) {
+	needed := false
+	for _, r := range rules {
+		if r.rule.SetCategoryID != nil {
+			needed = true
+			break
+		}
+	}
+	if !needed {
+		return nil, nil
+	}
+
+	categories, err := s.categoryRepo.GetByLedger(ctx, userID, ledgerID)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[uint]bool, len(categories))
+	for _, c := range categories {
+		result[c.ID] = true
+	}
+	return result, nil
+}
+
+// compileRules готовит условия правил; правила с некорректным выражением пропускаются
+func compileRules(rules []model.Rule) []compiledRule {
+	compiled := make([]compiledRule, 0, len(rules))
+	for i := range rules {
+		r := compiledRule{rule: &rules[i], contains: strings.ToLower(rules[i].DescriptionContains)}
+		if rules[i].DescriptionRegex != "" {
+			re, err := regexp.Compile(rules[i].DescriptionRegex)
+			if err != nil {
+				continue
+			}
+			r.re = re
+		}
+		compiled = append(compiled, r)
+	}
+	return compiled
+}
+
+// matches проверяет условия правила; описание передается отдельно, так как
+// предыдущие правила могли его переписать
+func (r compiledRule) matches(t *model.Transaction, description string) bool {
+	rule := r.rule
+	if rule.LedgerID != nil && *rule.LedgerID != t.LedgerID {
+		return false
+	}
+	if rule.Type != "" && rule.Type != t.Type {
+		return false
+	}
+	if rule.MinAmount != nil && t.Amount < *rule.MinAmount {
+		return false
+	}
+	if rule.MaxAmount != nil && t.Amount > *rule.MaxAmount {
+		return false
+	}
+	if r.contains != "" && !strings.Contains(strings.ToLower(description), r.contains) {
+		return false
+	}
+	if r.re != nil && !r.re.MatchString(description) {
+		return false
+	}
+	return true
+}
+
+// evaluateRules применяет правила по порядку: категорию назначает первое подходящее
+// правило, описания переписываются последовательно, теги накапливаются
+func evaluateRules(rules []compiledRule, t *model.Transaction, categories map[uint]bool) ruleOutcome {
+	outcome := ruleOutcome{description: t.Description}
+	for _, r := range rules {
+		if !r.matches(t, outcome.description) {
+			continue
+		}
+		rule := r.rule
+		outcome.ruleIDs = append(outcome.ruleIDs, rule.ID)
+
+		if outcome.categoryID == nil && rule.SetCategoryID != nil && categories[*rule.SetCategoryID] {
+			outcome.categoryID = rule.SetCategoryID
+		}
+		if rule.SetDescription != "" {
+			description := rule.SetDescription
+			if r.re != nil {
+				description = r.re.ReplaceAllString(outcome.description, rule.SetDescription)
+			}
+			// Описание обязательно, пустой результат замены не применяем
+			if strings.TrimSpace(description) != "" {
+				outcome.description = description
+			}
+		}
+		outcome.tags = append(outcome.tags, missingTags(outcome.tags, rule.Tags)...)
+
+		if rule.StopProcessing {
+			break
+		}
+	}
+	return outcome
+}
+
+// missingTags возвращает теги из candidates, которых нет в existing
+func missingTags(existing, candidates []model.Tag) []model.Tag {
+	seen := make(map[uint]bool, len(existing))
+	for _, tag := range existing {
+		seen[tag.ID] = true
+	}
+
+	var result []model.Tag
+	for _, tag := range candidates {
+		if !seen[tag.ID] {
+			seen[tag.ID] = true
+			result = append(result, tag)
+		}
+	}
+	return result
+}
+
+func newRuleResponse(rule *model.Rule) dto.RuleResponse {
+	return dto.RuleResponse{
+		ID:                  rule.ID,
+		Name:                rule.Name,
+		Priority:            rule.Priority,
+		Enabled:             rule.Enabled,
+		LedgerID:            rule.LedgerID,
+		DescriptionContains: rule.DescriptionContains,
+		DescriptionRegex:    rule.DescriptionRegex,
+		MinAmount:           rule.MinAmount,
+		MaxAmount:           rule.MaxAmount,
+		Type:                rule.Type,
+		SetCategoryID:       rule.SetCategoryID,
+		SetDescription:      rule.SetDescription,
+		Tags:                newTagResponses(rule.Tags),
+		StopProcessing:      rule.StopProcessing,
+	}
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"finance-backend/internal/dto"
+	"finance-backend/internal/repository"
+)
+
+const defaultSearchLimit = 20
+
+type SearchService struct {
+	searchRepo      *repository.SearchRepository
+	transactionRepo *repository.TransactionRepository
+}
+
+func NewSearchService(sr *repository.SearchRepository, tr *repository.TransactionRepository) *SearchService {
+	return &SearchService{searchRepo: sr, transactionRepo: tr}
+}
+
+// SearchTransactions ищет транзакции бюджета по описанию, заметкам, получателю и категории
+func (s *SearchService) SearchTransactions(ctx context.Context, userID, ledgerID uint, query dto.TransactionSearchQuery, from, to *time.Time) (*dto.TransactionSearchResponse, error) {
+	ctx, span := tracer.Start(ctx, "SearchService.SearchTransactions")
+	defer span.End()
+
+	limit := query.Limit
+	if limit == 0 {
+		limit = defaultSearchLimit
+	}
+
+	hits, total, err := s.searchRepo.Search(ctx, userID, ledgerID, query.Query, from, to, limit, query.Offset)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, 0, len(hits))
+	for _, hit := range hits {
+		ids = append(ids, hit.ID)
+	}
+	transactions, err := s.transactionRepo.GetByIDs(ctx, userID, ledgerID, ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]dto.TransactionResponse, len(transactions))
+	for i := range transactions {
+		byID[transactions[i].ID] = newTransactionResponse(&transactions[i])
+	}
+
+	// Сохраняем порядок по релевантности
+	response := &dto.TransactionSearchResponse{Total: total, Results: make([]dto.TransactionSearchResult, 0, len(hits))}
+	for _, hit := range hits {
+		transaction, ok := byID[hit.ID]
+		if !ok {
+			continue
+		}
+		response.Results = append(response.Results, dto.TransactionSearchResult{
+			TransactionResponse: transaction,
+			Rank:                hit.Rank,
+			Highlights: dto.SearchHighlights{
+				Description: hit.DescriptionHighlight,
+				Notes:       hit.NotesHighlight,
+			},
+		})
+	}
+	return response, nil
+}
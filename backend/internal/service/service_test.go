@@ -0,0 +1,147 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"finance-backend/internal/model"
+	"finance-backend/internal/repository/memory"
+	"finance-backend/internal/service"
+)
+
+// Сервисы транзакций, категорий и пользователей проверяются на хранилищах в
+// памяти; соседние сервисы заменены заглушками ниже
+
+// ledgers заглушка сервиса бюджетов: роли участников берутся из хранилища, где
+// личный бюджет получает ID пользователя
+type ledgers struct {
+	store *memory.Store
+	// checkedIn спан из контекста последней проверки доступа
+	checkedIn trace.SpanContext
+}
+
+func newLedgers(store *memory.Store) *ledgers {
+	return &ledgers{store: store}
+}
+
+func (l *ledgers) addMember(ledgerID, userID uint, role string) {
+	l.store.AddLedgerMember(ledgerID, userID, role)
+}
+
+func (l *ledgers) CheckWriteAccess(ctx context.Context, userID, ledgerID uint) error {
+	l.checkedIn = trace.SpanContextFromContext(ctx)
+	switch l.store.LedgerRole(ledgerID, userID) {
+	case "":
+		return errors.New("ledger not found")
+	case model.RoleViewer:
+		return service.ErrForbidden
+	}
+	return nil
+}
+
+// audit запоминает записи журнала
+type audit struct {
+	actions []string
+}
+
+func (a *audit) Record(_ context.Context, _, _ uint, entityType string, _ uint, action string, _, _ any) {
+	a.actions = append(a.actions, entityType+":"+action)
+}
+
+// noop заглушка правил, подсказок, получателей и поиска дубликатов
+type noop struct{}
+
+func (noop) ApplyRules(context.Context, uint, *model.Transaction) error { return nil }
+func (noop) Learn(*model.Transaction)                                   {}
+func (noop) Forget(*model.Transaction)                                  {}
+func (noop) ApplyPayee(context.Context, uint, *model.Transaction, *uint, string) error {
+	return nil
+}
+func (noop) ValidatePayee(context.Context, uint, uint, *uint) error { return nil }
+func (noop) FindFor(context.Context, uint, *model.Transaction) ([]uint, error) {
+	return nil, nil
+}
+
+// mailbox запоминает отправленные письма
+type mailbox struct {
+	mu   sync.Mutex
+	sent []mail
+}
+
+type mail struct {
+	to, subject, body string
+}
+
+func (m *mailbox) Send(to, subject, body string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, mail{to: to, subject: subject, body: body})
+	return nil
+}
+
+// token возвращает токен из ссылки в последнем письме на адрес to
+func (m *mailbox) token(t *testing.T, to string) string {
+	t.Helper()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := len(m.sent) - 1; i >= 0; i-- {
+		if m.sent[i].to != to {
+			continue
+		}
+		_, rest, ok := strings.Cut(m.sent[i].body, "token=")
+		if !ok {
+			break
+		}
+		token, err := url.QueryUnescape(strings.Fields(rest)[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+	t.Fatalf("no email with a token sent to %s", to)
+	return ""
+}
+
+type env struct {
+	ledgers *ledgers
+	audit   *audit
+	mailbox *mailbox
+	tags    *memory.TagRepository
+	users   *service.UserService
+	txs     *service.TransactionService
+	cats    *service.CategoryService
+}
+
+func newEnv() *env {
+	store := memory.NewStore()
+	e := &env{
+		ledgers: newLedgers(store),
+		audit:   &audit{},
+		mailbox: &mailbox{},
+		tags:    memory.NewTagRepository(store),
+	}
+	categories := memory.NewCategoryRepository(store)
+	auth := service.NewAuthService("secret", time.Hour)
+	e.users = service.NewUserService(memory.NewUserRepository(store), auth, e.mailbox, "http://app")
+	e.cats = service.NewCategoryService(categories, e.ledgers, e.audit)
+	e.txs = service.NewTransactionService(memory.NewTransactionRepository(store), categories, e.tags,
+		e.ledgers, noop{}, noop{}, noop{}, noop{}, e.audit)
+	return e
+}
+
+// newTag создает тег пользователя
+func (e *env) newTag(t *testing.T, userID uint, name string) *model.Tag {
+	t.Helper()
+	tag := &model.Tag{UserID: userID, Name: name}
+	if err := e.tags.Create(context.Background(), tag); err != nil {
+		t.Fatal(err)
+	}
+	return tag
+}
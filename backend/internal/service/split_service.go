@@ -0,0 +1,415 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"finance-backend/internal/dto"
+	"finance-backend/internal/metrics"
+	"finance-backend/internal/model"
+	"finance-backend/internal/repository"
+)
+
+type SplitService struct {
+	transactionRepo *repository.TransactionRepository
+	categoryRepo    *repository.CategoryRepository
+	splitRepo       *repository.SplitRepository
+	ledgerRepo      *repository.LedgerRepository
+	ledgerService   *LedgerService
+	ruleService     *RuleService
+	suggestions     *SuggestionService
+	payeeService    *PayeeService
+	audit           *AuditService
+}
+
+func NewSplitService(
+	tr *repository.TransactionRepository,
+	cr *repository.CategoryRepository,
+	sr *repository.SplitRepository,
+	lr *repository.LedgerRepository,
+	ls *LedgerService,
+	rs *RuleService,
+	ss *SuggestionService,
+	ps *PayeeService,
+	as *AuditService,
+) *SplitService {
+	return &SplitService{
+		transactionRepo: tr,
+		categoryRepo:    cr,
+		splitRepo:       sr,
+		ledgerRepo:      lr,
+		ledgerService:   ls,
+		ruleService:     rs,
+		suggestions:     ss,
+		payeeService:    ps,
+		audit:           as,
+	}
+}
+
+// CreateSplitExpense создает общий расход и распределяет его между участниками
+func (s *SplitService) CreateSplitExpense(ctx context.Context, userID, ledgerID uint, req dto.CreateSplitExpenseRequest) (*model.Transaction, error) {
+	ctx, span := tracer.Start(ctx, "SplitService.CreateSplitExpense")
+	defer span.End()
+
+	if err := s.ledgerService.CheckWriteAccess(ctx, userID, ledgerID); err != nil {
+		return nil, err
+	}
+
+	date, err := time.Parse(time.RFC3339, req.Date)
+	if err != nil {
+		return nil, errors.New("invalid date format")
+	}
+
+	if req.CategoryID != nil {
+		if _, err := s.categoryRepo.GetByID(ctx, userID, ledgerID, *req.CategoryID); err != nil {
+			return nil, err
+		}
+	}
+
+	paidBy := userID
+	if req.PaidBy != nil {
+		paidBy = *req.PaidBy
+	}
+	if _, err := s.ledgerRepo.GetMember(ctx, ledgerID, paidBy); errors.Is(err, repository.ErrDatabase) {
+		return nil, err
+	} else if err != nil {
+		return nil, errors.New("payer is not a member of the ledger")
+	}
+
+	seen := make(map[uint]bool, len(req.Participants))
+	for _, p := range req.Participants {
+		if seen[p.UserID] {
+			return nil, errors.New("duplicate participant")
+		}
+		seen[p.UserID] = true
+		if _, err := s.ledgerRepo.GetMember(ctx, ledgerID, p.UserID); errors.Is(err, repository.ErrDatabase) {
+			return nil, err
+		} else if err != nil {
+			return nil, errors.New("participant is not a member of the ledger")
+		}
+	}
+
+	amounts, err := splitAmount(toCents(req.Amount), req.Method, req.Participants)
+	if err != nil {
+		return nil, err
+	}
+
+	transaction := &model.Transaction{
+		UserID:      userID,
+		LedgerID:    ledgerID,
+		CategoryID:  req.CategoryID,
+		Amount:      req.Amount,
+		Type:        "expense",
+		Description: req.Description,
+		Notes:       req.Notes,
+		Date:        date,
+		PaidByID:    &paidBy,
+	}
+	for i, p := range req.Participants {
+		transaction.Splits = append(transaction.Splits, model.TransactionSplit{
+			UserID: p.UserID,
+			Amount: fromCents(amounts[i]),
+		})
+	}
+
+	if err := s.ruleService.ApplyRules(ctx, userID, transaction); err != nil {
+		return nil, err
+	}
+	if err := s.payeeService.ApplyPayee(ctx, userID, transaction, req.PayeeID, req.Description); err != nil {
+		return nil, err
+	}
+
+	if err := s.transactionRepo.Create(ctx, transaction); err != nil {
+		return nil, err
+	}
+	metrics.TransactionsCreated.WithLabelValues(transaction.Type).Inc()
+	s.suggestions.Learn(transaction)
+	s.audit.Record(ctx, userID, ledgerID, model.AuditEntityTransaction, transaction.ID, model.AuditActionCreate,
+		nil, auditTransaction(transaction))
+	return transaction, nil
+}
+
+// GetBalances считает, кто кому должен в бюджете, и предлагает минимальный набор платежей
+func (s *SplitService) GetBalances(ctx context.Context, userID, ledgerID uint) (*dto.BalancesResponse, error) {
+	ctx, span := tracer.Start(ctx, "SplitService.GetBalances")
+	defer span.End()
+
+	debts, err := s.splitRepo.GetLedgerDebts(ctx, userID, ledgerID)
+	if err != nil {
+		return nil, err
+	}
+	settlements, err := s.splitRepo.GetSettlements(ctx, userID, ledgerID)
+	if err != nil {
+		return nil, err
+	}
+	members, err := s.ledgerRepo.GetMembers(ctx, ledgerID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Балансы считаем в копейках, чтобы не накапливать ошибки округления
+	balances := make(map[uint]int64)
+	for _, d := range debts {
+		if d.UserID == d.PaidByID {
+			continue
+		}
+		cents := toCents(d.Amount)
+		balances[d.PaidByID] += cents
+		balances[d.UserID] -= cents
+	}
+	for _, st := range settlements {
+		cents := toCents(st.Amount)
+		balances[st.FromUserID] += cents
+		balances[st.ToUserID] -= cents
+	}
+
+	response := &dto.BalancesResponse{
+		Balances: make([]dto.MemberBalance, 0, len(members)),
+		Debts:    make([]dto.Debt, 0),
+	}
+	for _, m := range members {
+		item := dto.MemberBalance{UserID: m.UserID, Balance: fromCents(balances[m.UserID])}
+		if m.User != nil {
+			item.FirstName = m.User.FirstName
+			item.LastName = m.User.LastName
+		}
+		response.Balances = append(response.Balances, item)
+	}
+	response.Debts = append(response.Debts, simplifyDebts(balances)...)
+
+	return response, nil
+}
+
+// CreateSettlement записывает возврат долга одним участником другому
+func (s *SplitService) CreateSettlement(ctx context.Context, userID, ledgerID uint, req dto.CreateSettlementRequest) (*dto.SettlementResponse, error) {
+	ctx, span := tracer.Start(ctx, "SplitService.CreateSettlement")
+	defer span.End()
+
+	if err := s.ledgerService.CheckWriteAccess(ctx, userID, ledgerID); err != nil {
+		return nil, err
+	}
+
+	from := userID
+	if req.FromUserID != nil {
+		from = *req.FromUserID
+	}
+	if from == req.ToUserID {
+		return nil, errors.New("payer and recipient must differ")
+	}
+	// Возврат между другими участниками записывает только владелец бюджета:
+	// иначе редактор мог бы списать чужой долг
+	if userID != from && userID != req.ToUserID {
+		ledger, err := s.ledgerRepo.GetByID(ctx, userID, ledgerID)
+		if err != nil {
+			return nil, err
+		}
+		if ledger.OwnerID != userID {
+			return nil, ErrForbidden
+		}
+	}
+	for _, id := range []uint{from, req.ToUserID} {
+		if _, err := s.ledgerRepo.GetMember(ctx, ledgerID, id); errors.Is(err, repository.ErrDatabase) {
+			return nil, err
+		} else if err != nil {
+			return nil, errors.New("user is not a member of the ledger")
+		}
+	}
+
+	date := time.Now()
+	if req.Date != "" {
+		parsed, err := time.Parse(time.RFC3339, req.Date)
+		if err != nil {
+			return nil, errors.New("invalid date format")
+		}
+		date = parsed
+	}
+
+	settlement := &model.Settlement{
+		LedgerID:    ledgerID,
+		FromUserID:  from,
+		ToUserID:    req.ToUserID,
+		Amount:      fromCents(toCents(req.Amount)),
+		Date:        date,
+		Note:        req.Note,
+		CreatedByID: userID,
+	}
+	if err := s.splitRepo.CreateSettlement(ctx, settlement); err != nil {
+		return nil, fmt.Errorf("failed to create settlement: %w", err)
+	}
+
+	response := newSettlementResponse(settlement)
+	return &response, nil
+}
+
+// GetSettlements возвращает историю возвратов долгов в бюджете
+func (s *SplitService) GetSettlements(ctx context.Context, userID, ledgerID uint) ([]dto.SettlementResponse, error) {
+	ctx, span := tracer.Start(ctx, "SplitService.GetSettlements")
+	defer span.End()
+
+	settlements, err := s.splitRepo.GetSettlements(ctx, userID, ledgerID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dto.SettlementResponse, 0, len(settlements))
+	for i := range settlements {
+		response = append(response, newSettlementResponse(&settlements[i]))
+	}
+	return response, nil
+}
+
+func newSettlementResponse(s *model.Settlement) dto.SettlementResponse {
+	return dto.SettlementResponse{
+		ID:         s.ID,
+		FromUserID: s.FromUserID,
+		ToUserID:   s.ToUserID,
+		Amount:     s.Amount,
+		Date:       s.Date,
+		Note:       s.Note,
+	}
+}
+
+// splitAmount делит сумму в копейках между участниками выбранным способом
+func splitAmount(total int64, method string, participants []dto.SplitParticipant) ([]int64, error) {
+	weights := make([]float64, len(participants))
+
+	switch method {
+	case model.SplitEqual:
+		for i := range weights {
+			weights[i] = 1
+		}
+	case model.SplitShares:
+		for i, p := range participants {
+			if p.Value <= 0 {
+				return nil, errors.New("shares must be positive")
+			}
+			weights[i] = p.Value
+		}
+	case model.SplitPercent:
+		var sum float64
+		for i, p := range participants {
+			if p.Value < 0 {
+				return nil, errors.New("percentages must not be negative")
+			}
+			weights[i] = p.Value
+			sum += p.Value
+		}
+		if math.Abs(sum-100) > 0.01 {
+			return nil, errors.New("percentages must add up to 100")
+		}
+	case model.SplitExact:
+		amounts := make([]int64, len(participants))
+		var sum int64
+		for i, p := range participants {
+			if p.Value < 0 {
+				return nil, errors.New("amounts must not be negative")
+			}
+			amounts[i] = toCents(p.Value)
+			sum += amounts[i]
+		}
+		if sum != total {
+			return nil, errors.New("exact amounts must add up to the total")
+		}
+		return amounts, nil
+	default:
+		return nil, errors.New("unknown split method")
+	}
+
+	return distribute(total, weights), nil
+}
+
+// distribute делит сумму пропорционально весам; остаток после округления вниз
+// отдается участникам с наибольшей дробной частью
+func distribute(total int64, weights []float64) []int64 {
+	var weightSum float64
+	for _, w := range weights {
+		weightSum += w
+	}
+
+	amounts := make([]int64, len(weights))
+	fractions := make([]float64, len(weights))
+	var assigned int64
+	for i, w := range weights {
+		exact := float64(total) * w / weightSum
+		amounts[i] = int64(math.Floor(exact))
+		fractions[i] = exact - float64(amounts[i])
+		assigned += amounts[i]
+	}
+
+	order := make([]int, len(weights))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return fractions[order[a]] > fractions[order[b]]
+	})
+	for i := int64(0); i < total-assigned; i++ {
+		amounts[order[int(i)%len(order)]]++
+	}
+
+	return amounts
+}
+
+// simplifyDebts сводит балансы к минимальному набору платежей: самый крупный
+// должник платит самому крупному кредитору, пока все балансы не обнулятся
+func simplifyDebts(balances map[uint]int64) []dto.Debt {
+	type entry struct {
+		userID uint
+		amount int64
+	}
+
+	var creditors, debtors []entry
+	for userID, balance := range balances {
+		switch {
+		case balance > 0:
+			creditors = append(creditors, entry{userID, balance})
+		case balance < 0:
+			debtors = append(debtors, entry{userID, -balance})
+		}
+	}
+
+	byAmount := func(list []entry) func(a, b int) bool {
+		return func(a, b int) bool {
+			if list[a].amount != list[b].amount {
+				return list[a].amount > list[b].amount
+			}
+			return list[a].userID < list[b].userID
+		}
+	}
+
+	var debts []dto.Debt
+	for len(creditors) > 0 && len(debtors) > 0 {
+		sort.Slice(creditors, byAmount(creditors))
+		sort.Slice(debtors, byAmount(debtors))
+
+		amount := min(creditors[0].amount, debtors[0].amount)
+		debts = append(debts, dto.Debt{
+			FromUserID: debtors[0].userID,
+			ToUserID:   creditors[0].userID,
+			Amount:     fromCents(amount),
+		})
+
+		creditors[0].amount -= amount
+		debtors[0].amount -= amount
+		if creditors[0].amount == 0 {
+			creditors = creditors[1:]
+		}
+		if debtors[0].amount == 0 {
+			debtors = debtors[1:]
+		}
+	}
+
+	return debts
+}
+
+func toCents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
+
+func fromCents(cents int64) float64 {
+	return float64(cents) / 100
+}
@@ -0,0 +1,104 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"finance-backend/internal/config"
+	"finance-backend/internal/database"
+	"finance-backend/internal/dto"
+	"finance-backend/internal/migrations"
+	"finance-backend/internal/model"
+	"finance-backend/internal/repository"
+	"finance-backend/internal/service"
+)
+
+// Сервис долгов работает с репозиториями GORM напрямую, поэтому проверяется
+// на SQLite
+func openSQLite(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := database.Open(config.DBConfig{Driver: "sqlite", Path: t.TempDir() + "/finance.db"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.Logger = logger.Default.LogMode(logger.Silent)
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+
+	migrator, err := migrations.New(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := migrator.Up(); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestCreateSettlementBetweenOthers(t *testing.T) {
+	db := openSQLite(t)
+	ctx := context.Background()
+	users := repository.NewUserRepository(db)
+	ledgerRepo := repository.NewLedgerRepository(db)
+	ledgerService := service.NewLedgerService(ledgerRepo, users, nil, service.NewAuditService(repository.NewAuditRepository(db)))
+	splits := service.NewSplitService(repository.NewTransactionRepository(db), repository.NewCategoryRepository(db),
+		repository.NewSplitRepository(db), ledgerRepo, ledgerService, nil, nil, nil, nil)
+
+	ids := make(map[string]uint)
+	for _, name := range []string{"owner", "editor", "debtor", "lender"} {
+		user := &model.User{Email: name + "@example.com", Password: "hash", FirstName: name, LastName: "Test"}
+		if err := users.CreateWithPersonalLedger(ctx, user, &model.Ledger{Name: "Personal"}); err != nil {
+			t.Fatal(err)
+		}
+		ids[name] = user.ID
+	}
+	ledger := &model.Ledger{Name: "Квартира", OwnerID: ids["owner"]}
+	if err := ledgerRepo.Create(ctx, ledger); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"editor", "debtor", "lender"} {
+		member := &model.LedgerMember{LedgerID: ledger.ID, UserID: ids[name], Role: model.RoleEditor}
+		if err := ledgerRepo.AddMember(ctx, member); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	from := ids["debtor"]
+	between := dto.CreateSettlementRequest{FromUserID: &from, ToUserID: ids["lender"], Amount: 50}
+
+	tests := []struct {
+		name    string
+		userID  uint
+		req     dto.CreateSettlementRequest
+		wantErr error
+	}{
+		{"editor between others", ids["editor"], between, service.ErrForbidden},
+		{"payer", ids["debtor"], dto.CreateSettlementRequest{ToUserID: ids["lender"], Amount: 10}, nil},
+		{"recipient", ids["lender"], between, nil},
+		{"owner between others", ids["owner"], between, nil},
+	}
+	for _, tt := range tests {
+		_, err := splits.CreateSettlement(ctx, tt.userID, ledger.ID, tt.req)
+		if tt.wantErr == nil && err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+		}
+		if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: %v, want %v", tt.name, err, tt.wantErr)
+		}
+	}
+
+	settlements, err := splits.GetSettlements(ctx, ids["owner"], ledger.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(settlements) != 3 {
+		t.Errorf("got %d settlements, want 3: the rejected one must not be saved", len(settlements))
+	}
+}
@@ -0,0 +1,368 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"finance-backend/internal/dto"
+	"finance-backend/internal/model"
+	"finance-backend/internal/repository"
+)
+
+const (
+	// subscriptionHistoryMonths за сколько месяцев анализируются расходы
+	subscriptionHistoryMonths = 13
+	// subscriptionRegularShare доля интервалов, которые должны совпасть с периодом
+	subscriptionRegularShare = 0.75
+	// subscriptionAmountSpread допустимое отклонение суммы от медианы
+	subscriptionAmountSpread = 0.35
+	// priceChangeThreshold минимальное изменение суммы, о котором стоит сообщить
+	priceChangeThreshold = 0.01
+)
+
+// recurringPeriod период регулярного платежа: длина в днях, допуск и число платежей в год
+type recurringPeriod struct {
+	name           string
+	days           float64
+	tolerance      float64
+	perYear        float64
+	minOccurrences int
+}
+
+var recurringPeriods = []recurringPeriod{
+	{model.PeriodWeekly, 7, 2, 52, 3},
+	{model.PeriodBiweekly, 14, 3, 26, 3},
+	{model.PeriodMonthly, daysPerMonth, 5, 12, 3},
+	{model.PeriodQuarterly, 365.25 / 4, 10, 4, 3},
+	{model.PeriodYearly, 365.25, 20, 1, 2},
+}
+
+type SubscriptionService struct {
+	recurringRepo *repository.RecurringRepository
+	categoryRepo  *repository.CategoryRepository
+	ledgerService *LedgerService
+}
+
+func NewSubscriptionService(rr *repository.RecurringRepository, cr *repository.CategoryRepository, ls *LedgerService) *SubscriptionService {
+	return &SubscriptionService{
+		recurringRepo: rr,
+		categoryRepo:  cr,
+		ledgerService: ls,
+	}
+}
+
+// DetectSubscriptions ищет в расходах бюджета регулярные платежи: списания
+// одному получателю или с одинаковым описанием, похожей суммы и через равные промежутки
+func (s *SubscriptionService) DetectSubscriptions(ctx context.Context, userID, ledgerID uint) ([]dto.SubscriptionCandidate, error) {
+	ctx, span := tracer.Start(ctx, "SubscriptionService.DetectSubscriptions")
+	defer span.End()
+
+	now := time.Now()
+	expenses, err := s.recurringRepo.GetExpensesSince(ctx, userID, ledgerID, now.AddDate(0, -subscriptionHistoryMonths, 0))
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.recurringRepo.GetByLedger(ctx, userID, ledgerID)
+	if err != nil {
+		return nil, err
+	}
+
+	tracked := make(map[string]uint, len(items))
+	for _, item := range items {
+		if item.MatchKey != "" {
+			tracked[item.MatchKey] = item.ID
+		}
+	}
+
+	groups := make(map[string][]*model.Transaction)
+	for i := range expenses {
+		if key := subscriptionKey(&expenses[i]); key != "" {
+			groups[key] = append(groups[key], &expenses[i])
+		}
+	}
+
+	candidates := make([]dto.SubscriptionCandidate, 0)
+	for key, group := range groups {
+		candidate, ok := detectSubscription(key, group, now)
+		if !ok {
+			continue
+		}
+		if id, ok := tracked[key]; ok {
+			candidate.RecurringItemID = &id
+		}
+		candidates = append(candidates, candidate)
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].MonthlyCost != candidates[j].MonthlyCost {
+			return candidates[i].MonthlyCost > candidates[j].MonthlyCost
+		}
+		return candidates[i].Key < candidates[j].Key
+	})
+	return candidates, nil
+}
+
+// ConfirmSubscription начинает отслеживать найденный регулярный платеж
+func (s *SubscriptionService) ConfirmSubscription(ctx context.Context, userID, ledgerID uint, req dto.ConfirmSubscriptionRequest) (*dto.RecurringItemResponse, error) {
+	ctx, span := tracer.Start(ctx, "SubscriptionService.ConfirmSubscription")
+	defer span.End()
+
+	if err := s.ledgerService.CheckWriteAccess(ctx, userID, ledgerID); err != nil {
+		return nil, err
+	}
+
+	candidates, err := s.DetectSubscriptions(ctx, userID, ledgerID)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range candidates {
+		if c.Key != req.Key {
+			continue
+		}
+		if c.RecurringItemID != nil {
+			return nil, errors.New("subscription is already tracked")
+		}
+
+		item := &model.RecurringItem{
+			UserID:     userID,
+			LedgerID:   ledgerID,
+			Name:       c.Name,
+			Amount:     c.Amount,
+			Type:       "expense",
+			Period:     c.Period,
+			NextDate:   c.NextExpectedDate,
+			CategoryID: c.CategoryID,
+			PayeeID:    c.PayeeID,
+			MatchKey:   c.Key,
+		}
+		if err := s.recurringRepo.Create(ctx, item); err != nil {
+			return nil, fmt.Errorf("failed to create recurring item: %w", err)
+		}
+		response := newRecurringItemResponse(item)
+		return &response, nil
+	}
+	return nil, errors.New("subscription not found")
+}
+
+// GetRecurringItems возвращает отслеживаемые регулярные платежи бюджета
+func (s *SubscriptionService) GetRecurringItems(ctx context.Context, userID, ledgerID uint) ([]dto.RecurringItemResponse, error) {
+	ctx, span := tracer.Start(ctx, "SubscriptionService.GetRecurringItems")
+	defer span.End()
+
+	items, err := s.recurringRepo.GetByLedger(ctx, userID, ledgerID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dto.RecurringItemResponse, 0, len(items))
+	for i := range items {
+		response = append(response, newRecurringItemResponse(&items[i]))
+	}
+	return response, nil
+}
+
+// UpdateRecurringItem изменяет регулярный платеж, например после изменения цены
+func (s *SubscriptionService) UpdateRecurringItem(ctx context.Context, userID, ledgerID, id uint, req dto.RecurringItemRequest) (*dto.RecurringItemResponse, error) {
+	ctx, span := tracer.Start(ctx, "SubscriptionService.UpdateRecurringItem")
+	defer span.End()
+
+	if err := s.ledgerService.CheckWriteAccess(ctx, userID, ledgerID); err != nil {
+		return nil, err
+	}
+
+	item, err := s.recurringRepo.GetByID(ctx, userID, ledgerID, id)
+	if err != nil {
+		return nil, err
+	}
+	nextDate, err := time.Parse(time.RFC3339, req.NextDate)
+	if err != nil {
+		return nil, errors.New("invalid date format")
+	}
+	if req.CategoryID != nil {
+		if _, err := s.categoryRepo.GetByID(ctx, userID, ledgerID, *req.CategoryID); err != nil {
+			return nil, err
+		}
+	}
+
+	item.Name = req.Name
+	item.Amount = req.Amount
+	item.Type = req.Type
+	item.Period = req.Period
+	item.NextDate = nextDate
+	item.CategoryID = req.CategoryID
+	if err := s.recurringRepo.Update(ctx, item); err != nil {
+		return nil, fmt.Errorf("failed to update recurring item: %w", err)
+	}
+
+	response := newRecurringItemResponse(item)
+	return &response, nil
+}
+
+// DeleteRecurringItem прекращает отслеживать регулярный платеж
+func (s *SubscriptionService) DeleteRecurringItem(ctx context.Context, userID, ledgerID, id uint) error {
+	ctx, span := tracer.Start(ctx, "SubscriptionService.DeleteRecurringItem")
+	defer span.End()
+
+	if err := s.ledgerService.CheckWriteAccess(ctx, userID, ledgerID); err != nil {
+		return err
+	}
+	return s.recurringRepo.Delete(ctx, userID, ledgerID, id)
+}
+
+// detectSubscription проверяет, похожи ли списания группы на регулярный платеж.
+// Транзакции группы упорядочены по дате
+func detectSubscription(key string, group []*model.Transaction, now time.Time) (dto.SubscriptionCandidate, bool) {
+	var candidate dto.SubscriptionCandidate
+	if len(group) < 2 {
+		return candidate, false
+	}
+
+	intervals := make([]float64, 0, len(group)-1)
+	for i := 1; i < len(group); i++ {
+		intervals = append(intervals, group[i].Date.Sub(group[i-1].Date).Hours()/24)
+	}
+	typical := median(intervals)
+
+	var period *recurringPeriod
+	for i := range recurringPeriods {
+		if math.Abs(typical-recurringPeriods[i].days) <= recurringPeriods[i].tolerance {
+			period = &recurringPeriods[i]
+			break
+		}
+	}
+	if period == nil || len(group) < period.minOccurrences {
+		return candidate, false
+	}
+
+	regular := 0
+	for _, interval := range intervals {
+		if math.Abs(interval-period.days) <= period.tolerance {
+			regular++
+		}
+	}
+	if float64(regular) < subscriptionRegularShare*float64(len(intervals)) {
+		return candidate, false
+	}
+
+	amounts := make([]float64, len(group))
+	for i, t := range group {
+		amounts[i] = t.Amount
+	}
+	typicalAmount := median(amounts)
+	for _, amount := range amounts {
+		if math.Abs(amount-typicalAmount) > subscriptionAmountSpread*typicalAmount {
+			return candidate, false
+		}
+	}
+
+	// Платеж, пропущенный дольше чем на полпериода, считаем отмененным
+	last := group[len(group)-1]
+	if now.Sub(last.Date).Hours()/24 > period.days*1.5+period.tolerance {
+		return candidate, false
+	}
+
+	name := last.Description
+	if last.Payee != nil {
+		name = last.Payee.Name
+	}
+	monthly := last.Amount * period.perYear / 12
+	candidate = dto.SubscriptionCandidate{
+		Key:              key,
+		Name:             name,
+		PayeeID:          last.PayeeID,
+		CategoryID:       last.CategoryID,
+		Period:           period.name,
+		Amount:           last.Amount,
+		MonthlyCost:      roundMoney(monthly),
+		AnnualCost:       roundMoney(monthly * 12),
+		Occurrences:      len(group),
+		FirstDate:        group[0].Date,
+		LastDate:         last.Date,
+		NextExpectedDate: advancePeriod(last.Date, period.name),
+	}
+
+	previous := group[len(group)-2]
+	if math.Abs(last.Amount-previous.Amount) > priceChangeThreshold*previous.Amount {
+		candidate.PriceChange = &dto.PriceChange{
+			Previous:  previous.Amount,
+			Current:   last.Amount,
+			Percent:   math.Round((last.Amount-previous.Amount)/previous.Amount*10000) / 100,
+			ChangedAt: last.Date,
+		}
+	}
+	return candidate, true
+}
+
+// subscriptionKey группирует списания: по получателю, а без него — по нормализованному описанию
+func subscriptionKey(t *model.Transaction) string {
+	if t.PayeeID != nil {
+		return fmt.Sprintf("payee:%d", *t.PayeeID)
+	}
+	if text := normalizePayeeText(t.Description); text != "" {
+		return "text:" + text
+	}
+	return ""
+}
+
+// advancePeriod возвращает дату следующего платежа
+func advancePeriod(date time.Time, period string) time.Time {
+	switch period {
+	case model.PeriodWeekly:
+		return date.AddDate(0, 0, 7)
+	case model.PeriodBiweekly:
+		return date.AddDate(0, 0, 14)
+	case model.PeriodQuarterly:
+		return date.AddDate(0, 3, 0)
+	case model.PeriodYearly:
+		return date.AddDate(1, 0, 0)
+	default:
+		return date.AddDate(0, 1, 0)
+	}
+}
+
+// nextOccurrence возвращает ближайший платеж регулярной статьи не раньше now
+func nextOccurrence(item *model.RecurringItem, now time.Time) time.Time {
+	date := item.NextDate
+	for date.Before(now) {
+		date = advancePeriod(date, item.Period)
+	}
+	return date
+}
+
+// periodsPerYear возвращает число платежей в год
+func periodsPerYear(period string) float64 {
+	for _, p := range recurringPeriods {
+		if p.name == period {
+			return p.perYear
+		}
+	}
+	return 12
+}
+
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+func newRecurringItemResponse(item *model.RecurringItem) dto.RecurringItemResponse {
+	return dto.RecurringItemResponse{
+		ID:          item.ID,
+		Name:        item.Name,
+		Amount:      item.Amount,
+		Type:        item.Type,
+		Period:      item.Period,
+		NextDate:    nextOccurrence(item, time.Now()),
+		CategoryID:  item.CategoryID,
+		PayeeID:     item.PayeeID,
+		MonthlyCost: roundMoney(item.Amount * periodsPerYear(item.Period) / 12),
+	}
+}
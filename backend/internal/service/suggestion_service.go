@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"sync"
+
+	"finance-backend/internal/classifier"
+	"finance-backend/internal/dto"
+	"finance-backend/internal/model"
+	"finance-backend/internal/repository"
+)
+
+// maxSuggestionModels ограничивает число моделей в памяти; вытесненная модель
+// будет заново обучена при следующем запросе
+const maxSuggestionModels = 1000
+
+type suggestionKey struct {
+	userID   uint
+	ledgerID uint
+}
+
+// SuggestionService подсказывает категорию по истории пользователя в бюджете.
+// Модели обучаются лениво при первом запросе и дообучаются при изменении транзакций
+type SuggestionService struct {
+	transactionRepo *repository.TransactionRepository
+	categoryRepo    *repository.CategoryRepository
+
+	mu     sync.Mutex
+	models map[suggestionKey]*classifier.NaiveBayes
+}
+
+func NewSuggestionService(tr *repository.TransactionRepository, cr *repository.CategoryRepository) *SuggestionService {
+	return &SuggestionService{
+		transactionRepo: tr,
+		categoryRepo:    cr,
+		models:          make(map[suggestionKey]*classifier.NaiveBayes),
+	}
+}
+
+// SuggestCategories возвращает подходящие категории по убыванию уверенности
+func (s *SuggestionService) SuggestCategories(ctx context.Context, userID, ledgerID uint, description string, amount float64, kind string, limit int) ([]dto.CategorySuggestion, error) {
+	ctx, span := tracer.Start(ctx, "SuggestionService.SuggestCategories")
+	defer span.End()
+
+	suggestions := []dto.CategorySuggestion{}
+	if len(classifier.Tokenize(description)) == 0 {
+		return suggestions, nil
+	}
+	features := classifier.Features(description, amount, kind)
+
+	m, err := s.model(ctx, userID, ledgerID)
+	if err != nil {
+		return nil, err
+	}
+	predictions := m.Predict(features)
+	if len(predictions) == 0 {
+		return suggestions, nil
+	}
+
+	// Категория могла быть удалена или не подходить по типу
+	categories, err := s.categoryRepo.GetByLedger(ctx, userID, ledgerID)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]*model.Category, len(categories))
+	for i := range categories {
+		if kind == "" || categories[i].Type == kind {
+			byID[categories[i].ID] = &categories[i]
+		}
+	}
+
+	var total float64
+	for _, p := range predictions {
+		category, ok := byID[p.Class]
+		if !ok {
+			continue
+		}
+		total += p.Probability
+		suggestions = append(suggestions, dto.CategorySuggestion{
+			CategoryID: category.ID,
+			Name:       category.Name,
+			Color:      category.Color,
+			Confidence: p.Probability,
+		})
+	}
+	for i := range suggestions {
+		suggestions[i].Confidence /= total
+	}
+
+	if limit > 0 && len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+	return suggestions, nil
+}
+
+// Learn добавляет категоризированную транзакцию в уже загруженную модель
+func (s *SuggestionService) Learn(t *model.Transaction) {
+	if t.CategoryID == nil {
+		return
+	}
+	if m := s.loaded(t.UserID, t.LedgerID); m != nil {
+		m.Add(*t.CategoryID, classifier.Features(t.Description, t.Amount, t.Type))
+	}
+}
+
+// Forget убирает прежнее состояние транзакции из загруженной модели
+func (s *SuggestionService) Forget(t *model.Transaction) {
+	if t.CategoryID == nil {
+		return
+	}
+	if m := s.loaded(t.UserID, t.LedgerID); m != nil {
+		m.Remove(*t.CategoryID, classifier.Features(t.Description, t.Amount, t.Type))
+	}
+}
+
+// loaded возвращает модель, только если она уже в памяти
+func (s *SuggestionService) loaded(userID, ledgerID uint) *classifier.NaiveBayes {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.models[suggestionKey{userID, ledgerID}]
+}
+
+// model возвращает модель пользователя в бюджете, обучая ее по истории при необходимости
+func (s *SuggestionService) model(ctx context.Context, userID, ledgerID uint) (*classifier.NaiveBayes, error) {
+	key := suggestionKey{userID, ledgerID}
+	if m := s.loaded(userID, ledgerID); m != nil {
+		return m, nil
+	}
+
+	transactions, err := s.transactionRepo.GetCategorized(ctx, userID, ledgerID)
+	if err != nil {
+		return nil, err
+	}
+	m := classifier.New()
+	for _, t := range transactions {
+		m.Add(*t.CategoryID, classifier.Features(t.Description, t.Amount, t.Type))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// Модель могла быть обучена параллельным запросом
+	if existing, ok := s.models[key]; ok {
+		return existing, nil
+	}
+	if len(s.models) >= maxSuggestionModels {
+		for k := range s.models {
+			delete(s.models, k)
+			break
+		}
+	}
+	s.models[key] = m
+	return m, nil
+}
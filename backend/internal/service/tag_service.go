@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"finance-backend/internal/dto"
+	"finance-backend/internal/model"
+	"finance-backend/internal/repository"
+)
+
+type TagService struct {
+	tagRepo *repository.TagRepository
+}
+
+func NewTagService(tr *repository.TagRepository) *TagService {
+	return &TagService{tagRepo: tr}
+}
+
+// CreateTag создает тег пользователя
+func (s *TagService) CreateTag(ctx context.Context, userID uint, req dto.TagRequest) (*dto.TagResponse, error) {
+	ctx, span := tracer.Start(ctx, "TagService.CreateTag")
+	defer span.End()
+
+	if s.tagRepo.NameExists(ctx, userID, req.Name, 0) {
+		return nil, errors.New("tag with this name already exists")
+	}
+
+	tag := &model.Tag{UserID: userID, Name: req.Name, Color: req.Color}
+	if err := s.tagRepo.Create(ctx, tag); err != nil {
+		return nil, fmt.Errorf("failed to create tag: %w", err)
+	}
+
+	response := newTagResponse(tag)
+	return &response, nil
+}
+
+// GetUserTags возвращает теги пользователя
+func (s *TagService) GetUserTags(ctx context.Context, userID uint) ([]dto.TagResponse, error) {
+	ctx, span := tracer.Start(ctx, "TagService.GetUserTags")
+	defer span.End()
+
+	tags, err := s.tagRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := newTagResponses(tags)
+	if response == nil {
+		response = []dto.TagResponse{}
+	}
+	return response, nil
+}
+
+// UpdateTag переименовывает тег или меняет его цвет
+func (s *TagService) UpdateTag(ctx context.Context, userID, id uint, req dto.TagRequest) (*dto.TagResponse, error) {
+	ctx, span := tracer.Start(ctx, "TagService.UpdateTag")
+	defer span.End()
+
+	tag, err := s.tagRepo.GetByID(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if s.tagRepo.NameExists(ctx, userID, req.Name, id) {
+		return nil, errors.New("tag with this name already exists")
+	}
+
+	tag.Name = req.Name
+	if req.Color != "" {
+		tag.Color = req.Color
+	}
+	if err := s.tagRepo.Update(ctx, tag); err != nil {
+		return nil, fmt.Errorf("failed to update tag: %w", err)
+	}
+
+	response := newTagResponse(tag)
+	return &response, nil
+}
+
+// DeleteTag удаляет тег
+func (s *TagService) DeleteTag(ctx context.Context, userID, id uint) error {
+	ctx, span := tracer.Start(ctx, "TagService.DeleteTag")
+	defer span.End()
+
+	return s.tagRepo.Delete(ctx, userID, id)
+}
+
+// GetTagReport возвращает суммы по тегам пользователя в бюджете
+func (s *TagService) GetTagReport(ctx context.Context, userID, ledgerID uint, from, to *time.Time) ([]dto.TagReport, error) {
+	ctx, span := tracer.Start(ctx, "TagService.GetTagReport")
+	defer span.End()
+
+	totals, err := s.tagRepo.GetTotals(ctx, userID, ledgerID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	report := make([]dto.TagReport, 0, len(totals))
+	for _, t := range totals {
+		report = append(report, dto.TagReport{
+			TagID:        t.TagID,
+			Name:         t.Name,
+			Color:        t.Color,
+			TotalIncome:  t.TotalIncome,
+			TotalExpense: t.TotalExpense,
+			Balance:      t.TotalIncome - t.TotalExpense,
+			Count:        t.Count,
+		})
+	}
+	return report, nil
+}
+
+func newTagResponse(tag *model.Tag) dto.TagResponse {
+	return dto.TagResponse{ID: tag.ID, Name: tag.Name, Color: tag.Color}
+}
+
+func newTagResponses(tags []model.Tag) []dto.TagResponse {
+	var response []dto.TagResponse
+	for i := range tags {
+		response = append(response, newTagResponse(&tags[i]))
+	}
+	return response
+}
@@ -0,0 +1,6 @@
+package service
+
+import "go.opentelemetry.io/otel"
+
+// tracer создает спаны методов сервисов, вложенные в спан HTTP-запроса
+var tracer = otel.Tracer("finance-backend/internal/service")
@@ -0,0 +1,54 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"finance-backend/internal/dto"
+)
+
+func TestServiceSpans(t *testing.T) {
+	// Трассировщик пакета привязывается к первому глобальному провайдеру
+	rec := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+
+	e := newEnv()
+	user := register(t, e, "anna@example.com")
+	rec.Reset()
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "request")
+	createTransaction(t, e, user.ID, user.ID, dto.CreateTransactionRequest{
+		Amount: 100, Type: "expense", Date: "2024-03-01T10:00:00Z",
+	})
+	_, err := e.users.Login(ctx, dto.LoginRequest{Email: "anna@example.com", Password: "wrong"})
+	if err == nil {
+		t.Fatal("login with a wrong password succeeded")
+	}
+	parent.End()
+
+	byName := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range rec.Ended() {
+		byName[span.Name()] = span
+	}
+
+	created, ok := byName["TransactionService.CreateTransaction"]
+	if !ok {
+		t.Fatalf("no CreateTransaction span in %v", byName)
+	}
+	// Зависимости получают контекст со спаном метода
+	if e.ledgers.checkedIn.SpanID() != created.SpanContext().SpanID() {
+		t.Error("CheckWriteAccess did not get the CreateTransaction span context")
+	}
+
+	login, ok := byName["UserService.Login"]
+	if !ok {
+		t.Fatalf("no Login span in %v", byName)
+	}
+	if login.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Error("Login span is not a child of the caller span")
+	}
+}
@@ -1,28 +1,61 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"log/slog"
 	"time"
 
 	"finance-backend/internal/dto"
+	"finance-backend/internal/metrics"
 	"finance-backend/internal/model"
-	"finance-backend/internal/repository"
 )
 
 type TransactionService struct {
-	transactionRepo *repository.TransactionRepository
-	categoryRepo    *repository.CategoryRepository
+	transactionRepo TransactionRepository
+	categoryRepo    CategoryRepository
+	tagRepo         TagRepository
+	ledgerService   LedgerAccess
+	ruleService     RuleApplier
+	suggestions     CategoryLearner
+	payeeService    PayeeResolver
+	duplicates      DuplicateFinder
+	audit           AuditRecorder
 }
 
-func NewTransactionService(tr *repository.TransactionRepository, cr *repository.CategoryRepository) *TransactionService {
+func NewTransactionService(
+	tr TransactionRepository,
+	cr CategoryRepository,
+	tgr TagRepository,
+	ls LedgerAccess,
+	rs RuleApplier,
+	ss CategoryLearner,
+	ps PayeeResolver,
+	ds DuplicateFinder,
+	as AuditRecorder,
+) *TransactionService {
 	return &TransactionService{
 		transactionRepo: tr,
 		categoryRepo:    cr,
+		tagRepo:         tgr,
+		ledgerService:   ls,
+		ruleService:     rs,
+		suggestions:     ss,
+		payeeService:    ps,
+		duplicates:      ds,
+		audit:           as,
 	}
 }
 
 // CreateTransaction создает новую транзакцию
-func (s *TransactionService) CreateTransaction(userID uint, req dto.CreateTransactionRequest) (*model.Transaction, error) {
+func (s *TransactionService) CreateTransaction(ctx context.Context, userID, ledgerID uint, req dto.CreateTransactionRequest) (*model.Transaction, error) {
+	ctx, span := tracer.Start(ctx, "TransactionService.CreateTransaction")
+	defer span.End()
+
+	if err := s.ledgerService.CheckWriteAccess(ctx, userID, ledgerID); err != nil {
+		return nil, err
+	}
+
 	// Парсим дату
 	date, err := time.Parse(time.RFC3339, req.Date)
 	if err != nil {
@@ -31,28 +64,119 @@ func (s *TransactionService) CreateTransaction(userID uint, req dto.CreateTransa
 
 	// Проверяем категорию, если указана
 	if req.CategoryID != nil {
-		_, err := s.categoryRepo.GetByID(userID, *req.CategoryID)
-		if err != nil {
-			return nil, errors.New("category not found")
+		if _, err := s.categoryRepo.GetByID(ctx, userID, ledgerID, *req.CategoryID); err != nil {
+			return nil, err
 		}
 	}
 
+	// Теги должны принадлежать пользователю
+	tags, err := s.tagRepo.GetByIDs(ctx, userID, req.TagIDs)
+	if err != nil {
+		return nil, err
+	}
+
 	transaction := &model.Transaction{
 		UserID:      userID,
+		LedgerID:    ledgerID,
 		CategoryID:  req.CategoryID,
 		Amount:      req.Amount,
 		Type:        req.Type,
 		Description: req.Description,
+		Notes:       req.Notes,
 		Date:        date,
+		Tags:        tags,
+	}
+
+	if err := s.ruleService.ApplyRules(ctx, userID, transaction); err != nil {
+		return nil, err
+	}
+	if err := s.payeeService.ApplyPayee(ctx, userID, transaction, req.PayeeID, req.Description); err != nil {
+		return nil, err
+	}
+
+	if err := s.transactionRepo.Create(ctx, transaction); err != nil {
+		return nil, err
+	}
+	metrics.TransactionsCreated.WithLabelValues(transaction.Type).Inc()
+	s.suggestions.Learn(transaction)
+	s.audit.Record(ctx, userID, ledgerID, model.AuditEntityTransaction, transaction.ID, model.AuditActionCreate,
+		nil, auditTransaction(transaction))
+
+	// Транзакция уже сохранена, поэтому ошибка поиска дубликатов не должна ее отменять
+	duplicates, err := s.duplicates.FindFor(ctx, userID, transaction)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to find duplicates", "transaction_id", transaction.ID, "error", err)
+	}
+	transaction.PossibleDuplicates = duplicates
+	return transaction, nil
+}
+
+// UpdateTransaction изменяет транзакцию и заменяет теги пользователя на ней
+func (s *TransactionService) UpdateTransaction(ctx context.Context, userID, ledgerID uint, id uint, req dto.UpdateTransactionRequest) (*model.Transaction, error) {
+	ctx, span := tracer.Start(ctx, "TransactionService.UpdateTransaction")
+	defer span.End()
+
+	if err := s.ledgerService.CheckWriteAccess(ctx, userID, ledgerID); err != nil {
+		return nil, err
+	}
+
+	transaction, err := s.transactionRepo.GetByID(ctx, userID, ledgerID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	date, err := time.Parse(time.RFC3339, req.Date)
+	if err != nil {
+		return nil, errors.New("invalid date format")
+	}
+
+	if req.CategoryID != nil {
+		if _, err := s.categoryRepo.GetByID(ctx, userID, ledgerID, *req.CategoryID); err != nil {
+			return nil, err
+		}
 	}
 
-	err = s.transactionRepo.Create(transaction)
-	return transaction, err
+	if err := s.payeeService.ValidatePayee(ctx, userID, ledgerID, req.PayeeID); err != nil {
+		return nil, err
+	}
+
+	// Сумма общего расхода уже распределена между участниками
+	if transaction.PaidByID != nil && (req.Amount != transaction.Amount || req.Type != transaction.Type) {
+		return nil, errors.New("amount and type of a split expense cannot be changed")
+	}
+
+	tags, err := s.tagRepo.GetByIDs(ctx, userID, req.TagIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := *transaction
+	transaction.CategoryID = req.CategoryID
+	transaction.PayeeID = req.PayeeID
+	transaction.Amount = req.Amount
+	transaction.Type = req.Type
+	transaction.Description = req.Description
+	transaction.Notes = req.Notes
+	transaction.Date = date
+
+	if err := s.transactionRepo.UpdateWithTags(ctx, userID, transaction, req.TagIDs); err != nil {
+		return nil, err
+	}
+	s.suggestions.Forget(&previous)
+	s.suggestions.Learn(transaction)
+	s.audit.Record(ctx, userID, ledgerID, model.AuditEntityTransaction, transaction.ID, model.AuditActionUpdate,
+		auditTransaction(&previous), auditTransaction(transaction))
+
+	transaction.Tags = tags
+	return transaction, nil
 }
 
-// GetUserTransactions возвращает транзакции пользователя
-func (s *TransactionService) GetUserTransactions(userID uint, from, to *time.Time) ([]dto.TransactionResponse, error) {
-	transactions, err := s.transactionRepo.GetByUserID(userID, from, to)
+// GetLedgerTransactions возвращает транзакции бюджета
+func (s *TransactionService) GetLedgerTransactions(ctx context.Context, userID, ledgerID uint, filter dto.TransactionFilter) ([]dto.TransactionResponse, error) {
+	ctx, span := tracer.Start(ctx, "TransactionService.GetLedgerTransactions")
+	defer span.End()
+
+	transactions, err := s.transactionRepo.GetByLedger(ctx, userID, ledgerID, filter)
 	if err != nil {
 		return nil, err
 	}
@@ -60,30 +184,71 @@ func (s *TransactionService) GetUserTransactions(userID uint, from, to *time.Tim
 	// Преобразуем в DTO
 	var response []dto.TransactionResponse
 	for _, t := range transactions {
-		categoryName := ""
-		if t.Category != nil {
-			categoryName = t.Category.Name
-		}
-
-		response = append(response, dto.TransactionResponse{
-			ID:           t.ID,
-			Amount:       t.Amount,
-			Type:         t.Type,
-			Description:  t.Description,
-			Date:         t.Date,
-			CategoryName: categoryName,
-		})
+		response = append(response, newTransactionResponse(&t))
 	}
 
 	return response, nil
 }
 
 // GetFinancialSummary возвращает финансовую сводку
-func (s *TransactionService) GetFinancialSummary(userID uint, from, to *time.Time) (*dto.FinancialSummary, error) {
-	return s.transactionRepo.GetFinancialSummary(userID, from, to)
+func (s *TransactionService) GetFinancialSummary(ctx context.Context, userID, ledgerID uint, from, to *time.Time) (*dto.FinancialSummary, error) {
+	ctx, span := tracer.Start(ctx, "TransactionService.GetFinancialSummary")
+	defer span.End()
+
+	return s.transactionRepo.GetFinancialSummary(ctx, userID, ledgerID, from, to)
 }
 
 // DeleteTransaction удаляет транзакцию
-func (s *TransactionService) DeleteTransaction(userID uint, id uint) error {
-	return s.transactionRepo.Delete(userID, id)
+func (s *TransactionService) DeleteTransaction(ctx context.Context, userID, ledgerID uint, id uint) error {
+	ctx, span := tracer.Start(ctx, "TransactionService.DeleteTransaction")
+	defer span.End()
+
+	if err := s.ledgerService.CheckWriteAccess(ctx, userID, ledgerID); err != nil {
+		return err
+	}
+
+	transaction, err := s.transactionRepo.GetByID(ctx, userID, ledgerID, id)
+	if err != nil {
+		return err
+	}
+	if err := s.transactionRepo.Delete(ctx, userID, ledgerID, id); err != nil {
+		return err
+	}
+	s.suggestions.Forget(transaction)
+	s.audit.Record(ctx, userID, ledgerID, model.AuditEntityTransaction, id, model.AuditActionDelete,
+		auditTransaction(transaction), nil)
+	return nil
+}
+
+// newTransactionResponse преобразует транзакцию в DTO
+func newTransactionResponse(t *model.Transaction) dto.TransactionResponse {
+	categoryName := ""
+	if t.Category != nil {
+		categoryName = t.Category.Name
+	}
+	payeeName := ""
+	if t.Payee != nil {
+		payeeName = t.Payee.Name
+	}
+
+	var splits []dto.SplitResponse
+	for _, sp := range t.Splits {
+		splits = append(splits, dto.SplitResponse{UserID: sp.UserID, Amount: sp.Amount})
+	}
+
+	return dto.TransactionResponse{
+		ID:           t.ID,
+		CategoryID:   t.CategoryID,
+		Amount:       t.Amount,
+		Type:         t.Type,
+		Description:  t.Description,
+		Notes:        t.Notes,
+		Date:         t.Date,
+		CategoryName: categoryName,
+		PayeeID:      t.PayeeID,
+		PayeeName:    payeeName,
+		PaidByID:     t.PaidByID,
+		Splits:       splits,
+		Tags:         newTagResponses(t.Tags),
+	}
 }
@@ -0,0 +1,187 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"finance-backend/internal/dto"
+	"finance-backend/internal/model"
+	"finance-backend/internal/service"
+)
+
+func createTransaction(t *testing.T, e *env, userID, ledgerID uint, req dto.CreateTransactionRequest) *model.Transaction {
+	t.Helper()
+	transaction, err := e.txs.CreateTransaction(context.Background(), userID, ledgerID, req)
+	if err != nil {
+		t.Fatalf("CreateTransaction: %v", err)
+	}
+	return transaction
+}
+
+func tagNames(tags []dto.TagResponse) []string {
+	names := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		names = append(names, tag.Name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func TestCreateTransaction(t *testing.T) {
+	e := newEnv()
+	ctx := context.Background()
+	user := register(t, e, "anna@example.com")
+	ledgerID := user.ID
+	category, err := e.cats.CreateCategory(ctx, user.ID, ledgerID, dto.CreateCategoryRequest{Name: "Еда", Type: "expense"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	food := e.newTag(t, user.ID, "еда")
+	foreign := e.newTag(t, user.ID+100, "чужой")
+
+	tests := []struct {
+		name string
+		req  dto.CreateTransactionRequest
+		want string
+	}{
+		{"invalid date", dto.CreateTransactionRequest{Amount: 1, Type: "expense", Description: "x", Date: "10.03.2026"}, "invalid date format"},
+		{"foreign tag", dto.CreateTransactionRequest{Amount: 1, Type: "expense", Description: "x", Date: "2026-03-10T10:00:00Z", TagIDs: []uint{foreign.ID}}, "tag not found"},
+		{"missing category", dto.CreateTransactionRequest{Amount: 1, Type: "expense", Description: "x", Date: "2026-03-10T10:00:00Z", CategoryID: new(uint)}, "category not found"},
+	}
+	for _, tt := range tests {
+		if _, err := e.txs.CreateTransaction(ctx, user.ID, ledgerID, tt.req); err == nil || err.Error() != tt.want {
+			t.Errorf("%s: %v, want %q", tt.name, err, tt.want)
+		}
+	}
+
+	created := createTransaction(t, e, user.ID, ledgerID, dto.CreateTransactionRequest{
+		Amount: 350, Type: "expense", Description: "Обед", CategoryID: &category.ID,
+		Date: "2026-03-10T13:00:00+03:00", TagIDs: []uint{food.ID},
+	})
+	if created.ID == 0 || created.UserID != user.ID || created.LedgerID != ledgerID {
+		t.Errorf("created transaction: %+v", created)
+	}
+
+	list, err := e.txs.GetLedgerTransactions(ctx, user.ID, ledgerID, dto.TransactionFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].CategoryName != "Еда" || !slices.Equal(tagNames(list[0].Tags), []string{"еда"}) {
+		t.Errorf("transactions = %+v", list)
+	}
+	if !slices.Contains(e.audit.actions, model.AuditEntityTransaction+":"+model.AuditActionCreate) {
+		t.Errorf("audit = %v, want a create record", e.audit.actions)
+	}
+}
+
+func TestTransactionAccess(t *testing.T) {
+	e := newEnv()
+	ctx := context.Background()
+	owner := register(t, e, "owner@example.com")
+	viewer := register(t, e, "viewer@example.com")
+	stranger := register(t, e, "stranger@example.com")
+	ledgerID := owner.ID
+	e.ledgers.addMember(ledgerID, viewer.ID, model.RoleViewer)
+
+	transaction := createTransaction(t, e, owner.ID, ledgerID, dto.CreateTransactionRequest{
+		Amount: 100, Type: "expense", Description: "Такси", Date: "2026-03-10T10:00:00Z",
+	})
+
+	req := dto.CreateTransactionRequest{Amount: 1, Type: "expense", Description: "x", Date: "2026-03-10T10:00:00Z"}
+	if _, err := e.txs.CreateTransaction(ctx, viewer.ID, ledgerID, req); !errors.Is(err, service.ErrForbidden) {
+		t.Errorf("CreateTransaction by viewer: %v, want ErrForbidden", err)
+	}
+	if err := e.txs.DeleteTransaction(ctx, viewer.ID, ledgerID, transaction.ID); !errors.Is(err, service.ErrForbidden) {
+		t.Errorf("DeleteTransaction by viewer: %v, want ErrForbidden", err)
+	}
+	if err := e.txs.DeleteTransaction(ctx, stranger.ID, ledgerID, transaction.ID); err == nil {
+		t.Error("DeleteTransaction by non-member succeeded")
+	}
+
+	// Участник с правом чтения видит транзакции бюджета, посторонний — нет
+	if list, _ := e.txs.GetLedgerTransactions(ctx, viewer.ID, ledgerID, dto.TransactionFilter{}); len(list) != 1 {
+		t.Errorf("viewer sees %d transactions, want 1", len(list))
+	}
+	if list, _ := e.txs.GetLedgerTransactions(ctx, stranger.ID, ledgerID, dto.TransactionFilter{}); len(list) != 0 {
+		t.Errorf("non-member sees %d transactions", len(list))
+	}
+	if summary, _ := e.txs.GetFinancialSummary(ctx, stranger.ID, ledgerID, nil, nil); summary.TotalExpense != 0 {
+		t.Errorf("non-member summary = %+v", summary)
+	}
+}
+
+func TestUpdateTransaction(t *testing.T) {
+	e := newEnv()
+	ctx := context.Background()
+	user := register(t, e, "anna@example.com")
+	ledgerID := user.ID
+	trip := e.newTag(t, user.ID, "поездка")
+	gift := e.newTag(t, user.ID, "подарок")
+	transaction := createTransaction(t, e, user.ID, ledgerID, dto.CreateTransactionRequest{
+		Amount: 100, Type: "expense", Description: "Билеты", Date: "2026-03-10T10:00:00Z", TagIDs: []uint{trip.ID},
+	})
+
+	updated, err := e.txs.UpdateTransaction(ctx, user.ID, ledgerID, transaction.ID, dto.UpdateTransactionRequest{
+		Amount: 250, Type: "expense", Description: "Билеты на поезд", Date: "2026-03-11T10:00:00Z", TagIDs: []uint{gift.ID},
+	})
+	if err != nil {
+		t.Fatalf("UpdateTransaction: %v", err)
+	}
+	if updated.Amount != 250 || updated.Description != "Билеты на поезд" {
+		t.Errorf("updated transaction: %+v", updated)
+	}
+
+	list, err := e.txs.GetLedgerTransactions(ctx, user.ID, ledgerID, dto.TransactionFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].Amount != 250 || !slices.Equal(tagNames(list[0].Tags), []string{"подарок"}) {
+		t.Errorf("transactions after update = %+v", list)
+	}
+
+	_, err = e.txs.UpdateTransaction(ctx, user.ID, ledgerID, 999, dto.UpdateTransactionRequest{
+		Amount: 1, Type: "expense", Description: "x", Date: "2026-03-11T10:00:00Z",
+	})
+	if err == nil || err.Error() != "transaction not found" {
+		t.Errorf("UpdateTransaction of a missing transaction: %v", err)
+	}
+}
+
+func TestDeleteTransaction(t *testing.T) {
+	e := newEnv()
+	ctx := context.Background()
+	user := register(t, e, "anna@example.com")
+	ledgerID := user.ID
+	income := createTransaction(t, e, user.ID, ledgerID, dto.CreateTransactionRequest{
+		Amount: 1000, Type: "income", Description: "Зарплата", Date: "2026-03-01T10:00:00Z",
+	})
+	expense := createTransaction(t, e, user.ID, ledgerID, dto.CreateTransactionRequest{
+		Amount: 300, Type: "expense", Description: "Кафе", Date: "2026-03-02T10:00:00Z",
+	})
+
+	summary, err := e.txs.GetFinancialSummary(ctx, user.ID, ledgerID, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Balance != 700 {
+		t.Errorf("balance = %v, want 700", summary.Balance)
+	}
+
+	if err := e.txs.DeleteTransaction(ctx, user.ID, ledgerID, expense.ID); err != nil {
+		t.Fatalf("DeleteTransaction: %v", err)
+	}
+	if err := e.txs.DeleteTransaction(ctx, user.ID, ledgerID, expense.ID); err == nil || err.Error() != "transaction not found" {
+		t.Errorf("second DeleteTransaction: %v", err)
+	}
+
+	list, _ := e.txs.GetLedgerTransactions(ctx, user.ID, ledgerID, dto.TransactionFilter{})
+	if len(list) != 1 || list[0].ID != income.ID {
+		t.Errorf("transactions after delete = %+v", list)
+	}
+	summary, _ = e.txs.GetFinancialSummary(ctx, user.ID, ledgerID, nil, nil)
+	if summary.Balance != 1000 {
+		t.Errorf("balance after delete = %v, want 1000", summary.Balance)
+	}
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"finance-backend/internal/dto"
+	"finance-backend/internal/model"
+	"finance-backend/internal/repository"
+)
+
+type TrashService struct {
+	transactionRepo *repository.TransactionRepository
+	categoryRepo    *repository.CategoryRepository
+	ledgerService   *LedgerService
+	suggestions     *SuggestionService
+	audit           *AuditService
+	retention       time.Duration
+}
+
+func NewTrashService(
+	tr *repository.TransactionRepository,
+	cr *repository.CategoryRepository,
+	ls *LedgerService,
+	ss *SuggestionService,
+	as *AuditService,
+	retention time.Duration,
+) *TrashService {
+	return &TrashService{
+		transactionRepo: tr,
+		categoryRepo:    cr,
+		ledgerService:   ls,
+		suggestions:     ss,
+		audit:           as,
+		retention:       retention,
+	}
+}
+
+// GetTrash возвращает удаленные транзакции и категории бюджета
+func (s *TrashService) GetTrash(ctx context.Context, userID, ledgerID uint) (*dto.TrashResponse, error) {
+	ctx, span := tracer.Start(ctx, "TrashService.GetTrash")
+	defer span.End()
+
+	transactions, err := s.transactionRepo.GetTrashed(ctx, userID, ledgerID)
+	if err != nil {
+		return nil, err
+	}
+	categories, err := s.categoryRepo.GetTrashed(ctx, userID, ledgerID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.TrashResponse{
+		Transactions: make([]dto.TrashedTransaction, 0, len(transactions)),
+		Categories:   make([]dto.TrashedCategory, 0, len(categories)),
+	}
+	for i := range transactions {
+		t := &transactions[i]
+		response.Transactions = append(response.Transactions, dto.TrashedTransaction{
+			TransactionResponse: newTransactionResponse(t),
+			DeletedAt:           t.DeletedAt.Time,
+			PurgeAt:             t.DeletedAt.Time.Add(s.retention),
+		})
+	}
+	for _, c := range categories {
+		response.Categories = append(response.Categories, dto.TrashedCategory{
+			ID:        c.ID,
+			Name:      c.Name,
+			Type:      c.Type,
+			Color:     c.Color,
+			DeletedAt: c.DeletedAt.Time,
+			PurgeAt:   c.DeletedAt.Time.Add(s.retention),
+		})
+	}
+	return response, nil
+}
+
+// RestoreTransaction возвращает транзакцию из корзины
+func (s *TrashService) RestoreTransaction(ctx context.Context, userID, ledgerID, id uint) (*dto.TransactionResponse, error) {
+	ctx, span := tracer.Start(ctx, "TrashService.RestoreTransaction")
+	defer span.End()
+
+	if err := s.ledgerService.CheckWriteAccess(ctx, userID, ledgerID); err != nil {
+		return nil, err
+	}
+
+	transaction, err := s.transactionRepo.Restore(ctx, userID, ledgerID, id)
+	if err != nil {
+		return nil, err
+	}
+	s.suggestions.Learn(transaction)
+	s.audit.Record(ctx, userID, ledgerID, model.AuditEntityTransaction, id, model.AuditActionRestore,
+		nil, auditTransaction(transaction))
+
+	response := newTransactionResponse(transaction)
+	return &response, nil
+}
+
+// RestoreCategory возвращает категорию из корзины вместе со ссылками транзакций на нее
+func (s *TrashService) RestoreCategory(ctx context.Context, userID, ledgerID, id uint) (*model.Category, error) {
+	ctx, span := tracer.Start(ctx, "TrashService.RestoreCategory")
+	defer span.End()
+
+	if err := s.ledgerService.CheckWriteAccess(ctx, userID, ledgerID); err != nil {
+		return nil, err
+	}
+
+	category, err := s.categoryRepo.Restore(ctx, userID, ledgerID, id)
+	if err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, userID, ledgerID, model.AuditEntityCategory, id, model.AuditActionRestore,
+		nil, auditCategory(category))
+	return category, nil
+}
+
+// PurgeExpired окончательно удаляет записи, пролежавшие в корзине дольше срока хранения
+func (s *TrashService) PurgeExpired(ctx context.Context) error {
+	ctx, span := tracer.Start(ctx, "TrashService.PurgeExpired")
+	defer span.End()
+
+	before := time.Now().Add(-s.retention)
+
+	transactions, err := s.transactionRepo.PurgeDeleted(ctx, before)
+	if err != nil {
+		return fmt.Errorf("purge transactions: %w", err)
+	}
+	categories, err := s.categoryRepo.PurgeDeleted(ctx, before)
+	if err != nil {
+		return fmt.Errorf("purge categories: %w", err)
+	}
+
+	if transactions > 0 || categories > 0 {
+		slog.InfoContext(ctx, "trash purged", "transactions", transactions, "categories", categories)
+	}
+	return nil
+}
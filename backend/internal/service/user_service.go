@@ -1,29 +1,48 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"time"
 
 	"finance-backend/internal/dto"
+	"finance-backend/internal/mailer"
+	"finance-backend/internal/metrics"
 	"finance-backend/internal/model"
 	"finance-backend/internal/repository"
 )
 
+const (
+	verificationTokenTTL     = 24 * time.Hour
+	verificationResendPeriod = time.Minute
+)
+
 type UserService struct {
-	userRepo    *repository.UserRepository
+	userRepo    UserRepository
 	authService *AuthService
+	mailer      mailer.Mailer
+	appURL      string
 }
 
-func NewUserService(userRepo *repository.UserRepository, authService *AuthService) *UserService {
+func NewUserService(userRepo UserRepository, authService *AuthService, m mailer.Mailer, appURL string) *UserService {
 	return &UserService{
 		userRepo:    userRepo,
 		authService: authService,
+		mailer:      m,
+		appURL:      appURL,
 	}
 }
 
 // Register регистрирует нового пользователя
-func (s *UserService) Register(req dto.RegisterRequest) (*model.User, error) {
+func (s *UserService) Register(ctx context.Context, req dto.RegisterRequest) (*model.User, error) {
+	ctx, span := tracer.Start(ctx, "UserService.Register")
+	defer span.End()
+
 	// Проверяем, нет ли уже пользователя с таким email
-	if s.userRepo.EmailExists(req.Email) {
+	if s.userRepo.EmailExists(ctx, req.Email) {
 		return nil, errors.New("user with this email already exists")
 	}
 
@@ -40,24 +59,37 @@ func (s *UserService) Register(req dto.RegisterRequest) (*model.User, error) {
 		LastName:  req.LastName,
 	}
 
-	err = s.userRepo.Create(user)
+	err = s.userRepo.CreateWithPersonalLedger(ctx, user, &model.Ledger{Name: "Personal"})
 	if err != nil {
-		return nil, errors.New("failed to create user")
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	// Ошибка отправки письма не должна мешать регистрации: письмо можно запросить повторно
+	if err := s.sendVerificationEmail(ctx, user); err != nil {
+		slog.WarnContext(ctx, "failed to send verification email", "user_id", user.ID, "error", err)
 	}
 
 	return user, nil
 }
 
 // Login аутентифицирует пользователя
-func (s *UserService) Login(req dto.LoginRequest) (*model.User, error) {
+func (s *UserService) Login(ctx context.Context, req dto.LoginRequest) (*model.User, error) {
+	ctx, span := tracer.Start(ctx, "UserService.Login")
+	defer span.End()
+
 	// Находим пользователя по email
-	user, err := s.userRepo.GetByEmail(req.Email)
+	user, err := s.userRepo.GetByEmail(ctx, req.Email)
+	if errors.Is(err, repository.ErrDatabase) {
+		return nil, err
+	}
 	if err != nil {
+		metrics.LoginFailures.Inc()
 		return nil, errors.New("invalid email or password")
 	}
 
 	// Проверяем пароль
 	if !s.authService.CheckPassword(req.Password, user.Password) {
+		metrics.LoginFailures.Inc()
 		return nil, errors.New("invalid email or password")
 	}
 
@@ -65,6 +97,192 @@ func (s *UserService) Login(req dto.LoginRequest) (*model.User, error) {
 }
 
 // GetUserByID возвращает пользователя по ID
-func (s *UserService) GetUserByID(userID uint) (*model.User, error) {
-	return s.userRepo.GetByID(userID)
+func (s *UserService) GetUserByID(ctx context.Context, userID uint) (*model.User, error) {
+	ctx, span := tracer.Start(ctx, "UserService.GetUserByID")
+	defer span.End()
+
+	return s.userRepo.GetByID(ctx, userID)
+}
+
+// IsEmailVerified проверяет, подтвердил ли пользователь email
+func (s *UserService) IsEmailVerified(ctx context.Context, userID uint) (bool, error) {
+	ctx, span := tracer.Start(ctx, "UserService.IsEmailVerified")
+	defer span.End()
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	return user.EmailVerified, nil
+}
+
+// ResendVerificationEmail повторно отправляет письмо с подтверждением email
+func (s *UserService) ResendVerificationEmail(ctx context.Context, userID uint) error {
+	ctx, span := tracer.Start(ctx, "UserService.ResendVerificationEmail")
+	defer span.End()
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if user.EmailVerified {
+		return errors.New("email is already verified")
+	}
+	if user.VerificationSentAt != nil && time.Since(*user.VerificationSentAt) < verificationResendPeriod {
+		return errors.New("verification email was sent recently, try again later")
+	}
+
+	if err := s.sendVerificationEmail(ctx, user); err != nil {
+		return errors.New("failed to send verification email")
+	}
+	return nil
+}
+
+// VerifyEmail подтверждает email по токену из письма
+func (s *UserService) VerifyEmail(ctx context.Context, token string) (*model.User, error) {
+	ctx, span := tracer.Start(ctx, "UserService.VerifyEmail")
+	defer span.End()
+
+	userID, email, err := s.authService.ParseEmailToken(PurposeEmailVerification, token)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ссылка, выданная для старого адреса, не подтверждает новый
+	if user.Email != email {
+		return nil, errors.New("invalid or expired token")
+	}
+	if user.EmailVerified {
+		return user, nil
+	}
+
+	now := time.Now()
+	user.EmailVerified = true
+	user.EmailVerifiedAt = &now
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to update user: %w", err)
+	}
+
+	return user, nil
+}
+
+// UpdateProfile обновляет имя и фамилию пользователя
+func (s *UserService) UpdateProfile(ctx context.Context, userID uint, req dto.UpdateProfileRequest) (*model.User, error) {
+	ctx, span := tracer.Start(ctx, "UserService.UpdateProfile")
+	defer span.End()
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	user.FirstName = req.FirstName
+	user.LastName = req.LastName
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to update user: %w", err)
+	}
+
+	return user, nil
+}
+
+// RequestEmailChange отправляет ссылку для подтверждения нового email
+func (s *UserService) RequestEmailChange(ctx context.Context, userID uint, req dto.ChangeEmailRequest) error {
+	ctx, span := tracer.Start(ctx, "UserService.RequestEmailChange")
+	defer span.End()
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if !s.authService.CheckPassword(req.Password, user.Password) {
+		return errors.New("invalid password")
+	}
+	if req.NewEmail == user.Email {
+		return errors.New("new email matches the current one")
+	}
+	if s.userRepo.EmailExists(ctx, req.NewEmail) {
+		return errors.New("user with this email already exists")
+	}
+
+	token, err := s.authService.GenerateEmailToken(PurposeEmailChange, user.ID, req.NewEmail, verificationTokenTTL)
+	if err != nil {
+		return errors.New("failed to generate token")
+	}
+
+	link := s.appURL + "/api/auth/email/confirm?token=" + url.QueryEscape(token)
+	body := fmt.Sprintf("Hello, %s!\n\n"+
+		"To change the email of your account to this address, open the link below:\n%s\n\n"+
+		"The link is valid for 24 hours. If you did not request the change, ignore this email.\n", user.FirstName, link)
+
+	if err := s.mailer.Send(req.NewEmail, "Confirm your new email", body); err != nil {
+		return errors.New("failed to send confirmation email")
+	}
+	return nil
+}
+
+// ConfirmEmailChange применяет смену email по токену из письма
+func (s *UserService) ConfirmEmailChange(ctx context.Context, token string) (*model.User, error) {
+	ctx, span := tracer.Start(ctx, "UserService.ConfirmEmailChange")
+	defer span.End()
+
+	userID, newEmail, err := s.authService.ParseEmailToken(PurposeEmailChange, token)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Email == newEmail {
+		return user, nil
+	}
+	if s.userRepo.EmailExists(ctx, newEmail) {
+		return nil, errors.New("user with this email already exists")
+	}
+
+	oldEmail := user.Email
+	now := time.Now()
+	user.Email = newEmail
+	user.EmailVerified = true
+	user.EmailVerifiedAt = &now
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to update user: %w", err)
+	}
+
+	// Уведомляем старый адрес, чтобы владелец заметил несанкционированную смену
+	body := fmt.Sprintf("Hello, %s!\n\nThe email of your account was changed to %s.\n", user.FirstName, newEmail)
+	if err := s.mailer.Send(oldEmail, "Your email was changed", body); err != nil {
+		slog.WarnContext(ctx, "failed to notify user about email change", "user_id", user.ID, "error", err)
+	}
+
+	return user, nil
+}
+
+// sendVerificationEmail отправляет ссылку для подтверждения email
+func (s *UserService) sendVerificationEmail(ctx context.Context, user *model.User) error {
+	token, err := s.authService.GenerateEmailToken(PurposeEmailVerification, user.ID, user.Email, verificationTokenTTL)
+	if err != nil {
+		return err
+	}
+
+	link := s.appURL + "/api/auth/verify-email?token=" + url.QueryEscape(token)
+	body := fmt.Sprintf("Hello, %s!\n\n"+
+		"Please confirm your email address by opening the link below:\n%s\n\n"+
+		"The link is valid for 24 hours.\n", user.FirstName, link)
+
+	if err := s.mailer.Send(user.Email, "Confirm your email", body); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	user.VerificationSentAt = &now
+	return s.userRepo.Update(ctx, user)
 }
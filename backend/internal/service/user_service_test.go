@@ -0,0 +1,133 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"finance-backend/internal/dto"
+	"finance-backend/internal/model"
+)
+
+func register(t *testing.T, e *env, email string) *model.User {
+	t.Helper()
+	user, err := e.users.Register(context.Background(), dto.RegisterRequest{
+		Email: email, Password: "secret1", FirstName: "Анна", LastName: "Иванова",
+	})
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	return user
+}
+
+func TestRegister(t *testing.T) {
+	e := newEnv()
+	ctx := context.Background()
+	user := register(t, e, "anna@example.com")
+
+	if user.Password == "secret1" {
+		t.Error("password is stored in plain text")
+	}
+	if user.EmailVerified {
+		t.Error("new user has a verified email")
+	}
+	if err := e.ledgers.CheckWriteAccess(ctx, user.ID, user.ID); err != nil {
+		t.Errorf("personal ledger is not created: %v", err)
+	}
+	if len(e.mailbox.sent) != 1 || e.mailbox.sent[0].to != "anna@example.com" {
+		t.Errorf("sent = %v, want one verification email", e.mailbox.sent)
+	}
+
+	_, err := e.users.Register(ctx, dto.RegisterRequest{Email: "anna@example.com", Password: "secret2"})
+	if err == nil || err.Error() != "user with this email already exists" {
+		t.Errorf("second Register: %v", err)
+	}
+}
+
+func TestLogin(t *testing.T) {
+	e := newEnv()
+	ctx := context.Background()
+	user := register(t, e, "anna@example.com")
+
+	got, err := e.users.Login(ctx, dto.LoginRequest{Email: "anna@example.com", Password: "secret1"})
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	if got.ID != user.ID {
+		t.Errorf("Login returned user %d, want %d", got.ID, user.ID)
+	}
+
+	for _, req := range []dto.LoginRequest{
+		{Email: "anna@example.com", Password: "wrong"},
+		{Email: "nobody@example.com", Password: "secret1"},
+	} {
+		if _, err := e.users.Login(ctx, req); err == nil || err.Error() != "invalid email or password" {
+			t.Errorf("Login(%s, %s): %v", req.Email, req.Password, err)
+		}
+	}
+}
+
+func TestVerifyEmail(t *testing.T) {
+	e := newEnv()
+	ctx := context.Background()
+	user := register(t, e, "anna@example.com")
+
+	if _, err := e.users.VerifyEmail(ctx, "garbage"); err == nil {
+		t.Error("VerifyEmail accepted an invalid token")
+	}
+
+	verified, err := e.users.VerifyEmail(ctx, e.mailbox.token(t, "anna@example.com"))
+	if err != nil {
+		t.Fatalf("VerifyEmail: %v", err)
+	}
+	if !verified.EmailVerified || verified.EmailVerifiedAt == nil {
+		t.Errorf("user after verification: %+v", verified)
+	}
+	if ok, err := e.users.IsEmailVerified(ctx, user.ID); err != nil || !ok {
+		t.Errorf("IsEmailVerified = %v, %v", ok, err)
+	}
+	if err := e.users.ResendVerificationEmail(ctx, user.ID); err == nil {
+		t.Error("ResendVerificationEmail succeeded for a verified email")
+	}
+}
+
+func TestEmailChange(t *testing.T) {
+	e := newEnv()
+	ctx := context.Background()
+	user := register(t, e, "anna@example.com")
+	register(t, e, "taken@example.com")
+
+	tests := []struct {
+		req  dto.ChangeEmailRequest
+		want string
+	}{
+		{dto.ChangeEmailRequest{NewEmail: "new@example.com", Password: "wrong"}, "invalid password"},
+		{dto.ChangeEmailRequest{NewEmail: "anna@example.com", Password: "secret1"}, "new email matches the current one"},
+		{dto.ChangeEmailRequest{NewEmail: "taken@example.com", Password: "secret1"}, "user with this email already exists"},
+	}
+	for _, tt := range tests {
+		if err := e.users.RequestEmailChange(ctx, user.ID, tt.req); err == nil || err.Error() != tt.want {
+			t.Errorf("RequestEmailChange(%s): %v, want %q", tt.req.NewEmail, err, tt.want)
+		}
+	}
+
+	err := e.users.RequestEmailChange(ctx, user.ID, dto.ChangeEmailRequest{NewEmail: "new@example.com", Password: "secret1"})
+	if err != nil {
+		t.Fatalf("RequestEmailChange: %v", err)
+	}
+	changed, err := e.users.ConfirmEmailChange(ctx, e.mailbox.token(t, "new@example.com"))
+	if err != nil {
+		t.Fatalf("ConfirmEmailChange: %v", err)
+	}
+	if changed.Email != "new@example.com" || !changed.EmailVerified {
+		t.Errorf("user after email change: %+v", changed)
+	}
+	if _, err := e.users.Login(ctx, dto.LoginRequest{Email: "new@example.com", Password: "secret1"}); err != nil {
+		t.Errorf("Login with the new email: %v", err)
+	}
+
+	// Старый адрес получает уведомление о смене
+	last := e.mailbox.sent[len(e.mailbox.sent)-1]
+	if last.to != "anna@example.com" {
+		t.Errorf("last email sent to %s, want the old address", last.to)
+	}
+}
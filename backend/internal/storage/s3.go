@@ -0,0 +1,278 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// S3Storage хранит файлы в S3-совместимом хранилище (AWS S3, MinIO и т.п.).
+// Запросы подписываются AWS Signature V4, адресация бакета — path-style.
+type S3Storage struct {
+	endpoint  *url.URL
+	region    string
+	bucket    string
+	accessKey string
+	secretKey string
+	client    *http.Client
+	now       func() time.Time // время подписи; подменяется в тестах
+}
+
+func NewS3Storage(endpoint, region, bucket, accessKey, secretKey string) (*S3Storage, error) {
+	u, err := url.Parse(strings.TrimRight(endpoint, "/"))
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, errors.New("invalid S3 endpoint")
+	}
+	if bucket == "" {
+		return nil, errors.New("S3 bucket is required")
+	}
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	return &S3Storage{
+		endpoint:  u,
+		region:    region,
+		bucket:    bucket,
+		accessKey: accessKey,
+		secretKey: secretKey,
+		client:    &http.Client{Timeout: 60 * time.Second},
+		now:       time.Now,
+	}, nil
+}
+
+// Put загружает объект
+func (s *S3Storage) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	if !validKey(key) {
+		return errors.New("invalid storage key")
+	}
+
+	// Подпись требует хеш тела, поэтому объект читается целиком; размер ограничен выше
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	req, err := s.newRequest(ctx, http.MethodPut, key, body)
+	if err != nil {
+		return err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	s.sign(req, body)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return s.responseError(resp)
+	}
+	return nil
+}
+
+// Get скачивает объект
+func (s *S3Storage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	if !validKey(key) {
+		return nil, errors.New("invalid storage key")
+	}
+
+	req, err := s.newRequest(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	s.sign(req, nil)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp.Body, nil
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return nil, ErrNotFound
+	default:
+		defer resp.Body.Close()
+		return nil, s.responseError(resp)
+	}
+}
+
+// Delete удаляет объект; S3 не считает отсутствие объекта ошибкой
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	if !validKey(key) {
+		return errors.New("invalid storage key")
+	}
+
+	req, err := s.newRequest(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+	s.sign(req, nil)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return s.responseError(resp)
+	}
+	return nil
+}
+
+func (s *S3Storage) newRequest(ctx context.Context, method, key string, body []byte) (*http.Request, error) {
+	u := *s.endpoint
+	u.Path = strings.TrimRight(u.Path, "/") + "/" + s.bucket + "/" + key
+	u.RawPath = strings.TrimRight(s.endpoint.EscapedPath(), "/") + "/" + uriEncode(s.bucket) + "/" + uriEncodePath(key)
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	return http.NewRequestWithContext(ctx, method, u.String(), reader)
+}
+
+// sign добавляет к запросу заголовки подписи AWS Signature V4
+func (s *S3Storage) sign(req *http.Request, body []byte) {
+	now := s.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	canonicalRequest, signedHeaders := canonicalRequest(req, payloadHash)
+
+	scope := date + "/" + s.region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.secretKey), date)
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.accessKey, scope, signedHeaders, signature))
+}
+
+// canonicalRequest собирает каноничный запрос AWS Signature V4 и список
+// подписанных заголовков: host, content-type и x-amz-*
+func canonicalRequest(req *http.Request, payloadHash string) (string, string) {
+	headers := map[string]string{
+		"host":                 req.URL.Host,
+		"x-amz-date":           req.Header.Get("X-Amz-Date"),
+		"x-amz-content-sha256": payloadHash,
+	}
+	if ct := req.Header.Get("Content-Type"); ct != "" {
+		headers["content-type"] = ct
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(headers[name]) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	return strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n"), signedHeaders
+}
+
+// canonicalQuery кодирует параметры запроса и сортирует их по имени, затем по значению;
+// параметр без значения записывается как "name="
+func canonicalQuery(query url.Values) string {
+	type param struct{ name, value string }
+	var params []param
+	for name, values := range query {
+		for _, v := range values {
+			params = append(params, param{uriEncode(name), uriEncode(v)})
+		}
+	}
+	sort.Slice(params, func(i, j int) bool {
+		if params[i].name != params[j].name {
+			return params[i].name < params[j].name
+		}
+		return params[i].value < params[j].value
+	})
+
+	parts := make([]string, len(params))
+	for i, p := range params {
+		parts[i] = p.name + "=" + p.value
+	}
+	return strings.Join(parts, "&")
+}
+
+func (s *S3Storage) responseError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("s3 %s: %s", resp.Status, strings.TrimSpace(string(body)))
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// uriEncodePath кодирует ключ объекта по правилам S3, сохраняя разделители "/"
+func uriEncodePath(key string) string {
+	parts := strings.Split(key, "/")
+	for i, part := range parts {
+		parts[i] = uriEncode(part)
+	}
+	return strings.Join(parts, "/")
+}
+
+// uriEncode кодирует все символы, кроме незарезервированных (RFC 3986)
+func uriEncode(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
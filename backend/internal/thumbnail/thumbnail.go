@@ -0,0 +1,91 @@
+package thumbnail
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+)
+
+// maxPixels защищает от изображений, распаковка которых займет слишком много памяти
+const maxPixels = 50_000_000
+
+// Generate уменьшает изображение так, чтобы большая сторона не превышала maxSide,
+// и кодирует результат в JPEG
+func Generate(data []byte, maxSide int) ([]byte, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > maxPixels {
+		return nil, errors.New("unsupported image dimensions")
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	dst := resize(src, maxSide)
+
+	// В JPEG нет прозрачности, поэтому подкладываем белый фон
+	out := image.NewRGBA(dst.Bounds())
+	draw.Draw(out, out.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(out, out.Bounds(), dst, image.Point{}, draw.Over)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, out, &jpeg.Options{Quality: 80}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// resize уменьшает изображение усреднением пикселей исходной области (box filter)
+func resize(src image.Image, maxSide int) *image.RGBA64 {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+
+	dw, dh := w, h
+	if w > maxSide || h > maxSide {
+		if w >= h {
+			dw, dh = maxSide, max(1, h*maxSide/w)
+		} else {
+			dw, dh = max(1, w*maxSide/h), maxSide
+		}
+	}
+
+	dst := image.NewRGBA64(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		sy0 := b.Min.Y + y*h/dh
+		sy1 := max(b.Min.Y+(y+1)*h/dh, sy0+1)
+
+		for x := 0; x < dw; x++ {
+			sx0 := b.Min.X + x*w/dw
+			sx1 := max(b.Min.X+(x+1)*w/dw, sx0+1)
+
+			var r, g, bl, a, n uint64
+			for sy := sy0; sy < sy1; sy++ {
+				for sx := sx0; sx < sx1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					r += uint64(cr)
+					g += uint64(cg)
+					bl += uint64(cb)
+					a += uint64(ca)
+					n++
+				}
+			}
+
+			dst.SetRGBA64(x, y, color.RGBA64{
+				R: uint16(r / n),
+				G: uint16(g / n),
+				B: uint16(bl / n),
+				A: uint16(a / n),
+			})
+		}
+	}
+	return dst
+}
@@ -0,0 +1,73 @@
+package tracing
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const spanKey = "finance:tracing:span"
+
+// GormPlugin создает спан на каждый запрос GORM, дочерний к спану из контекста
+// запроса (db.WithContext). Текст запроса пишется без значений параметров
+type GormPlugin struct{}
+
+func (GormPlugin) Name() string {
+	return "finance:tracing"
+}
+
+func (GormPlugin) Initialize(db *gorm.DB) error {
+	tracer := otel.Tracer("finance-backend/internal/tracing")
+	system := db.Dialector.Name()
+
+	before := func(operation string) func(*gorm.DB) {
+		return func(tx *gorm.DB) {
+			ctx, span := tracer.Start(tx.Statement.Context, "db."+operation,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(
+					attribute.String("db.system.name", system),
+					attribute.String("db.operation.name", operation),
+				))
+			tx.Statement.Context = ctx
+			tx.InstanceSet(spanKey, span)
+		}
+	}
+	after := func(tx *gorm.DB) {
+		v, ok := tx.InstanceGet(spanKey)
+		if !ok {
+			return
+		}
+		span := v.(trace.Span)
+		defer span.End()
+
+		span.SetAttributes(
+			attribute.String("db.collection.name", tx.Statement.Table),
+			attribute.String("db.query.text", tx.Statement.SQL.String()),
+			attribute.Int64("db.response.returned_rows", tx.RowsAffected),
+		)
+		if tx.Error != nil && !errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+			span.RecordError(tx.Error)
+			span.SetStatus(codes.Error, tx.Error.Error())
+		}
+	}
+
+	cb := db.Callback()
+	return errors.Join(
+		cb.Create().Before("*").Register("finance:tracing:before", before("create")),
+		cb.Create().After("*").Register("finance:tracing:after", after),
+		cb.Query().Before("*").Register("finance:tracing:before", before("query")),
+		cb.Query().After("*").Register("finance:tracing:after", after),
+		cb.Update().Before("*").Register("finance:tracing:before", before("update")),
+		cb.Update().After("*").Register("finance:tracing:after", after),
+		cb.Delete().Before("*").Register("finance:tracing:before", before("delete")),
+		cb.Delete().After("*").Register("finance:tracing:after", after),
+		cb.Row().Before("*").Register("finance:tracing:before", before("row")),
+		cb.Row().After("*").Register("finance:tracing:after", after),
+		cb.Raw().Before("*").Register("finance:tracing:before", before("raw")),
+		cb.Raw().After("*").Register("finance:tracing:after", after),
+	)
+}
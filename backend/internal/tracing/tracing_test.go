@@ -0,0 +1,161 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"finance-backend/internal/config"
+)
+
+func attrValue(span sdktrace.ReadOnlySpan, key attribute.Key) attribute.Value {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+// findSpan возвращает единственный завершенный спан с именем name
+func findSpan(t *testing.T, rec *tracetest.SpanRecorder, name string) sdktrace.ReadOnlySpan {
+	t.Helper()
+	var found []sdktrace.ReadOnlySpan
+	for _, span := range rec.Ended() {
+		if span.Name() == name {
+			found = append(found, span)
+		}
+	}
+	if len(found) != 1 {
+		t.Fatalf("got %d %q spans, want 1", len(found), name)
+	}
+	return found[0]
+}
+
+func TestGormPlugin(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+	// Плагин берет трассировщик из глобального провайдера при подключении
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	db, err := gorm.Open(sqlite.Open(t.TempDir()+"/tracing.db"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Use(GormPlugin{}); err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sqlDB.Close()
+
+	type widget struct {
+		ID   uint
+		Name string
+	}
+	if err := db.Exec("CREATE TABLE widgets (id integer PRIMARY KEY, name text)").Error; err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("child of request span", func(t *testing.T) {
+		rec.Reset()
+		ctx, parent := provider.Tracer("test").Start(context.Background(), "request")
+		if err := db.WithContext(ctx).Create(&widget{Name: "secret-name"}).Error; err != nil {
+			t.Fatal(err)
+		}
+		parent.End()
+
+		span := findSpan(t, rec, "db.create")
+		if span.Parent().SpanID() != parent.SpanContext().SpanID() {
+			t.Errorf("db.create parent = %v, want the request span", span.Parent().SpanID())
+		}
+		if span.SpanKind() != trace.SpanKindClient {
+			t.Errorf("kind = %v, want client", span.SpanKind())
+		}
+		want := map[attribute.Key]string{
+			"db.system.name":     "sqlite",
+			"db.operation.name":  "create",
+			"db.collection.name": "widgets",
+		}
+		for key, value := range want {
+			if got := attrValue(span, key).AsString(); got != value {
+				t.Errorf("%s = %q, want %q", key, got, value)
+			}
+		}
+		query := attrValue(span, "db.query.text").AsString()
+		if !strings.Contains(query, "INSERT INTO") || strings.Contains(query, "secret-name") {
+			t.Errorf("db.query.text = %q, want the statement without values", query)
+		}
+		if got := attrValue(span, "db.response.returned_rows").AsInt64(); got != 1 {
+			t.Errorf("db.response.returned_rows = %d, want 1", got)
+		}
+	})
+
+	t.Run("not found is not an error", func(t *testing.T) {
+		rec.Reset()
+		var w widget
+		if err := db.Where("name = ?", "nobody").First(&w).Error; !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("First = %v, want ErrRecordNotFound", err)
+		}
+		span := findSpan(t, rec, "db.query")
+		if span.Status().Code != codes.Unset || len(span.Events()) != 0 {
+			t.Errorf("status = %v, events = %v, want no error", span.Status(), span.Events())
+		}
+	})
+
+	t.Run("failed query is an error", func(t *testing.T) {
+		rec.Reset()
+		if err := db.Table("missing_table").Find(&[]widget{}).Error; err == nil {
+			t.Fatal("query on a missing table succeeded")
+		}
+		span := findSpan(t, rec, "db.query")
+		if span.Status().Code != codes.Error || !strings.Contains(span.Status().Description, "missing_table") {
+			t.Errorf("status = %v, want the database error", span.Status())
+		}
+		if len(span.Events()) != 1 || span.Events()[0].Name != "exception" {
+			t.Errorf("events = %v, want a recorded exception", span.Events())
+		}
+	})
+}
+
+func TestSetupWithoutEndpoint(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	shutdown, err := Setup(context.Background(), config.TracingConfig{ServiceName: "finance-test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Errorf("shutdown: %v", err)
+	}
+	if otel.GetTracerProvider() != previous {
+		t.Error("Setup without an endpoint replaced the tracer provider")
+	}
+
+	// traceparent распространяется и без коллектора
+	carrier := propagation.MapCarrier{}
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{2},
+		TraceFlags: trace.FlagsSampled,
+	})
+	otel.GetTextMapPropagator().Inject(trace.ContextWithSpanContext(context.Background(), sc), carrier)
+	if carrier.Get("traceparent") == "" {
+		t.Errorf("no traceparent injected: %v", carrier)
+	}
+}